@@ -0,0 +1,233 @@
+package app
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+)
+
+// Sort fields of the paginated client list
+const (
+	sortByID       = "id"
+	sortByName     = "name"
+	sortByCreated  = "created"
+	sortByActivity = "activity"
+)
+
+// clientKey holds the values a client list can be sorted by. Cursors carry
+// the key of the last item of a page, so the next page starts right after it
+// even if clients were added or removed in between.
+type clientKey struct {
+	ID           int       `json:"i"`
+	Name         string    `json:"n,omitempty"`
+	CreatedAt    time.Time `json:"c,omitzero"`
+	LastActivity time.Time `json:"a,omitzero"`
+}
+
+type clientCursor struct {
+	Sort string    `json:"s"`
+	Desc bool      `json:"d,omitempty"`
+	Key  clientKey `json:"k"`
+}
+
+// clientQuery is the sorting and filtering of GET /api/client/get/all/paginated
+type clientQuery struct {
+	Search string
+	Tags   []string
+	Sort   string
+	Desc   bool
+	// Running filters by running state if set
+	Running *bool
+	// ActiveWithin keeps clients with a datagram in this window, 0 disables the filter
+	ActiveWithin time.Duration
+	MinDatagrams int
+	// MaxDatagrams is the maximum datagram count, -1 disables the filter
+	MaxDatagrams int
+	Cursor       *clientKey
+}
+
+func keyOf(uc api.UDPClient) clientKey {
+	return clientKey{ID: uc.ID, Name: uc.Name, CreatedAt: uc.CreatedAt, LastActivity: uc.LastActivity}
+}
+
+// parseClientQuery reads the sort, filter and cursor query parameters
+func parseClientQuery(query url.Values) (clientQuery, error) {
+	q := clientQuery{
+		Search:       query.Get("q"),
+		Tags:         query["tag"],
+		Sort:         sortByID,
+		MaxDatagrams: -1,
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		if !slices.Contains([]string{sortByID, sortByName, sortByCreated, sortByActivity}, sort) {
+			return q, errors.New("sort must be 'id', 'name', 'created' or 'activity'")
+		}
+		q.Sort = sort
+	}
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		q.Desc = true
+	default:
+		return q, errors.New("order must be 'asc' or 'desc'")
+	}
+
+	if running := query.Get("running"); running != "" {
+		b, err := strconv.ParseBool(running)
+		if err != nil {
+			return q, errors.New("running must be 'true' or 'false'")
+		}
+		q.Running = &b
+	}
+	if activeWithin := query.Get("activeWithin"); activeWithin != "" {
+		seconds, err := strconv.Atoi(activeWithin)
+		if err != nil || seconds < 1 {
+			return q, errors.New("activeWithin must be a positive number of seconds")
+		}
+		q.ActiveWithin = time.Duration(seconds) * time.Second
+	}
+	if minDatagrams := query.Get("minDatagrams"); minDatagrams != "" {
+		n, err := strconv.Atoi(minDatagrams)
+		if err != nil || n < 0 {
+			return q, errors.New("minDatagrams must be a non-negative integer")
+		}
+		q.MinDatagrams = n
+	}
+	if maxDatagrams := query.Get("maxDatagrams"); maxDatagrams != "" {
+		n, err := strconv.Atoi(maxDatagrams)
+		if err != nil || n < 0 {
+			return q, errors.New("maxDatagrams must be a non-negative integer")
+		}
+		q.MaxDatagrams = n
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return q, errors.New("cursor is invalid")
+		}
+		if c.Sort != q.Sort || c.Desc != q.Desc {
+			return q, errors.New("cursor belongs to a different sort order")
+		}
+		q.Cursor = &c.Key
+	}
+	return q, nil
+}
+
+// searchMatch reports whether the lower-case search text is in the name,
+// group or one of the tags of the client, ignoring case
+func searchMatch(uc api.UDPClient, search string) bool {
+	if strings.Contains(strings.ToLower(uc.Name), search) || strings.Contains(strings.ToLower(uc.Group), search) {
+		return true
+	}
+	return slices.ContainsFunc(uc.Tags, func(tag string) bool {
+		return strings.Contains(strings.ToLower(tag), search)
+	})
+}
+
+// match reports whether the client passes all filters of the query
+func (q clientQuery) match(uc api.UDPClient, now time.Time) bool {
+	if q.Search != "" && !searchMatch(uc, strings.ToLower(q.Search)) {
+		return false
+	}
+	if !hasTags(uc, q.Tags) {
+		return false
+	}
+	if q.Running != nil && uc.Running != *q.Running {
+		return false
+	}
+	if q.ActiveWithin > 0 && (uc.LastActivity.IsZero() || now.Sub(uc.LastActivity) > q.ActiveWithin) {
+		return false
+	}
+	if len(uc.Datagrams) < q.MinDatagrams {
+		return false
+	}
+	if q.MaxDatagrams >= 0 && len(uc.Datagrams) > q.MaxDatagrams {
+		return false
+	}
+	return true
+}
+
+// compare orders two clients by the sort field, ties are broken by ID so the order is total
+func (q clientQuery) compare(a, b clientKey) int {
+	c := 0
+	switch q.Sort {
+	case sortByName:
+		c = strings.Compare(a.Name, b.Name)
+	case sortByCreated:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	case sortByActivity:
+		c = a.LastActivity.Compare(b.LastActivity)
+	}
+	if c == 0 {
+		c = cmp.Compare(a.ID, b.ID)
+	}
+	if q.Desc {
+		c = -c
+	}
+	return c
+}
+
+// sort orders the clients by the query and returns the index of the first client after the cursor
+func (q clientQuery) sort(clients []api.UDPClient) int {
+	slices.SortFunc(clients, func(a, b api.UDPClient) int {
+		return q.compare(keyOf(a), keyOf(b))
+	})
+	if q.Cursor == nil {
+		return 0
+	}
+	start, _ := slices.BinarySearchFunc(clients, *q.Cursor, func(uc api.UDPClient, key clientKey) int {
+		// Sort the cursor key itself before the first client after it
+		if q.compare(keyOf(uc), key) <= 0 {
+			return -1
+		}
+		return 1
+	})
+	return start
+}
+
+func (q clientQuery) cursorAfter(uc api.UDPClient) string {
+	b, err := json.Marshal(clientCursor{Sort: q.Sort, Desc: q.Desc, Key: keyOf(uc)})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (clientCursor, error) {
+	var c clientCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+func listItems(clients []api.UDPClient) []api.UDPClientListItem {
+	items := make([]api.UDPClientListItem, 0, len(clients))
+	for _, uc := range clients {
+		items = append(items, newUDPClientListItem(uc))
+	}
+	return items
+}
+
+func invalidClientQuery(w http.ResponseWriter, err error) {
+	apiError := api.ApiError{
+		Code:      http.StatusBadRequest,
+		ErrorCode: api.ErrInvalidParameter,
+		Message:   "Invalid client query",
+		Details:   err.Error(),
+	}
+	apiError.Send(w)
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClientQuery_Invalid(t *testing.T) {
+	tests := []string{
+		"sort=size",
+		"order=up",
+		"running=maybe",
+		"activeWithin=0",
+		"minDatagrams=-1",
+		"maxDatagrams=x",
+		"cursor=not-a-cursor!",
+	}
+
+	for _, raw := range tests {
+		t.Run(raw, func(t *testing.T) {
+			query, err := url.ParseQuery(raw)
+			require.NoError(t, err)
+			_, err = parseClientQuery(query)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseClientQuery_CursorOfOtherSort(t *testing.T) {
+	q, err := parseClientQuery(url.Values{"sort": {"name"}})
+	require.NoError(t, err)
+	cursor := q.cursorAfter(api.UDPClient{ID: 1, Name: "a"})
+
+	_, err = parseClientQuery(url.Values{"sort": {"name"}, "order": {"desc"}, "cursor": {cursor}})
+	assert.Error(t, err)
+	_, err = parseClientQuery(url.Values{"sort": {"name"}, "cursor": {cursor}})
+	assert.NoError(t, err)
+}
+
+func TestClientQuery_Match(t *testing.T) {
+	now := time.Now()
+	running := true
+	uc := api.UDPClient{
+		Name:         "Alice",
+		Group:        "Red",
+		Tags:         []string{"load", "EU"},
+		Running:      true,
+		Datagrams:    make([]api.Datagram, 3),
+		LastActivity: now.Add(-5 * time.Second),
+	}
+
+	assert.True(t, clientQuery{Search: "ali", Running: &running, MaxDatagrams: -1}.match(uc, now))
+	assert.True(t, clientQuery{Search: "red", MaxDatagrams: -1}.match(uc, now))
+	assert.True(t, clientQuery{Search: "eu", MaxDatagrams: -1}.match(uc, now))
+	assert.False(t, clientQuery{Search: "bob", MaxDatagrams: -1}.match(uc, now))
+	assert.True(t, clientQuery{ActiveWithin: 10 * time.Second, MinDatagrams: 3, MaxDatagrams: 3}.match(uc, now))
+	assert.False(t, clientQuery{ActiveWithin: time.Second, MaxDatagrams: -1}.match(uc, now))
+	assert.False(t, clientQuery{MinDatagrams: 4, MaxDatagrams: -1}.match(uc, now))
+	assert.False(t, clientQuery{MaxDatagrams: 2}.match(uc, now))
+	assert.False(t, clientQuery{ActiveWithin: time.Hour, MaxDatagrams: -1}.match(api.UDPClient{}, now))
+}
+
+func TestClientQuery_SortIsStable(t *testing.T) {
+	base := time.Now()
+	clients := []api.UDPClient{
+		{ID: 3, Name: "bob", CreatedAt: base},
+		{ID: 1, Name: "bob", CreatedAt: base.Add(time.Second)},
+		{ID: 2, Name: "alice", CreatedAt: base},
+	}
+
+	q := clientQuery{Sort: sortByName}
+	q.sort(clients)
+	assert.Equal(t, []int{2, 1, 3}, clientIDs(clients))
+
+	q = clientQuery{Sort: sortByCreated, Desc: true}
+	q.sort(clients)
+	assert.Equal(t, []int{1, 3, 2}, clientIDs(clients))
+}
+
+func TestServer_GetAllUDPClientPaginated_CursorSurvivesInserts(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	for id := 1; id <= 5; id++ {
+		name := fmt.Sprintf("client%d", id)
+		addClient(t, server, api.UDPClient{ID: id, Name: name})
+	}
+
+	first := getClientPage(t, server, "pageSize=2&order=desc")
+	assert.Equal(t, []int{5, 4}, itemIDs(first.Items))
+	require.NotEmpty(t, first.NextCursor)
+
+	// A client added before the cursor doesn't shift the next page
+	addClient(t, server, api.UDPClient{ID: 6, Name: "client6"})
+
+	second := getClientPage(t, server, "pageSize=2&order=desc&cursor="+first.NextCursor)
+	assert.Equal(t, []int{3, 2}, itemIDs(second.Items))
+	assert.Equal(t, 0, second.Page)
+	assert.Equal(t, 6, second.Total)
+
+	last := getClientPage(t, server, "pageSize=2&order=desc&cursor="+second.NextCursor)
+	assert.Equal(t, []int{1}, itemIDs(last.Items))
+	assert.Empty(t, last.NextCursor)
+}
+
+func TestServer_GetAllUDPClientPaginated_InvalidQuery(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	req := httptest.NewRequest("GET", "/api/client/get/all/paginated?sort=size", nil)
+	rr := httptest.NewRecorder()
+
+	server.GetAllUDPClientPaginated(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func getClientPage(t *testing.T, server *Server, query string) api.UDPClientPaginatedRespone {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/client/get/all/paginated?"+query, nil)
+	rr := httptest.NewRecorder()
+
+	server.GetAllUDPClientPaginated(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var response api.UDPClientPaginatedRespone
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	return response
+}
+
+func clientIDs(clients []api.UDPClient) []int {
+	ids := []int{}
+	for _, uc := range clients {
+		ids = append(ids, uc.ID)
+	}
+	return ids
+}
+
+func itemIDs(items []api.UDPClientListItem) []int {
+	ids := []int{}
+	for _, item := range items {
+		ids = append(ids, item.Id)
+	}
+	return ids
+}
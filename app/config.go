@@ -0,0 +1,51 @@
+package app
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read by ConfigFromEnv, lists are comma separated
+const (
+	EnvAdminTokens    = "DEBUG_UI_ADMIN_TOKENS"
+	EnvViewerTokens   = "DEBUG_UI_VIEWER_TOKENS"
+	EnvAllowedOrigins = "DEBUG_UI_CORS_ORIGINS"
+	EnvTLSCert        = "DEBUG_UI_TLS_CERT"
+	EnvTLSKey         = "DEBUG_UI_TLS_KEY"
+	EnvTLSSelfSigned  = "DEBUG_UI_TLS_SELF_SIGNED"
+	EnvTLSDir         = "DEBUG_UI_TLS_DIR"
+	EnvTLSHosts       = "DEBUG_UI_TLS_HOSTS"
+	EnvFuzzDir        = "DEBUG_UI_FUZZ_DIR"
+)
+
+// ConfigFromEnv returns the default config with the settings from the environment
+func ConfigFromEnv(udpPort int) Config {
+	return Config{
+		UDPPort:        udpPort,
+		AdminTokens:    envList(EnvAdminTokens),
+		ViewerTokens:   envList(EnvViewerTokens),
+		AllowedOrigins: envList(EnvAllowedOrigins),
+		TLSCertFile:    os.Getenv(EnvTLSCert),
+		TLSKeyFile:     os.Getenv(EnvTLSKey),
+		TLSSelfSigned:  envBool(EnvTLSSelfSigned),
+		TLSDir:         os.Getenv(EnvTLSDir),
+		TLSHosts:       envList(EnvTLSHosts),
+		FuzzDir:        os.Getenv(EnvFuzzDir),
+	}
+}
+
+func envBool(name string) bool {
+	value, err := strconv.ParseBool(os.Getenv(name))
+	return err == nil && value
+}
+
+func envList(name string) []string {
+	var values []string
+	for value := range strings.SplitSeq(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/auraspeak/debug-ui/internal/certs"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvAdminTokens, "a1, a2,")
+	t.Setenv(EnvViewerTokens, "")
+	t.Setenv(EnvAllowedOrigins, "http://lab:5173")
+	t.Setenv(EnvTLSSelfSigned, "true")
+	t.Setenv(EnvTLSHosts, "lab.local")
+
+	config := ConfigFromEnv(9090)
+
+	assert.Equal(t, 9090, config.UDPPort)
+	assert.Equal(t, []string{"a1", "a2"}, config.AdminTokens)
+	assert.Empty(t, config.ViewerTokens)
+	assert.Equal(t, []string{"http://lab:5173"}, config.AllowedOrigins)
+	assert.True(t, config.TLSSelfSigned)
+	assert.Equal(t, []string{"lab.local"}, config.TLSHosts)
+}
+
+func TestServer_TLSConfig(t *testing.T) {
+	server := NewServerWithConfig(8080, Config{}, debugui.Config{})
+	tlsConfig, err := server.tlsConfig()
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig, "plain HTTP without TLS settings")
+
+	dir := t.TempDir()
+	server = NewServerWithConfig(8080, Config{TLSSelfSigned: true, TLSDir: dir}, debugui.Config{})
+	tlsConfig, err = server.tlsConfig()
+	require.NoError(t, err)
+	require.NotNil(t, tlsConfig)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.FileExists(t, filepath.Join(dir, certs.CertFile))
+
+	server = NewServerWithConfig(8080, Config{TLSCertFile: filepath.Join(dir, "missing.pem"), TLSKeyFile: filepath.Join(dir, certs.KeyFile)}, debugui.Config{})
+	_, err = server.tlsConfig()
+	assert.Error(t, err)
+}
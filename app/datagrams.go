@@ -0,0 +1,124 @@
+package app
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+)
+
+const (
+	defaultDatagramLimit = 100
+	maxDatagramLimit     = 1000
+)
+
+func newUDPClientStateResponse(uc api.UDPClient, withDatagrams bool) api.UDPClientStateResponse {
+	response := api.UDPClientStateResponse{
+		Id:            uc.ID,
+		Running:       uc.Running,
+		Datagrams:     []api.Datagram{},
+		DatagramCount: len(uc.Datagrams),
+		LastSeq:       uc.LastSeq,
+	}
+	if withDatagrams {
+		response.Datagrams = uc.Datagrams
+	}
+	return response
+}
+
+// pageDatagrams returns up to limit datagrams with a sequence number above
+// since, oldest first. Reverse pages return the datagrams below since, newest
+// first, starting at the newest one if since is 0. next is the since value of
+// the following page.
+func pageDatagrams(datagrams []api.Datagram, since, limit int, reverse bool) (page []api.Datagram, next int, hasMore bool) {
+	// Datagrams are ordered by Seq, but clearing the history leaves gaps
+	index := func(seq int) int {
+		return sort.Search(len(datagrams), func(i int) bool { return datagrams[i].Seq >= seq })
+	}
+
+	if !reverse {
+		start := index(since + 1)
+		end := min(start+limit, len(datagrams))
+		page = slices.Clone(datagrams[start:end])
+		next = since
+		if len(page) > 0 {
+			next = page[len(page)-1].Seq
+		}
+		return page, next, end < len(datagrams)
+	}
+
+	end := len(datagrams)
+	if since > 0 {
+		end = index(since)
+	}
+	start := max(end-limit, 0)
+	page = slices.Clone(datagrams[start:end])
+	slices.Reverse(page)
+	next = since
+	if len(page) > 0 {
+		next = page[len(page)-1].Seq
+	}
+	return page, next, start > 0
+}
+
+func (s *Server) GetClientDatagrams(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	id, err := strconv.Atoi(query.Get("id"))
+	if err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidParameter,
+			Message:   "ID is invalid",
+		}
+		apiError.Send(w)
+		return
+	}
+	since := 0
+	if sinceStr := query.Get("since"); sinceStr != "" {
+		since, err = strconv.Atoi(sinceStr)
+		if err != nil || since < 0 {
+			apiError := api.ApiError{
+				Code:      http.StatusBadRequest,
+				ErrorCode: api.ErrInvalidParameter,
+				Message:   "Since must be a non-negative integer",
+			}
+			apiError.Send(w)
+			return
+		}
+	}
+	limit := defaultDatagramLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxDatagramLimit {
+			apiError := api.ApiError{
+				Code:      http.StatusBadRequest,
+				ErrorCode: api.ErrInvalidParameter,
+				Message:   "Limit must be between 1 and " + strconv.Itoa(maxDatagramLimit),
+			}
+			apiError.Send(w)
+			return
+		}
+	}
+	reverse := query.Get("reverse") == "true"
+
+	if uc, ok := s.clients.Get(id); ok {
+		page, next, hasMore := pageDatagrams(uc.Datagrams, since, limit, reverse)
+		response := api.DatagramPageResponse{
+			ClientID:  uc.ID,
+			Datagrams: page,
+			LastSeq:   uc.LastSeq,
+			Next:      next,
+			HasMore:   hasMore,
+		}
+		response.Send(w)
+		return
+	}
+	apiError := api.ApiError{
+		Code:      http.StatusNotFound,
+		ErrorCode: api.ErrClientNotFound,
+		Message:   "UDP client not found",
+	}
+	apiError.Send(w)
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// datagramsWithSeqs builds a history with the given sequence numbers
+func datagramsWithSeqs(seqs ...int) []api.Datagram {
+	datagrams := []api.Datagram{}
+	for _, seq := range seqs {
+		datagrams = append(datagrams, api.Datagram{Seq: seq, Direction: api.ClientToServer})
+	}
+	return datagrams
+}
+
+func seqsOf(datagrams []api.Datagram) []int {
+	seqs := []int{}
+	for _, d := range datagrams {
+		seqs = append(seqs, d.Seq)
+	}
+	return seqs
+}
+
+func TestRecordDatagram(t *testing.T) {
+	uc := api.UDPClient{LastSeq: 7}
+
+	d := recordDatagram(&uc, api.Datagram{Direction: api.ServerToClient})
+
+	assert.Equal(t, 8, d.Seq)
+	assert.Equal(t, 8, uc.LastSeq)
+	assert.Equal(t, []int{8}, seqsOf(uc.Datagrams))
+	assert.False(t, uc.LastActivity.IsZero())
+}
+
+func TestPageDatagrams(t *testing.T) {
+	// History with a gap, e.g. after the group history was cleared
+	datagrams := datagramsWithSeqs(3, 4, 5, 6, 7)
+
+	tests := []struct {
+		name    string
+		since   int
+		limit   int
+		reverse bool
+		seqs    []int
+		next    int
+		hasMore bool
+	}{
+		{"from start", 0, 2, false, []int{3, 4}, 4, true},
+		{"since", 4, 10, false, []int{5, 6, 7}, 7, false},
+		{"up to date", 7, 10, false, []int{}, 7, false},
+		{"reverse newest", 0, 2, true, []int{7, 6}, 6, true},
+		{"reverse before", 6, 10, true, []int{5, 4, 3}, 3, false},
+		{"reverse at start", 3, 10, true, []int{}, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, next, hasMore := pageDatagrams(datagrams, tt.since, tt.limit, tt.reverse)
+			assert.Equal(t, tt.seqs, seqsOf(page))
+			assert.Equal(t, tt.next, next)
+			assert.Equal(t, tt.hasMore, hasMore)
+		})
+	}
+}
+
+func TestServer_GetClientDatagrams(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	addClient(t, server, api.UDPClient{ID: 1, Name: "alice", Datagrams: datagramsWithSeqs(1, 2, 3), LastSeq: 3})
+
+	req := httptest.NewRequest("GET", "/api/client/datagrams?id=1&since=1", nil)
+	rr := httptest.NewRecorder()
+
+	server.GetClientDatagrams(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response api.DatagramPageResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, []int{2, 3}, seqsOf(response.Datagrams))
+	assert.Equal(t, 3, response.LastSeq)
+	assert.Equal(t, 3, response.Next)
+	assert.False(t, response.HasMore)
+}
+
+func TestServer_GetClientDatagrams_InvalidRequest(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	tests := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"no id", "/api/client/datagrams", http.StatusBadRequest},
+		{"negative since", "/api/client/datagrams?id=1&since=-1", http.StatusBadRequest},
+		{"limit too large", "/api/client/datagrams?id=1&limit=1001", http.StatusBadRequest},
+		{"not found", "/api/client/datagrams?id=42", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			server.GetClientDatagrams(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+		})
+	}
+}
+
+func TestServer_GetUDPClientStateById_WithoutDatagrams(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	addClient(t, server, api.UDPClient{ID: 1, Name: "alice", Datagrams: datagramsWithSeqs(1, 2), LastSeq: 2})
+
+	req := httptest.NewRequest("GET", "/api/client/get/id?id=1&datagrams=false", nil)
+	rr := httptest.NewRecorder()
+
+	server.GetUDPClientStateById(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response api.UDPClientStateResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Empty(t, response.Datagrams)
+	assert.Equal(t, 2, response.DatagramCount)
+	assert.Equal(t, 2, response.LastSeq)
+}
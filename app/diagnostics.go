@@ -0,0 +1,142 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/diag"
+)
+
+// stuckLockAfter is how long s.mu may be held before /healthz fails. No
+// handler holds it for more than a few milliseconds, so a longer hold is a
+// deadlock.
+const stuckLockAfter = 10 * time.Second
+
+// Healthz is the liveness probe. It fails when the server lock is stuck.
+func (s *Server) Healthz(w http.ResponseWriter, r *http.Request) {
+	lock := api.HealthCheck{Name: "serverLock", OK: true}
+	if stats := s.mu.Stats(); stats.Held && stats.HeldFor > stuckLockAfter {
+		lock.OK = false
+		lock.Message = fmt.Sprintf("held for %s", stats.HeldFor.Round(time.Millisecond))
+	}
+	response := api.NewHealthResponse([]api.HealthCheck{lock})
+	response.Send(w)
+}
+
+// Readyz is the readiness probe. It fails until Serve started and while the
+// server shuts down.
+func (s *Server) Readyz(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	serving := s.httpServer != nil
+	s.mu.Unlock()
+
+	web := api.HealthCheck{Name: "http", OK: serving}
+	if !serving {
+		web.Message = "not serving yet"
+	}
+	shutdown := api.HealthCheck{Name: "shutdown", OK: !s.closing.Load()}
+	if !shutdown.OK {
+		shutdown.Message = "shutting down"
+	}
+	response := api.NewHealthResponse([]api.HealthCheck{web, shutdown})
+	response.Send(w)
+}
+
+// GetDiagnostics reports goroutines, channel backlogs, lock times,
+// WebSocket connections, event bus messages and memory
+func (s *Server) GetDiagnostics(w http.ResponseWriter, r *http.Request) {
+	// Before taking the lock, so this request doesn't show up as holder
+	lock := lockDiagnostics("server", s.mu.Stats())
+
+	s.mu.Lock()
+	startedAt := s.startedAt
+	udpServer := s.udpServer
+	s.mu.Unlock()
+
+	clients := s.clients.Snapshot()
+	var channels []api.ChannelDiagnostics
+	if udpServer != nil {
+		channels = append(channels,
+			api.ChannelDiagnostics{Name: "server.outCommand", Len: len(udpServer.OutCommandCh), Cap: cap(udpServer.OutCommandCh)},
+			api.ChannelDiagnostics{Name: "server.trace", Len: len(udpServer.TraceCh), Cap: cap(udpServer.TraceCh)},
+		)
+	}
+	for _, uc := range clients {
+		// Stopped clients have nobody listening anymore
+		scope := s.clients.Scope(uc.ID)
+		if uc.Client == nil || scope == nil || scope.Context().Err() != nil {
+			continue
+		}
+		id, ch := uc.ID, uc.Client.OutCommandCh
+		channels = append(channels, api.ChannelDiagnostics{Name: "client.outCommand", ClientID: &id, Len: len(ch), Cap: cap(ch)})
+	}
+
+	published := map[string]uint64{}
+	for topic, n := range s.events.Counts() {
+		published[string(topic)] = n
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	response := api.DiagnosticsResponse{
+		StartedAt: startedAt,
+		Goroutines: api.GoroutineDiagnostics{
+			Total:      runtime.NumGoroutine(),
+			Components: s.goroutines.Running(),
+		},
+		Channels: channels,
+		Locks:    []api.LockDiagnostics{lock},
+		WebSocket: api.WebSocketDiagnostics{
+			Connections: s.wsHub.ConnectionCount(),
+			Subscribers: s.wsHub.SubscriberCount(),
+		},
+		Events: api.EventDiagnostics{
+			Subscribers: s.bus.SubscriberCount(),
+			Published:   published,
+		},
+		Memory: api.MemoryDiagnostics{
+			HeapAlloc:    mem.HeapAlloc,
+			HeapInuse:    mem.HeapInuse,
+			HeapObjects:  mem.HeapObjects,
+			Sys:          mem.Sys,
+			TotalAlloc:   mem.TotalAlloc,
+			NumGC:        mem.NumGC,
+			PauseTotalMs: milliseconds(time.Duration(mem.PauseTotalNs)),
+		},
+		Clients:   len(clients),
+		UDPServer: udpServer != nil,
+	}
+	if !startedAt.IsZero() {
+		response.UptimeMs = time.Since(startedAt).Milliseconds()
+	}
+	if response.Goroutines.Components == nil {
+		response.Goroutines.Components = map[string]int{}
+	}
+	if response.Channels == nil {
+		response.Channels = []api.ChannelDiagnostics{}
+	}
+	response.Send(w)
+}
+
+func lockDiagnostics(name string, stats diag.LockStats) api.LockDiagnostics {
+	return api.LockDiagnostics{
+		Name:         name,
+		Acquisitions: stats.Acquisitions,
+		Contended:    stats.Contended,
+		TotalWaitMs:  milliseconds(stats.TotalWait),
+		MaxWaitMs:    milliseconds(stats.MaxWait),
+		TotalHoldMs:  milliseconds(stats.TotalHold),
+		MaxHoldMs:    milliseconds(stats.MaxHold),
+		Held:         stats.Held,
+		HeldForMs:    milliseconds(stats.HeldFor),
+	}
+}
+
+// milliseconds returns d in fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
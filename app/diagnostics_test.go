@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Healthz(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	rr := httptest.NewRecorder()
+
+	server.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response api.HealthResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, api.HealthOK, response.Status)
+}
+
+func TestServer_Readyz(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	// Not serving yet
+	rr := httptest.NewRecorder()
+	server.Readyz(rr, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	server.httpServer = &http.Server{}
+	rr = httptest.NewRecorder()
+	server.Readyz(rr, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Shutting down
+	_, err := server.Shutdown(time.Second)
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	server.Readyz(rr, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	var response api.HealthResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Contains(t, response.Checks, api.HealthCheck{Name: "shutdown", Message: "shutting down"})
+}
+
+func TestServer_GetDiagnostics(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	defer server.Shutdown(time.Second)
+	udpClient, err := server.startUDPClient(context.Background(), "alice", "", nil)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return server.goroutines.Running()["handleClientCommands"] == 1
+	}, time.Second, 10*time.Millisecond)
+	// Clients don't take the server lock, the probe does
+	server.Readyz(httptest.NewRecorder(), httptest.NewRequest("GET", "/readyz", nil))
+
+	rr := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/diagnostics", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response api.DiagnosticsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Clients)
+	assert.False(t, response.UDPServer)
+	assert.Positive(t, response.Goroutines.Total)
+	assert.Equal(t, 1, response.Goroutines.Components["handleClientCommands"])
+	require.Len(t, response.Channels, 1)
+	assert.Equal(t, "client.outCommand", response.Channels[0].Name)
+	assert.Equal(t, &udpClient.ID, response.Channels[0].ClientID)
+	require.Len(t, response.Locks, 1)
+	assert.Equal(t, "server", response.Locks[0].Name)
+	assert.Positive(t, response.Locks[0].Acquisitions)
+	assert.False(t, response.Locks[0].Held)
+	assert.Positive(t, response.Memory.HeapAlloc)
+	assert.Equal(t, 3, response.Events.Subscribers)
+	assert.Equal(t, uint64(1), response.Events.Published["clientStarted"])
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"strconv"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/communication"
+	"github.com/auraspeak/debug-ui/internal/fuzz"
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	"github.com/auraspeak/debug-ui/internal/ws"
+)
+
+// subscribe connects the consumers to the bus: the log store, the metrics
+// shown by /diagnostics and the WebSocket hub. The store comes first, so
+// an entry is stored before it is sent.
+func (s *Server) subscribe() {
+	s.bus.SetInvalidHandler(s.logInvalidMessage)
+	communication.Logs.Subscribe(s.bus, s.storeLog)
+	s.bus.Subscribe(s.events.Handle)
+	s.bus.Subscribe(s.forwardToWebSocket)
+}
+
+// logInvalidMessage logs a message the bus dropped because of its data,
+// which some publisher didn't send through the topic's Event
+func (s *Server) logInvalidMessage(msg communication.InternalMessage) {
+	s.logger.WithField("caller", "web").Errorf("Dropped %s message with data of type %T", msg.Topic, msg.Data)
+}
+
+// storeLog adds the published log entries to the log store
+func (s *Server) storeLog(_ communication.InternalMessage, record logstore.Record) {
+	s.logs.Append(record.Entry)
+}
+
+// publishLog is the publish func of the log hook
+func (s *Server) publishLog(record logstore.Record) {
+	communication.Logs.Publish(s.bus, communication.InternalMessage{
+		Caller: communication.CallerLogger,
+	}, record)
+}
+
+// packetEvent describes a datagram, fromID and toID are 0 for the server
+func packetEvent(fromID, toID int, d api.Datagram) api.PacketEvent {
+	return api.PacketEvent{
+		FromClientID: fromID,
+		ToClientID:   toID,
+		Seq:          d.Seq,
+		Direction:    d.Direction,
+		PacketType:   d.PacketType,
+		Raw:          d.Raw,
+		Message:      d.Message,
+		Fields:       d.Fields,
+	}
+}
+
+// forwardToWebSocket sends the messages of the bus to the WebSocket
+// connections and SSE streams, as the short string commands and structured
+// frames the UI knows:
+//
+// rp: the web server (re)started
+// uss: the UDP server state changed
+// cnu: a new client was started
+// usu<id>: the state of a client changed
+// dgm,clientId,seq: a client sent or received a datagram
+// pkt,fromId,toId,dir: a datagram, followed by the PKT frame
+// map: the client map changed
+// lvu: the log levels changed
+// fzu: the fuzz run progressed
+//
+// Log entries come through here, so it must not log them.
+func (s *Server) forwardToWebSocket(msg communication.InternalMessage) {
+	hub := s.wsHub
+	if hub == nil {
+		return
+	}
+	switch msg.Topic {
+	case communication.TopicServerStarted:
+		hub.Broadcast([]byte("rp"))
+	case communication.TopicServerState:
+		hub.Broadcast([]byte("uss"))
+		hub.Broadcast([]byte("map"))
+	case communication.TopicServerPacket:
+		if payload, ok := communication.ServerPackets.Data(msg); ok {
+			hub.Broadcast(payload)
+		}
+	case communication.TopicClientStarted:
+		hub.Broadcast([]byte("cnu"))
+		hub.Broadcast([]byte("map"))
+	case communication.TopicClientState:
+		hub.Broadcast([]byte("usu" + strconv.Itoa(msg.ClientID)))
+		hub.Broadcast([]byte("map"))
+	case communication.TopicClientsStopped:
+		hub.Broadcast([]byte("map"))
+	case communication.TopicDatagramsCleared:
+		ids, _ := communication.DatagramsCleared.Data(msg)
+		for _, id := range ids {
+			hub.Broadcast([]byte("usu" + strconv.Itoa(id)))
+		}
+		hub.Broadcast([]byte("map"))
+	case communication.TopicPacket:
+		if event, ok := communication.Packets.Data(msg); ok {
+			s.forwardPacket(hub, msg.ClientID, event)
+		}
+	case communication.TopicSendJob:
+		if job, ok := communication.SendJobs.Data(msg); ok {
+			s.forwardMessage(hub, ws.WebSocketMessage{
+				Type:    ws.TypeSendJob,
+				Content: "job," + strconv.Itoa(job.ID) + "," + string(job.State),
+				Data:    job,
+			})
+		}
+	case communication.TopicFuzz:
+		hub.Broadcast([]byte("fzu"))
+	case communication.TopicLogLevels:
+		hub.Broadcast([]byte("lvu"))
+	case communication.TopicReset:
+		if response, ok := communication.Resets.Data(msg); ok {
+			s.forwardMessage(hub, ws.WebSocketMessage{
+				Type:    ws.TypeReset,
+				Content: "rst",
+				Data:    response,
+			})
+		}
+		hub.Broadcast([]byte("rst"))
+		hub.Broadcast([]byte("map"))
+	case communication.TopicLog:
+		if record, ok := communication.Logs.Data(msg); ok {
+			hub.BroadcastLog(record.JSON)
+		}
+	}
+}
+
+// forwardPacket announces a datagram of a client: its new state and
+// sequence number, the changed map and the packet itself as short command
+// and as PKT message carrying the dissection
+func (s *Server) forwardPacket(hub *ws.WebSocketHub, clientID int, event api.PacketEvent) {
+	hub.Broadcast([]byte("usu" + strconv.Itoa(clientID)))
+	hub.Broadcast([]byte("dgm," + strconv.Itoa(clientID) + "," + strconv.Itoa(event.Seq)))
+	hub.Broadcast([]byte("map"))
+	content := "pkt," + strconv.Itoa(event.FromClientID) + "," + strconv.Itoa(event.ToClientID) + "," + strconv.Itoa(int(event.Direction))
+	hub.Broadcast([]byte(content))
+	s.forwardMessage(hub, ws.WebSocketMessage{
+		Type:    ws.TypePacket,
+		Content: content,
+		Data:    event,
+	})
+}
+
+func (s *Server) forwardMessage(hub *ws.WebSocketHub, msg ws.WebSocketMessage) {
+	if err := hub.BroadcastMessage(msg); err != nil {
+		s.logger.WithField("caller", "web").WithError(err).Errorf("Can't broadcast %s message", msg.Type)
+	}
+}
+
+// publishFuzz is the OnUpdate func of the fuzzer
+func (s *Server) publishFuzz(summary fuzz.Summary) {
+	communication.FuzzUpdates.Publish(s.bus, communication.InternalMessage{
+		Caller: communication.CallerWebServer,
+	}, summary)
+}
+
+// publishSendJob is the onUpdate func of the send jobs
+func (s *Server) publishSendJob(job api.SendJob) {
+	communication.SendJobs.Publish(s.bus, communication.InternalMessage{
+		Caller:   communication.CallerWebServer,
+		ClientID: job.ClientID,
+	}, job)
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/communication"
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	"github.com/auraspeak/debug-ui/internal/ws"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/auraspeak/server/pkg/tracer"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eventContents(events []ws.Event) []string {
+	contents := make([]string, 0, len(events))
+	for _, e := range events {
+		contents = append(contents, e.Content)
+	}
+	return contents
+}
+
+func TestServer_ForwardToWebSocket(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	communication.Packets.Publish(server.bus, communication.InternalMessage{ClientID: 3}, packetEvent(3, 0, api.Datagram{Seq: 7, Direction: api.ClientToServer}))
+	communication.DatagramsCleared.Publish(server.bus, communication.InternalMessage{}, []int{1, 2})
+	communication.SendJobs.Publish(server.bus, communication.InternalMessage{}, api.SendJob{ID: 4, State: api.SendJobDone})
+	// Consumed by other subscribers only
+	communication.Traces.Publish(server.bus, communication.InternalMessage{}, tracer.TraceEvent{})
+
+	backlog, _, unsubscribe := server.wsHub.Subscribe(0, nil)
+	defer unsubscribe()
+	assert.Equal(t, []string{"usu3", "dgm,3,7", "map", "pkt,3,0,1", "usu1", "usu2", "map", "job,4,done"}, eventContents(backlog))
+	assert.Equal(t, ws.EventPacket, backlog[3].Kind)
+	assert.Contains(t, string(backlog[3].Data), `"seq":7`)
+	assert.Equal(t, map[communication.Topic]uint64{
+		communication.TopicPacket:           1,
+		communication.TopicDatagramsCleared: 1,
+		communication.TopicSendJob:          1,
+		communication.TopicTrace:            1,
+	}, server.events.Counts())
+}
+
+func TestServer_InvalidBusMessage(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	hook := logtest.NewLocal(server.logger)
+
+	// Data of the wrong type is dropped and logged
+	server.bus.Publish(communication.InternalMessage{Topic: communication.TopicPacket, Data: []int{3}})
+
+	backlog, _, unsubscribe := server.wsHub.Subscribe(0, func(e ws.Event) bool { return e.Kind != ws.EventLog })
+	defer unsubscribe()
+	assert.Empty(t, backlog)
+	assert.Zero(t, server.events.Counts()[communication.TopicPacket])
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, log.ErrorLevel, hook.LastEntry().Level)
+	assert.Equal(t, "Dropped packet message with data of type []int", hook.LastEntry().Message)
+}
+
+func TestServer_LogsThroughBus(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	logger := log.New()
+	entry := logger.WithField("caller", "web").WithTime(time.Now())
+	entry.Level = log.InfoLevel
+	entry.Message = `say "hi"`
+
+	require.NoError(t, server.logHook.Fire(entry))
+
+	stored := server.logs.Query(logstore.Query{})
+	require.Len(t, stored, 1)
+	assert.Equal(t, `say "hi"`, stored[0].Message)
+	backlog, _, unsubscribe := server.wsHub.Subscribe(0, nil)
+	defer unsubscribe()
+	require.Len(t, backlog, 1)
+	assert.Equal(t, ws.EventLog, backlog[0].Kind)
+	assert.Contains(t, string(backlog[0].Data), `"msg":"say \"hi\""`)
+	assert.Equal(t, uint64(1), server.events.Counts()[communication.TopicLog])
+}
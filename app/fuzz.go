@@ -0,0 +1,310 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/auraspeak/client"
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/communication"
+	"github.com/auraspeak/debug-ui/internal/fuzz"
+	"github.com/auraspeak/debug-ui/internal/loglevel"
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	"github.com/auraspeak/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+// fuzzTarget sends fuzz inputs through one debug client and uses the UDP
+// server state, echo probes and server error logs as oracle
+type fuzzTarget struct {
+	s         *Server
+	clientID  int
+	client    *client.Client
+	errorLogs *errorLogCounter
+	probes    int
+}
+
+func (t *fuzzTarget) Send(b []byte) error {
+	return t.client.Send(b)
+}
+
+func (t *fuzzTarget) Alive() bool {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	return t.s.udpServer != nil && t.s.udpServer.ServerState.IsAlive
+}
+
+// Probe sends a unique DebugAny packet and waits until the server echoed it
+// back to the client
+func (t *fuzzTarget) Probe(timeout time.Duration) error {
+	t.probes++
+	payload := []byte("fuzz-probe-" + strconv.Itoa(t.probes))
+	seen := t.s.lastSeq(t.clientID)
+	b, _, err := encodeDatagram(api.SendDatagramRequest{}, payload)
+	if err != nil {
+		return err
+	}
+	if err := t.client.Send(b); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if t.s.receivedSince(t.clientID, seen, payload) {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return errors.New("probe was not echoed in time")
+}
+
+func (t *fuzzTarget) ErrorLogs() uint64 {
+	return t.errorLogs.count.Load()
+}
+
+func (t *fuzzTarget) Restart() error {
+	if err := t.s.stopUDPServer(); err != nil && !errors.Is(err, errUDPServerNotRunning) {
+		return err
+	}
+	return t.s.startUDPServer()
+}
+
+// errorLogCounter counts the error log entries of the UDP server. Its
+// Handle is subscribed to the log entries while a fuzz run is active.
+type errorLogCounter struct {
+	count atomic.Uint64
+}
+
+func (c *errorLogCounter) Handle(_ communication.InternalMessage, record logstore.Record) {
+	if record.Entry.Level <= log.ErrorLevel && record.Entry.Caller == loglevel.CallerServer {
+		c.count.Add(1)
+	}
+}
+
+// fuzzPath resolves a corpus or crash directory of a request, which has to
+// stay inside the fuzz directory
+func (s *Server) fuzzPath(dir string) (string, error) {
+	if !filepath.IsLocal(dir) {
+		return "", errors.New("path must be relative to the fuzz directory and stay inside it: " + dir)
+	}
+	return filepath.Join(s.config.FuzzDir, dir), nil
+}
+
+// lastSeq returns the sequence number of the newest datagram of a client
+func (s *Server) lastSeq(clientID int) int {
+	uc, _ := s.clients.Get(clientID)
+	return uc.LastSeq
+}
+
+// receivedSince reports whether a client received payload in a datagram
+// newer than since
+func (s *Server) receivedSince(clientID int, since int, payload []byte) bool {
+	uc, _ := s.clients.Get(clientID)
+	for _, d := range uc.Datagrams {
+		if d.Seq > since && d.Direction == api.ServerToClient && string(d.Message) == string(payload) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) StartFuzz(w http.ResponseWriter, r *http.Request) {
+	var req api.StartFuzzRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+
+	// Collect the client and its recorded datagrams as seeds
+	uc, _ := s.clients.Get(req.ClientID)
+	fuzzClient, running := uc.Client, uc.Running
+	seeds := []fuzz.Input{}
+	for _, d := range uc.Datagrams {
+		if d.Direction == api.ClientToServer && !d.Raw {
+			seeds = append(seeds, fuzz.SeedFromPayload(d.PacketType, d.Message))
+		}
+	}
+	s.mu.Lock()
+	serverRunning := s.udpServer != nil
+	s.mu.Unlock()
+
+	if fuzzClient == nil {
+		apiError := api.ApiError{
+			Code:      http.StatusNotFound,
+			ErrorCode: api.ErrClientNotFound,
+			Message:   "UDP client not found",
+		}
+		apiError.Send(w)
+		return
+	}
+	if !running {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrClientNotRunning,
+			Message:   "Client is not running",
+		}
+		apiError.Send(w)
+		return
+	}
+	if !serverRunning {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrServerNotRunning,
+			Message:   "UDP server is not running",
+		}
+		apiError.Send(w)
+		return
+	}
+
+	crashDir, err := s.fuzzPath(cmp.Or(req.CrashDir, "crashes"))
+	if err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidParameter,
+			Message:   "Invalid crash directory",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	if req.CorpusDir != "" {
+		corpusDir, err := s.fuzzPath(req.CorpusDir)
+		if err != nil {
+			apiError := api.ApiError{
+				Code:      http.StatusBadRequest,
+				ErrorCode: api.ErrInvalidCorpus,
+				Message:   "Invalid corpus directory",
+				Details:   err.Error(),
+			}
+			apiError.Send(w)
+			return
+		}
+		corpus, err := fuzz.LoadCorpus(corpusDir, protocol.PacketTypeDebugAny)
+		if err != nil {
+			apiError := api.ApiError{
+				Code:      http.StatusBadRequest,
+				ErrorCode: api.ErrInvalidCorpus,
+				Message:   "Can't load corpus",
+				Details:   err.Error(),
+			}
+			apiError.Send(w)
+			return
+		}
+		seeds = append(seeds, corpus...)
+	}
+
+	errorLogs := &errorLogCounter{}
+	target := &fuzzTarget{
+		s:         s,
+		clientID:  req.ClientID,
+		client:    fuzzClient,
+		errorLogs: errorLogs,
+	}
+	fuzzer, err := fuzz.New(fuzz.Config{
+		Iterations:     req.Iterations,
+		Seed:           req.Seed,
+		MaxMutations:   req.MaxMutations,
+		ProbeEvery:     req.ProbeEvery,
+		ProbeTimeout:   time.Duration(req.ProbeTimeoutMs) * time.Millisecond,
+		MinimizeBudget: req.MinimizeBudget,
+		CrashDir:       crashDir,
+	}, target, seeds)
+	if err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrNoFuzzSeeds,
+			Message:   "No seeds to fuzz with",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	fuzzer.OnUpdate = s.publishFuzz
+
+	s.fuzzMu.Lock()
+	if s.fuzzActive {
+		s.fuzzMu.Unlock()
+		apiError := api.ApiError{
+			Code:      http.StatusConflict,
+			ErrorCode: api.ErrFuzzRunning,
+			Message:   "Fuzzer is already running",
+		}
+		apiError.Send(w)
+		return
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.fuzzer = fuzzer
+	s.fuzzCancel = cancel
+	s.fuzzActive = true
+	s.fuzzMu.Unlock()
+
+	unsubscribe := communication.Logs.Subscribe(s.bus, errorLogs.Handle)
+	s.goroutines.Go(&s.shutdownWg, "fuzz", func() {
+		defer func() {
+			s.fuzzMu.Lock()
+			s.fuzzActive = false
+			s.fuzzMu.Unlock()
+		}()
+		defer cancel()
+		defer unsubscribe()
+		summary := fuzzer.Run(ctx)
+		s.logger.WithField("caller", "web").Infof("Fuzz run finished: %d inputs, %d findings", summary.Executed, len(summary.Findings))
+	})
+
+	apiSuccess := api.ApiSuccess{
+		Message: "Fuzzer started",
+		Details: "seeds: " + strconv.Itoa(len(seeds)),
+	}
+	apiSuccess.Send(w)
+}
+
+func (s *Server) StopFuzz(w http.ResponseWriter, r *http.Request) {
+	if !s.stopFuzz() {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrFuzzNotRunning,
+			Message:   "Fuzzer is not running",
+		}
+		apiError.Send(w)
+		return
+	}
+	apiSuccess := api.ApiSuccess{
+		Message: "Fuzzer stopped",
+	}
+	apiSuccess.Send(w)
+}
+
+// stopFuzz cancels the active fuzz run and reports whether there was one
+func (s *Server) stopFuzz() bool {
+	s.fuzzMu.Lock()
+	defer s.fuzzMu.Unlock()
+	if !s.fuzzActive {
+		return false
+	}
+	s.fuzzCancel()
+	return true
+}
+
+func (s *Server) GetFuzzSummary(w http.ResponseWriter, r *http.Request) {
+	s.fuzzMu.Lock()
+	fuzzer := s.fuzzer
+	s.fuzzMu.Unlock()
+
+	resp := api.FuzzSummaryResponse{Summary: fuzz.Summary{Findings: []fuzz.Finding{}}}
+	if fuzzer != nil {
+		resp.Summary = fuzzer.Summary()
+	}
+	resp.Send(w)
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/communication"
+	"github.com/auraspeak/debug-ui/internal/loglevel"
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	"github.com/auraspeak/server/pkg/debugui"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_GetFuzzSummary_NoRun(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	req := httptest.NewRequest("GET", "/api/fuzz/get", nil)
+	rr := httptest.NewRecorder()
+
+	server.GetFuzzSummary(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response api.FuzzSummaryResponse
+	err := json.Unmarshal(rr.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.False(t, response.Running)
+	assert.Empty(t, response.Findings)
+}
+
+func TestServer_StartFuzz_ClientNotFound(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	body, _ := json.Marshal(api.StartFuzzRequest{ClientID: 999})
+	req := httptest.NewRequest("POST", "/api/fuzz/start", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.StartFuzz(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestServer_StartFuzz_InvalidBody(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	req := httptest.NewRequest("POST", "/api/fuzz/start", bytes.NewReader([]byte("{")))
+	rr := httptest.NewRecorder()
+
+	server.StartFuzz(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestServer_StopFuzz_NotRunning(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	req := httptest.NewRequest("POST", "/api/fuzz/stop", nil)
+	rr := httptest.NewRecorder()
+
+	server.StopFuzz(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestErrorLogCounter(t *testing.T) {
+	counter := &errorLogCounter{}
+	publish := func(caller string, level log.Level) {
+		counter.Handle(communication.InternalMessage{}, logstore.Record{Entry: logstore.Entry{Caller: caller, Level: level}})
+	}
+
+	publish(loglevel.CallerWeb, log.ErrorLevel)
+	publish(loglevel.CallerClient, log.ErrorLevel)
+	publish(loglevel.CallerServer, log.WarnLevel)
+	publish(loglevel.CallerServer, log.ErrorLevel)
+
+	assert.Equal(t, uint64(1), counter.count.Load())
+}
+
+func TestServer_FuzzPath(t *testing.T) {
+	server := NewServerWithConfig(8080, Config{FuzzDir: t.TempDir()}, debugui.Config{})
+
+	for _, dir := range []string{"../corpus", "/etc", "a/../../b"} {
+		_, err := server.fuzzPath(dir)
+		assert.Error(t, err, dir)
+	}
+	path, err := server.fuzzPath("corpus/ping")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(server.config.FuzzDir, "corpus", "ping"), path)
+}
+
+func TestServer_StopFuzz_Active(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	// A run is active from its start, before the fuzzer reports it running
+	server.fuzzMu.Lock()
+	server.fuzzActive, server.fuzzCancel = true, cancel
+	server.fuzzMu.Unlock()
+
+	req := httptest.NewRequest("POST", "/api/fuzz/stop", nil)
+	rr := httptest.NewRecorder()
+
+	server.StopFuzz(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Error(t, ctx.Err())
+}
@@ -0,0 +1,282 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/communication"
+	"github.com/auraspeak/debug-ui/internal/middleware"
+	"github.com/auraspeak/debug-ui/internal/services"
+)
+
+// maxGroupStart limits how many clients one request may start into a group
+const maxGroupStart = 256
+
+// normalizeTags trims the tags and drops empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// hasTags reports whether the client carries all of the given tags
+func hasTags(uc api.UDPClient, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(uc.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func newUDPClientListItem(uc api.UDPClient) api.UDPClientListItem {
+	return api.UDPClientListItem{
+		Id:            uc.ID,
+		Name:          uc.Name,
+		Group:         uc.Group,
+		Tags:          uc.Tags,
+		Running:       uc.Running,
+		DatagramCount: len(uc.Datagrams),
+		CreatedAt:     uc.CreatedAt,
+		LastActivity:  uc.LastActivity,
+	}
+}
+
+// groupMembers returns the clients of a group ordered by ID
+func (s *Server) groupMembers(group string) []api.UDPClient {
+	return s.clients.Filter(func(uc api.UDPClient) bool {
+		return uc.Group == group
+	})
+}
+
+// groupFromQuery reads the required group query parameter
+func groupFromQuery(w http.ResponseWriter, r *http.Request) (string, bool) {
+	group := strings.TrimSpace(r.URL.Query().Get("group"))
+	if group == "" {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrMissingParameter,
+			Message:   "Group is required",
+		}
+		apiError.Send(w)
+		return "", false
+	}
+	return group, true
+}
+
+func groupNotFound(w http.ResponseWriter) {
+	apiError := api.ApiError{
+		Code:      http.StatusNotFound,
+		ErrorCode: api.ErrGroupNotFound,
+		Message:   "Group not found",
+	}
+	apiError.Send(w)
+}
+
+func (s *Server) SetClientLabels(w http.ResponseWriter, r *http.Request) {
+	var req api.SetClientLabelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+
+	uc, ok := s.clients.Update(req.Id, func(uc *api.UDPClient) {
+		uc.Group = strings.TrimSpace(req.Group)
+		uc.Tags = normalizeTags(req.Tags)
+	})
+	if ok {
+		s.bus.Publish(communication.InternalMessage{
+			Caller:   communication.CallerWebServer,
+			Topic:    communication.TopicClientState,
+			ClientID: uc.ID,
+		})
+		item := newUDPClientListItem(uc)
+		item.Send(w)
+		return
+	}
+	apiError := api.ApiError{
+		Code:      http.StatusNotFound,
+		ErrorCode: api.ErrClientNotFound,
+		Message:   "UDP client not found",
+	}
+	apiError.Send(w)
+}
+
+func (s *Server) GetAllGroups(w http.ResponseWriter, r *http.Request) {
+	groups := map[string]*api.ClientGroup{}
+	for _, uc := range s.clients.Snapshot() {
+		if uc.Group == "" {
+			continue
+		}
+		g, ok := groups[uc.Group]
+		if !ok {
+			g = &api.ClientGroup{Name: uc.Group, ClientIDs: []int{}}
+			groups[uc.Group] = g
+		}
+		g.ClientIDs = append(g.ClientIDs, uc.ID)
+		if uc.Running {
+			g.Running++
+		}
+	}
+
+	response := api.AllGroupsResponse{Groups: []api.ClientGroup{}}
+	for _, g := range groups {
+		slices.Sort(g.ClientIDs)
+		response.Groups = append(response.Groups, *g)
+	}
+	slices.SortFunc(response.Groups, func(a, b api.ClientGroup) int { return strings.Compare(a.Name, b.Name) })
+	response.Send(w)
+}
+
+func (s *Server) StartGroup(w http.ResponseWriter, r *http.Request) {
+	var req api.StartGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	group := strings.TrimSpace(req.Group)
+	if group == "" {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrMissingParameter,
+			Message:   "Group is required",
+		}
+		apiError.Send(w)
+		return
+	}
+	if req.Count < 1 || req.Count > maxGroupStart {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidParameter,
+			Message:   "Count must be between 1 and " + strconv.Itoa(maxGroupStart),
+		}
+		apiError.Send(w)
+		return
+	}
+
+	tags := normalizeTags(req.Tags)
+	response := api.GroupActionResponse{Group: group, Results: []api.GroupMemberResult{}}
+	started := 0
+	for range req.Count {
+		// Every client gets its own copy of the tags
+		udpClient, err := s.startUDPClient(r.Context(), "", group, slices.Clone(tags))
+		if err != nil {
+			// Generated names are never taken
+			middleware.Log(s.logger, r).WithError(err).Error("Can't start group client")
+			response.Results = append(response.Results, api.GroupMemberResult{Id: -1, Error: err.Error()})
+			continue
+		}
+		started++
+		response.Results = append(response.Results, api.GroupMemberResult{Id: udpClient.ID, Name: udpClient.Name})
+	}
+	middleware.Log(s.logger, r).Infof("Started %d of %d UDP clients into group %s", started, req.Count, group)
+	response.Send(w)
+}
+
+func (s *Server) StopGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := groupFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	members := s.groupMembers(group)
+	if len(members) == 0 {
+		groupNotFound(w)
+		return
+	}
+	response := api.GroupActionResponse{Group: group, Results: []api.GroupMemberResult{}}
+	scopes := make([]*services.Scope, 0, len(members))
+	ids := make([]int, 0, len(members))
+	for _, uc := range members {
+		scopes = append(scopes, s.stopUDPClient(uc))
+		ids = append(ids, uc.ID)
+		response.Results = append(response.Results, api.GroupMemberResult{Id: uc.ID, Name: uc.Name})
+	}
+	s.waitClients(scopes...)
+	communication.ClientsStopped.Publish(s.bus, communication.InternalMessage{
+		Caller: communication.CallerWebServer,
+	}, ids)
+	response.Send(w)
+}
+
+func (s *Server) SendGroup(w http.ResponseWriter, r *http.Request) {
+	var req api.SendGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	group := strings.TrimSpace(req.Group)
+
+	members := s.groupMembers(group)
+	if group == "" || len(members) == 0 {
+		groupNotFound(w)
+		return
+	}
+
+	// The members are sent from one after another, each locked only while its datagram is recorded
+	response := api.GroupActionResponse{Group: group, Results: []api.GroupMemberResult{}}
+	for _, uc := range members {
+		sendReq := req.Request
+		sendReq.Id = uc.ID
+		result := api.GroupMemberResult{Id: uc.ID, Name: uc.Name}
+		if apiError := s.sendDatagram(r.Context(), sendReq); apiError != nil {
+			result.Error = apiError.Error()
+		}
+		response.Results = append(response.Results, result)
+	}
+	response.Send(w)
+}
+
+func (s *Server) ClearGroup(w http.ResponseWriter, r *http.Request) {
+	group, ok := groupFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	members := s.groupMembers(group)
+	if len(members) == 0 {
+		groupNotFound(w)
+		return
+	}
+	response := api.GroupActionResponse{Group: group, Results: []api.GroupMemberResult{}}
+	ids := make([]int, 0, len(members))
+	for _, uc := range members {
+		s.clients.Update(uc.ID, func(uc *api.UDPClient) {
+			uc.Datagrams = []api.Datagram{}
+		})
+		ids = append(ids, uc.ID)
+		response.Results = append(response.Results, api.GroupMemberResult{Id: uc.ID, Name: uc.Name})
+	}
+	communication.DatagramsCleared.Publish(s.bus, communication.InternalMessage{
+		Caller: communication.CallerWebServer,
+	}, ids)
+	response.Send(w)
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/services"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGroupTestServer returns a server with stopped clients that don't need a UDP server
+func newGroupTestServer(t *testing.T) *Server {
+	server := NewServer(8080, 9090, debugui.Config{})
+	addClient(t, server, api.UDPClient{ID: 1, Name: "alice", Group: "red", Tags: []string{"load", "eu"}, Datagrams: []api.Datagram{{Direction: api.ClientToServer, Message: []byte("a")}}})
+	addClient(t, server, api.UDPClient{ID: 2, Name: "bob", Group: "red", Tags: []string{"load"}, Datagrams: []api.Datagram{{Direction: api.ClientToServer, Message: []byte("b")}}})
+	addClient(t, server, api.UDPClient{ID: 3, Name: "carol", Datagrams: []api.Datagram{{Direction: api.ClientToServer, Message: []byte("c")}}})
+	return server
+}
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, normalizeTags([]string{" a", "", "b", "a "}))
+	assert.Equal(t, []string{}, normalizeTags(nil))
+}
+
+func TestServer_GetAllUDPClientPaginated_TagFilter(t *testing.T) {
+	server := newGroupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/client/get/all/paginated?tag=load&tag=eu", nil)
+	rr := httptest.NewRecorder()
+
+	server.GetAllUDPClientPaginated(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response api.UDPClientPaginatedRespone
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response.Items, 1)
+	assert.Equal(t, "alice", response.Items[0].Name)
+	assert.Equal(t, "red", response.Items[0].Group)
+}
+
+func TestServer_GetClientMap_TagFilter(t *testing.T) {
+	server := newGroupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/client/map?tag=load", nil)
+	rr := httptest.NewRecorder()
+
+	server.GetClientMap(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response api.ClientMapResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Len(t, response.Clients, 2)
+	assert.Len(t, response.Connections, 2)
+}
+
+func TestServer_SetClientLabels(t *testing.T) {
+	server := newGroupTestServer(t)
+
+	body, _ := json.Marshal(api.SetClientLabelsRequest{Id: 3, Group: " blue ", Tags: []string{"x", "x", " "}})
+	req := httptest.NewRequest("POST", "/api/client/labels", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.SetClientLabels(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "blue", clientByName(t, server, "carol").Group)
+	assert.Equal(t, []string{"x"}, clientByName(t, server, "carol").Tags)
+
+	body, _ = json.Marshal(api.SetClientLabelsRequest{Id: 42})
+	req = httptest.NewRequest("POST", "/api/client/labels", bytes.NewReader(body))
+	rr = httptest.NewRecorder()
+
+	server.SetClientLabels(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestServer_GetAllGroups(t *testing.T) {
+	server := newGroupTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/groups/all", nil)
+	rr := httptest.NewRecorder()
+
+	server.GetAllGroups(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response api.AllGroupsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response.Groups, 1)
+	assert.Equal(t, api.ClientGroup{Name: "red", ClientIDs: []int{1, 2}}, response.Groups[0])
+}
+
+func TestServer_StartGroup_InvalidRequest(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	tests := []struct {
+		name string
+		req  api.StartGroupRequest
+	}{
+		{"no group", api.StartGroupRequest{Count: 1}},
+		{"no count", api.StartGroupRequest{Group: "red"}},
+		{"too many", api.StartGroupRequest{Group: "red", Count: maxGroupStart + 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.req)
+			req := httptest.NewRequest("POST", "/api/groups/start", bytes.NewReader(body))
+			rr := httptest.NewRecorder()
+
+			server.StartGroup(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.Zero(t, server.clients.Len())
+		})
+	}
+}
+
+func TestServer_StartGroup_ReportsFailedStarts(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	defer server.cancel()
+	// The first start gets an ID that is already taken
+	services.ResetIDs(2000)
+	addClient(t, server, api.UDPClient{ID: 2000, Name: "alice"})
+
+	body, _ := json.Marshal(api.StartGroupRequest{Group: "red", Count: 2})
+	req := httptest.NewRequest("POST", "/api/groups/start", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.StartGroup(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response api.GroupActionResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response.Results, 2)
+	assert.Equal(t, -1, response.Results[0].Id)
+	assert.NotEmpty(t, response.Results[0].Error)
+	assert.Equal(t, 2001, response.Results[1].Id)
+	assert.Empty(t, response.Results[1].Error)
+}
+
+func TestServer_StopGroup_NotFound(t *testing.T) {
+	server := newGroupTestServer(t)
+
+	req := httptest.NewRequest("POST", "/api/groups/stop?group=blue", nil)
+	rr := httptest.NewRecorder()
+
+	server.StopGroup(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestServer_SendGroup_ReportsMemberErrors(t *testing.T) {
+	server := newGroupTestServer(t)
+
+	body, _ := json.Marshal(api.SendGroupRequest{Group: "red", Request: api.SendDatagramRequest{Message: "hi", Format: "text"}})
+	req := httptest.NewRequest("POST", "/api/groups/send", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.SendGroup(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response api.GroupActionResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Len(t, response.Results, 2)
+	for _, result := range response.Results {
+		assert.NotEmpty(t, result.Error)
+	}
+}
+
+func TestServer_ClearGroup(t *testing.T) {
+	server := newGroupTestServer(t)
+
+	req := httptest.NewRequest("POST", "/api/groups/clear?group=red", nil)
+	rr := httptest.NewRecorder()
+
+	server.ClearGroup(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, clientByName(t, server, "alice").Datagrams)
+	assert.Empty(t, clientByName(t, server, "bob").Datagrams)
+	assert.Len(t, clientByName(t, server, "carol").Datagrams, 1)
+}
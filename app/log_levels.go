@@ -0,0 +1,170 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/communication"
+	"github.com/auraspeak/debug-ui/internal/loglevel"
+	log "github.com/sirupsen/logrus"
+)
+
+// logLevelCallers are always listed in the effective levels
+var logLevelCallers = []string{loglevel.CallerWeb, loglevel.CallerServer, loglevel.CallerClient}
+
+// setLogLevel sets the level of a client, a caller or, without both, the
+// global level. An empty level or "reset" removes a client or caller level.
+func (s *Server) setLogLevel(ctx context.Context, caller string, clientID *int, levelStr string) error {
+	var level *log.Level
+	if levelStr != "" && levelStr != "reset" {
+		parsed, err := log.ParseLevel(levelStr)
+		if err != nil {
+			return err
+		}
+		level = &parsed
+	}
+	switch {
+	case clientID != nil:
+		s.logLevels.SetClient(*clientID, level)
+	case caller != "":
+		s.logLevels.SetCaller(caller, level)
+	case level == nil:
+		return errors.New("the global level can't be reset")
+	default:
+		s.logLevels.SetGlobal(*level)
+	}
+	s.logger.WithContext(ctx).WithField("caller", "web").Infof("Log level of %s set to %s", logLevelTarget(caller, clientID), cmp.Or(levelStr, "reset"))
+	s.bus.Publish(communication.InternalMessage{
+		Caller: communication.CallerWebServer,
+		Topic:  communication.TopicLogLevels,
+	})
+	return nil
+}
+
+func logLevelTarget(caller string, clientID *int) string {
+	switch {
+	case clientID != nil:
+		return "client " + strconv.Itoa(*clientID)
+	case caller != "":
+		return "caller " + caller
+	default:
+		return "all callers"
+	}
+}
+
+func (s *Server) logLevelsResponse() api.LogLevelsResponse {
+	levels := s.logLevels.Levels()
+	response := api.LogLevelsResponse{
+		Global:    levels.Global.String(),
+		Callers:   make(map[string]string, len(levels.Callers)),
+		Clients:   make(map[int]string, len(levels.Clients)),
+		Effective: []api.EffectiveLogLevel{},
+	}
+	callers := slices.Clone(logLevelCallers)
+	for caller, level := range levels.Callers {
+		response.Callers[caller] = level.String()
+		if !slices.Contains(callers, caller) {
+			callers = append(callers, caller)
+		}
+	}
+	for id, level := range levels.Clients {
+		response.Clients[id] = level.String()
+	}
+	slices.Sort(callers[len(logLevelCallers):])
+	for _, caller := range callers {
+		_, override := levels.Callers[caller]
+		response.Effective = append(response.Effective, api.EffectiveLogLevel{
+			Caller:   caller,
+			Level:    s.logLevels.Effective(caller, nil).String(),
+			Override: override,
+		})
+	}
+
+	for _, uc := range s.clients.Snapshot() {
+		id := uc.ID
+		_, override := levels.Clients[id]
+		response.Effective = append(response.Effective, api.EffectiveLogLevel{
+			Caller:   loglevel.CallerClient,
+			ClientID: &id,
+			Name:     uc.Name,
+			Level:    s.logLevels.Effective(loglevel.CallerClient, &id).String(),
+			Override: override,
+		})
+	}
+	return response
+}
+
+// GetLogLevels returns the configured and the effective log levels
+func (s *Server) GetLogLevels(w http.ResponseWriter, r *http.Request) {
+	response := s.logLevelsResponse()
+	response.Send(w)
+}
+
+// SetLogLevel changes a log level at runtime
+func (s *Server) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req api.SetLogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	if err := s.setLogLevel(r.Context(), req.Caller, req.ClientID, req.Level); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidParameter,
+			Message:   "Level is invalid",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	response := s.logLevelsResponse()
+	response.Send(w)
+}
+
+// handleWSCommand handles the commands WebSocket clients send:
+// lvl,<level>: sets the global log level
+// lvl,<caller>,<level>: sets the level of a caller, "client:<id>" of a single client
+func (s *Server) handleWSCommand(msg string) bool {
+	parts := strings.Split(strings.TrimSpace(msg), ",")
+	if parts[0] != "lvl" {
+		return false
+	}
+	var err error
+	switch len(parts) {
+	case 2:
+		err = s.setLogLevel(s.ctx, "", nil, parts[1])
+	case 3:
+		caller := parts[1]
+		var clientID *int
+		if idStr, ok := strings.CutPrefix(caller, "client:"); ok {
+			id, convErr := strconv.Atoi(idStr)
+			if convErr != nil {
+				err = convErr
+				break
+			}
+			clientID = &id
+		}
+		if err == nil {
+			err = s.setLogLevel(s.ctx, caller, clientID, parts[2])
+		}
+	default:
+		err = errors.New("expected lvl,<level> or lvl,<caller>,<level>")
+	}
+	if err != nil {
+		s.logger.WithField("caller", "web").WithError(err).Warnf("Invalid WebSocket command %q", msg)
+	}
+	return true
+}
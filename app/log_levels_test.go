@@ -0,0 +1,75 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/loglevel"
+	"github.com/auraspeak/server/pkg/debugui"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_SetLogLevel(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	addClient(t, server, api.UDPClient{ID: 2, Name: "alice"})
+
+	req := httptest.NewRequest("POST", "/api/logs/levels", strings.NewReader(`{"caller":"server","level":"trace"}`))
+	rr := httptest.NewRecorder()
+	server.SetLogLevel(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest("POST", "/api/logs/levels", strings.NewReader(`{"clientId":2,"level":"error"}`))
+	rr = httptest.NewRecorder()
+	server.SetLogLevel(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response api.LogLevelsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "trace", response.Callers["server"])
+	assert.Equal(t, "error", response.Clients[2])
+	levels := map[string]api.EffectiveLogLevel{}
+	for _, e := range response.Effective {
+		key := e.Caller
+		if e.ClientID != nil {
+			key = e.Name
+		}
+		levels[key] = e
+	}
+	assert.Equal(t, "trace", levels["server"].Level)
+	assert.True(t, levels["server"].Override)
+	assert.Equal(t, response.Global, levels["web"].Level)
+	assert.Equal(t, "error", levels["alice"].Level)
+}
+
+func TestServer_SetLogLevel_Invalid(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	for _, body := range []string{"{", `{"level":"loud"}`, `{"level":"reset"}`} {
+		req := httptest.NewRequest("POST", "/api/logs/levels", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+
+		server.SetLogLevel(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
+
+func TestServer_HandleWSCommand(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	assert.False(t, server.handleWSCommand("hello"))
+	assert.True(t, server.handleWSCommand("lvl,web,debug"))
+	assert.True(t, server.handleWSCommand("lvl,client:5,trace"))
+	assert.True(t, server.handleWSCommand("lvl,warning"))
+
+	levels := server.logLevels.Levels()
+	assert.Equal(t, log.WarnLevel, levels.Global)
+	assert.Equal(t, log.DebugLevel, levels.Callers[loglevel.CallerWeb])
+	assert.Equal(t, log.TraceLevel, levels.Clients[5])
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	"github.com/auraspeak/debug-ui/internal/middleware"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultLogLimit = 1000
+
+// parseLogQuery reads the filters of GET /api/logs. The returned message is
+// not empty if a parameter is invalid.
+func parseLogQuery(r *http.Request) (logstore.Query, string) {
+	query := r.URL.Query()
+	q := logstore.Query{
+		Caller: query.Get("caller"),
+		Text:   query.Get("q"),
+		Limit:  defaultLogLimit,
+	}
+	if levelStr := query.Get("level"); levelStr != "" {
+		level, err := log.ParseLevel(levelStr)
+		if err != nil {
+			return q, "Level is invalid"
+		}
+		q.MinLevel = level
+		q.HasLevel = true
+	}
+	if clientStr := query.Get("clientId"); clientStr != "" {
+		id, err := strconv.Atoi(clientStr)
+		if err != nil {
+			return q, "Client ID is invalid"
+		}
+		q.ClientID = &id
+	}
+	for name, t := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		if str := query.Get(name); str != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, str)
+			if err != nil {
+				return q, "Time '" + name + "' must be RFC 3339"
+			}
+			*t = parsed
+		}
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > logstore.DefaultCapacity {
+			return q, "Limit must be between 1 and " + strconv.Itoa(logstore.DefaultCapacity)
+		}
+		q.Limit = limit
+	}
+	return q, ""
+}
+
+// GetLogs returns the stored log entries matching the query parameters,
+// oldest first. With format=ndjson they are downloaded one JSON object per line.
+func (s *Server) GetLogs(w http.ResponseWriter, r *http.Request) {
+	q, invalid := parseLogQuery(r)
+	if invalid != "" {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidParameter,
+			Message:   invalid,
+		}
+		apiError.Send(w)
+		return
+	}
+	if s.logs == nil {
+		apiError := api.ApiError{
+			Code:      http.StatusServiceUnavailable,
+			ErrorCode: api.ErrUnavailable,
+			Message:   "Log store is not available",
+		}
+		apiError.Send(w)
+		return
+	}
+	entries := s.logs.Query(q)
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		response := api.LogsResponse{
+			Entries: entries,
+			Stored:  s.logs.Len(),
+		}
+		response.Send(w)
+	case "ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="logs.ndjson"`)
+		w.WriteHeader(http.StatusOK)
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				middleware.Log(s.logger, r).WithError(err).Error("Can't write log entry")
+				return
+			}
+		}
+	default:
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidParameter,
+			Message:   "Format must be json or ndjson",
+		}
+		apiError.Send(w)
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	"github.com/auraspeak/server/pkg/debugui"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addLog(s *Server, level log.Level, msg string, fields log.Fields) {
+	e := log.NewEntry(log.StandardLogger()).WithFields(fields)
+	e.Level = level
+	e.Message = msg
+	e.Time = time.Now()
+	s.logs.Add(e)
+}
+
+func TestServer_GetLogs_Filters(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	addLog(server, log.DebugLevel, "debug", log.Fields{"caller": "web"})
+	addLog(server, log.WarnLevel, "warning", log.Fields{"caller": "UDP Client", "cid": 2})
+	addLog(server, log.ErrorLevel, "error", log.Fields{"caller": "UDP Client", "cid": 3})
+
+	req := httptest.NewRequest("GET", "/api/logs?level=warning&caller=UDP+Client&clientId=2", nil)
+	rr := httptest.NewRecorder()
+
+	server.GetLogs(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response api.LogsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Stored)
+	require.Len(t, response.Entries, 1)
+	assert.Equal(t, "warning", response.Entries[0].Message)
+}
+
+func TestServer_GetLogs_NDJSON(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	addLog(server, log.InfoLevel, "one", nil)
+	addLog(server, log.InfoLevel, "two", nil)
+
+	req := httptest.NewRequest("GET", "/api/logs?format=ndjson", nil)
+	rr := httptest.NewRecorder()
+
+	server.GetLogs(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	scanner := bufio.NewScanner(rr.Body)
+	messages := []string{}
+	for scanner.Scan() {
+		var e logstore.Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{"one", "two"}, messages)
+}
+
+func TestServer_GetLogs_InvalidQuery(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	for _, query := range []string{"level=loud", "clientId=x", "since=yesterday", "limit=0", "format=xml"} {
+		req := httptest.NewRequest("GET", "/api/logs?"+query, nil)
+		rr := httptest.NewRecorder()
+
+		server.GetLogs(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
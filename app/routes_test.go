@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+)
+
+// unversionedRoutes are served but not part of the REST API
+var unversionedRoutes = []string{"/ws", "/"}
+
+// TestServer_RoutesMatchOpenAPI compares the routes of the server with the
+// OpenAPI document, including routes registered outside the endpoint table
+func TestServer_RoutesMatchOpenAPI(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	doc := api.OpenAPI()
+
+	registered := map[string]bool{}
+	for _, pattern := range server.routes().Patterns() {
+		method, path, found := strings.Cut(pattern, " ")
+		if !found {
+			assert.Contains(t, unversionedRoutes, pattern, "%s is registered without method", pattern)
+			continue
+		}
+		registered[pattern] = true
+		assert.True(t, doc.Has(method, path), "%s is served but missing from the OpenAPI document", pattern)
+	}
+	for _, op := range doc.Operations() {
+		assert.True(t, registered[op], "%s is in the OpenAPI document but not served", op)
+	}
+}
+
+// TestServer_HandlerServesOpenAPI sends every documented operation to the
+// handler of the server. The handlers may answer 404 or 405 themselves, e.g.
+// for unknown clients, but then with a specific error code.
+func TestServer_HandlerServesOpenAPI(t *testing.T) {
+	server := NewServerWithConfig(8080, Config{TemplateDir: t.TempDir(), FuzzDir: t.TempDir()}, debugui.Config{})
+	defer server.Shutdown(time.Second)
+	handler := server.Handler()
+	// Streams end right away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, op := range api.OpenAPI().Operations() {
+		method, path, _ := strings.Cut(op, " ")
+		req := httptest.NewRequest(method, path, strings.NewReader("{}")).WithContext(ctx)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound && rr.Code != http.StatusMethodNotAllowed {
+			continue
+		}
+		var apiError api.ApiError
+		err := json.Unmarshal(rr.Body.Bytes(), &apiError)
+		if assert.NoError(t, err, "%s is not served: %d %s", op, rr.Code, rr.Body.String()) {
+			assert.NotContains(t, []api.ErrorCode{"", api.ErrNotFound}, apiError.ErrorCode, "%s is not served", op)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/middleware"
+	"github.com/auraspeak/debug-ui/internal/services"
+)
+
+// sendJobDatagram is the send function of the periodic send jobs
+func (s *Server) sendJobDatagram(req api.SendDatagramRequest) error {
+	if apiError := s.sendDatagram(s.ctx, req); apiError != nil {
+		return apiError
+	}
+	return nil
+}
+
+func (s *Server) StartSendJob(w http.ResponseWriter, r *http.Request) {
+	var req api.StartSendJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+
+	uc, found := s.clients.Get(req.ClientID)
+	if !found {
+		apiError := api.ApiError{
+			Code:      http.StatusNotFound,
+			ErrorCode: api.ErrClientNotFound,
+			Message:   "UDP client not found",
+		}
+		apiError.Send(w)
+		return
+	}
+	if !uc.Running {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrClientNotRunning,
+			Message:   "Client is not running",
+		}
+		apiError.Send(w)
+		return
+	}
+
+	// The job runs in the scope of its client and ends with it
+	job, err := s.sendJobs.StartIn(s.clients.Scope(req.ClientID), req)
+	if errors.Is(err, services.ErrScopeCancelled) {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrClientNotRunning,
+			Message:   "Client is not running",
+		}
+		apiError.Send(w)
+		return
+	}
+	if err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidSendJob,
+			Message:   "Invalid send job",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	middleware.Log(s.logger, r).Infof("Send job %d started for client %d", job.ID, job.ClientID)
+	job.Send(w)
+}
+
+func (s *Server) GetAllSendJobs(w http.ResponseWriter, r *http.Request) {
+	var clientID *int
+	if id := r.URL.Query().Get("clientId"); id != "" {
+		idInt, err := strconv.Atoi(id)
+		if err != nil {
+			apiError := api.ApiError{
+				Code:      http.StatusBadRequest,
+				ErrorCode: api.ErrInvalidParameter,
+				Message:   "Client ID is invalid",
+			}
+			apiError.Send(w)
+			return
+		}
+		clientID = &idInt
+	}
+	resp := api.AllSendJobsResponse{Jobs: s.sendJobs.List(clientID)}
+	resp.Send(w)
+}
+
+func (s *Server) PauseSendJob(w http.ResponseWriter, r *http.Request) {
+	s.changeSendJob(w, r, s.sendJobs.Pause)
+}
+
+func (s *Server) ResumeSendJob(w http.ResponseWriter, r *http.Request) {
+	s.changeSendJob(w, r, s.sendJobs.Resume)
+}
+
+func (s *Server) CancelSendJob(w http.ResponseWriter, r *http.Request) {
+	s.changeSendJob(w, r, s.sendJobs.Cancel)
+}
+
+// changeSendJob applies change to the job given by the id query parameter
+func (s *Server) changeSendJob(w http.ResponseWriter, r *http.Request, change func(id int) (api.SendJob, error)) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrMissingParameter,
+			Message:   "ID is required",
+		}
+		apiError.Send(w)
+		return
+	}
+	idInt, err := strconv.Atoi(id)
+	if err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidParameter,
+			Message:   "ID is invalid",
+		}
+		apiError.Send(w)
+		return
+	}
+	job, err := change(idInt)
+	if errors.Is(err, services.ErrSendJobNotFound) {
+		apiError := api.ApiError{
+			Code:      http.StatusNotFound,
+			ErrorCode: api.ErrSendJobNotFound,
+			Message:   "Send job not found",
+		}
+		apiError.Send(w)
+		return
+	}
+	if errors.Is(err, services.ErrSendJobFinished) {
+		apiError := api.ApiError{
+			Code:      http.StatusConflict,
+			ErrorCode: api.ErrSendJobFinished,
+			Message:   "Send job already finished",
+		}
+		apiError.Send(w)
+		return
+	}
+	job.Send(w)
+}
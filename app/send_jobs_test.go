@@ -0,0 +1,74 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_StartSendJob_ClientNotFound(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	body, _ := json.Marshal(api.StartSendJobRequest{ClientID: 999, IntervalMs: 10})
+	req := httptest.NewRequest("POST", "/api/client/jobs/start", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.StartSendJob(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestServer_GetAllSendJobs_Empty(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	req := httptest.NewRequest("GET", "/api/client/jobs/all?clientId=1", nil)
+	rr := httptest.NewRecorder()
+
+	server.GetAllSendJobs(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response api.AllSendJobsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Empty(t, response.Jobs)
+}
+
+func TestServer_CancelSendJob(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	tests := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"no id", "/api/client/jobs/cancel", http.StatusBadRequest},
+		{"invalid id", "/api/client/jobs/cancel?id=abc", http.StatusBadRequest},
+		{"not found", "/api/client/jobs/cancel?id=42", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			server.CancelSendJob(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+		})
+	}
+}
+
+func TestServer_SendJobDatagram_ClientNotFound(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	err := server.sendJobDatagram(api.SendDatagramRequest{Id: 999, Message: "hi", Format: "text"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
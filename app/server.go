@@ -2,20 +2,34 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"net"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/auraspeak/client"
 	"github.com/auraspeak/client/pkg/command"
 	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/certs"
 	"github.com/auraspeak/debug-ui/internal/communication"
+	"github.com/auraspeak/debug-ui/internal/diag"
+	"github.com/auraspeak/debug-ui/internal/dissect"
+	"github.com/auraspeak/debug-ui/internal/fuzz"
+	"github.com/auraspeak/debug-ui/internal/loglevel"
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	"github.com/auraspeak/debug-ui/internal/middleware"
+	"github.com/auraspeak/debug-ui/internal/payload"
 	"github.com/auraspeak/debug-ui/internal/services"
 	"github.com/auraspeak/debug-ui/internal/util"
 	"github.com/auraspeak/debug-ui/internal/ws"
@@ -31,89 +45,268 @@ import (
 
 type Config struct {
 	UDPPort int
+	// Directory the payload templates are stored in
+	TemplateDir string
+	// NameSeed makes the generated client names reproducible, 0 picks a random seed
+	NameSeed int64
+	// FullNames generates "First Last" client names
+	FullNames bool
+	// AdminTokens and ViewerTokens are the bearer tokens for /api/* and /ws.
+	// Without any token authentication is off.
+	AdminTokens  []string
+	ViewerTokens []string
+	// AllowedOrigins for CORS, empty allows every origin
+	AllowedOrigins []string
+	// TLSCertFile and TLSKeyFile serve HTTPS with a user-supplied certificate
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSSelfSigned serves HTTPS with a self-signed certificate, generated on
+	// first start and kept in TLSDir
+	TLSSelfSigned bool
+	TLSDir        string
+	// TLSHosts are added to the names of the self-signed certificate
+	TLSHosts []string
+	// FuzzDir holds the corpus and crash directories of fuzz runs, which
+	// requests name relative to it
+	FuzzDir string
 }
 
 type Server struct {
-	Port       int
-	mu         sync.Mutex
+	Port int
+	// mu records its hold times for the diagnostics
+	mu         diag.Mutex
 	config     Config
 	httpServer *http.Server
+	// startedAt is set when Serve starts
+	startedAt  time.Time
 	ctx        context.Context
 	cancel     context.CancelFunc
 	shutdownWg sync.WaitGroup
+	// goroutines counts the goroutines of shutdownWg by component
+	goroutines diag.Goroutines
+	// closing is set when Shutdown starts, new API calls are refused
+	closing atomic.Bool
 
 	cfg *serverConfig.Config
 
-	// WebSocket Hub
+	// WebSocket Hub, it outlives ctx and is closed last by Shutdown
 	wsHub *ws.WebSocketHub
 
+	// In-process event bus, everything the UI or the log store needs to
+	// know about is published to it
+	bus *communication.Bus
+	// Messages published to the bus, by topic
+	events communication.Counter
+
+	// logger is the server's own, so servers in the same process keep their
+	// log stores and levels apart. While the server runs it also receives
+	// the entries the libraries log to the standard logger.
+	logger *log.Logger
+	// Recent log entries, published by logHook while the server runs
+	logs    *logstore.Store
+	logHook *logstore.Hook
+	// Runtime log levels of logger, globally and per caller or client
+	logLevels *loglevel.Controller
+
 	// UDP Parts
 	udpServer *server.Server
-	// udpClientWrapper
-	udpClients      map[string]api.UDPClient
+	// The debug clients by ID and name, together with their scopes. The
+	// scope of a client owns its command listener, packet handler, send jobs
+	// and Run loop. The registry has its own locks, s.mu doesn't guard it.
+	clients         *services.ClientRegistry
 	clientMu        sync.Mutex
 	udpClientAction api.UDPClientAction
-	// Communicate from UDP Server and Clients to WebSocket Hub
-	messageCh chan []communication.InternalMessage
-	// Client command channels mapped by client ID
-	clientCommandChs map[int]chan command.InternalCommand
+
+	// Generates the names of new clients
+	names *util.NameGenerator
+
+	// Payload templates
+	templates *payload.Store
+	// Periodic send jobs of the clients
+	sendJobs *services.SendJobService
 
 	// Traces
 	traces  []tracer.TraceEvent
 	traceMu sync.Mutex
+
+	// Fuzzing, only one run at a time. fuzzActive is set from the start
+	// until the run's goroutine ended.
+	fuzzer     *fuzz.Fuzzer
+	fuzzCancel context.CancelFunc
+	fuzzActive bool
+	fuzzMu     sync.Mutex
 }
 
+// clientNamePattern limits caller-supplied client names
+var clientNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-][A-Za-z0-9 _.-]{0,63}$`)
+
+// defaultTemplateDir is where payload templates are stored, relative to the working directory
+const defaultTemplateDir = "templates"
+
+// defaultTLSDir is where the self-signed certificate is kept, relative to the working directory
+const defaultTLSDir = "certs"
+
+// defaultFuzzDir is the base of the fuzz corpus and crash directories, relative to the working directory
+const defaultFuzzDir = "fuzz"
+
 func NewServer(port int, udpPort int, cfg serverConfig.Config) *Server {
+	return NewServerWithConfig(port, Config{UDPPort: udpPort}, cfg)
+}
+
+// NewServerWithConfig creates a server with the given config. Empty fields
+// take their defaults.
+func NewServerWithConfig(port int, config Config, cfg serverConfig.Config) *Server {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Server{
-		Port:             port,
-		mu:               sync.Mutex{},
-		ctx:              ctx,
-		cancel:           cancel,
-		wsHub:            ws.NewHub(ctx),
-		config:           Config{UDPPort: udpPort},
-		udpClients:       make(map[string]api.UDPClient),
-		clientCommandChs: make(map[int]chan command.InternalCommand),
-		traceMu:          sync.Mutex{},
-		cfg:              &cfg,
+	if config.TemplateDir == "" {
+		config.TemplateDir = defaultTemplateDir
 	}
+	if config.TLSDir == "" {
+		config.TLSDir = defaultTLSDir
+	}
+	if config.FuzzDir == "" {
+		config.FuzzDir = defaultFuzzDir
+	}
+	s := &Server{
+		Port:      port,
+		ctx:       ctx,
+		cancel:    cancel,
+		wsHub:     ws.NewHub(context.Background()),
+		config:    config,
+		templates: payload.NewStore(config.TemplateDir),
+		names:     util.NewNameGenerator(config.NameSeed, config.FullNames),
+		clients:   services.NewClientRegistry(),
+		bus:       communication.NewBus(),
+		traceMu:   sync.Mutex{},
+		cfg:       &cfg,
+	}
+	s.sendJobs = services.NewSendJobService(ctx, s.sendJobDatagram, s.publishSendJob)
+	s.logger = loglevel.NewLogger()
+	s.logger.AddHook(middleware.RequestIDHook{})
+	s.wsHub.SetLogger(s.logger)
+	s.logs = logstore.New(logstore.DefaultCapacity)
+	s.logLevels = loglevel.New(s.logger)
+	s.logHook = logstore.NewHook(s.publishLog, s.logLevels.Enabled)
+	s.subscribe()
+	s.wsHub.SetCommandHandler(s.handleWSCommand)
+	return s
+}
+
+// tlsConfig returns the TLS config of the web server, nil for plain HTTP
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+	switch {
+	case s.config.TLSCertFile != "" || s.config.TLSKeyFile != "":
+		cert, err = certs.Load(s.config.TLSCertFile, s.config.TLSKeyFile)
+	case s.config.TLSSelfSigned:
+		cert, err = certs.LoadOrCreate(s.config.TLSDir, s.config.TLSHosts...)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := certs.Fingerprint(cert)
+	fmt.Printf("TLS certificate SHA-256 fingerprint: %s\n", fingerprint)
+	s.logger.WithFields(log.Fields{
+		"caller":      "web",
+		"fingerprint": fingerprint,
+		"notAfter":    cert.Leaf.NotAfter,
+	}).Info("Serving HTTPS")
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// routes returns the mux with all routes the server serves
+func (s *Server) routes() *api.Router {
+	return api.RegisterRoutes(s)
+}
+
+// Handler returns the HTTP handler with all routes, CORS and authentication
+func (s *Server) Handler() http.Handler {
+	routes := s.refuseWhileClosing(s.routes())
+	auth := middleware.Auth{AdminTokens: s.config.AdminTokens, ViewerTokens: s.config.ViewerTokens, Logger: s.logger}
+	return api.Protect(routes, middleware.Cors{AllowedOrigins: s.config.AllowedOrigins, Logger: s.logger}, auth)
 }
 
 func (s *Server) Run() error {
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", s.Port))
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve runs the web server on l until Shutdown, like Run. With a listener
+// on port 0 the server gets a free port, see Addr.
+func (s *Server) Serve(l net.Listener) error {
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		l.Close()
+		return err
+	}
+	if len(s.config.AdminTokens) == 0 && len(s.config.ViewerTokens) == 0 {
+		s.logger.WithField("caller", "web").Warn("No API tokens configured, authentication is off")
+	}
+
+	s.mu.Lock()
+	s.startedAt = time.Now()
 	s.httpServer = &http.Server{
-		Addr: fmt.Sprintf(":%d", s.Port),
-		Handler: api.RegisterRoutes(
-			func(w http.ResponseWriter, r *http.Request) {
-				// WebSocket handler needs special handling
-				websocket.Handler(s.HandleWS).ServeHTTP(w, r)
-			},
-			s.StartUDPServer,
-			s.StopUDPServer,
-			s.GetUDPServerState,
-			s.StartUDPClient,
-			s.StopUDPClient,
-			s.SendDatagram,
-			s.GetUDPClientStateByName,
-			s.GetUDPClientStateById,
-			s.GetAllUDPClients,
-			s.GetTraces,
-			s.GetAllUDPClientPaginated,
-			s.GetClientMap,
-		),
-	}
-
-	s.shutdownWg.Go(func() {
-		s.handleInternal()
-	})
+		Addr:      l.Addr().String(),
+		Handler:   s.Handler(),
+		TLSConfig: tlsConfig,
+	}
+	httpServer := s.httpServer
+	s.mu.Unlock()
 
-	s.shutdownWg.Go(func() {
-		s.handleTrace()
+	// A single hook stores the log entries and forwards them to the websockets
+	s.logLevels.Install()
+	s.logger.AddHook(s.logHook)
+
+	s.handleInternal()
+	s.handleTrace()
+
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	fmt.Printf("Starting server on %s://%s\n", scheme, displayAddr(l.Addr()))
+	// Signal the restart once to all clients
+	s.bus.Publish(communication.InternalMessage{
+		Caller: communication.CallerWebServer,
+		Topic:  communication.TopicServerStarted,
 	})
+	if tlsConfig != nil {
+		// The certificate is already in the TLS config
+		return httpServer.ServeTLS(l, "", "")
+	}
+	return httpServer.Serve(l)
+}
+
+// Addr returns the address the web server listens on, empty before Serve
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.httpServer == nil {
+		return ""
+	}
+	return s.httpServer.Addr
+}
 
-	fmt.Printf("Starting server on http://localhost:%d\n", s.Port)
-	// Broadcast restart signal once to all clients
-	s.wsHub.Broadcast([]byte("rp"))
-	return s.httpServer.ListenAndServe()
+// displayAddr shows unspecified listen addresses as localhost
+func displayAddr(addr net.Addr) string {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok || !tcp.IP.IsUnspecified() {
+		return addr.String()
+	}
+	return fmt.Sprintf("localhost:%d", tcp.Port)
+}
+
+// ServeWS upgrades the request to the WebSocket
+func (s *Server) ServeWS(w http.ResponseWriter, r *http.Request) {
+	websocket.Handler(s.HandleWS).ServeHTTP(w, r)
 }
 
 func (s *Server) HandleWS(ws *websocket.Conn) {
@@ -123,38 +316,31 @@ func (s *Server) HandleWS(ws *websocket.Conn) {
 }
 
 // handleClientCommands listens for commands from a specific UDP client
-func (s *Server) handleClientCommands(clientID int, cmdCh chan command.InternalCommand) {
-	s.shutdownWg.Go(func() {
+// until its scope ends
+func (s *Server) handleClientCommands(scope *services.Scope, clientID int, cmdCh chan command.InternalCommand) {
+	scope.Go(s.goroutines.Wrap("handleClientCommands", func() {
 		for {
 			select {
 			case cmd := <-cmdCh:
 				switch cmd {
 				case command.CmdUpdateClientState:
-					s.mu.Lock()
-					// Find udpClient by ID and update running field
-					for name, uc := range s.udpClients {
-						if uc.ID == clientID {
-							// Update running field from ClientState
-							running := uc.Client.ClientState.Running
-							uc.Running = running == 1
-							// Update the map entry
-							s.udpClients[name] = uc
-
-							// Broadcast to all WebSocket Clients that the UDP Client State has changed
-							if s.wsHub != nil {
-								s.wsHub.Broadcast([]byte("usu" + strconv.Itoa(clientID)))
-								s.wsHub.Broadcast([]byte("map"))
-							}
-							break
-						}
+					// Update running field from ClientState
+					_, ok := s.clients.Update(clientID, func(uc *api.UDPClient) {
+						uc.Running = uc.Client.ClientState.Running == 1
+					})
+					if ok {
+						s.bus.Publish(communication.InternalMessage{
+							Caller:   communication.CallerUDPClient,
+							Topic:    communication.TopicClientState,
+							ClientID: clientID,
+						})
 					}
-					s.mu.Unlock()
 				}
-			case <-s.ctx.Done():
+			case <-scope.Context().Done():
 				return
 			}
 		}
-	})
+	}))
 }
 
 // Handles all internal communications to the web server
@@ -163,7 +349,7 @@ func (s *Server) handleClientCommands(clientID int, cmdCh chan command.InternalC
 // usu: tells the clients, that a udp client state has been updated
 // cnu: tells the web server, that a new udp client has been started
 func (s *Server) handleInternal() {
-	s.shutdownWg.Go(func() {
+	s.goroutines.Go(&s.shutdownWg, "handleInternal", func() {
 		// Brodcast through on UDP Server State Changes
 		for {
 			// Wait until UDP server is initialized
@@ -186,11 +372,10 @@ func (s *Server) handleInternal() {
 			case cmd := <-udpServer.OutCommandCh:
 				switch cmd {
 				case serverCommand.CmdUpdateServerState:
-					// Broadcast to all WebSocket Clients that the UDP Server State has changed
-					if s.wsHub != nil {
-						s.wsHub.Broadcast([]byte("uss"))
-						s.wsHub.Broadcast([]byte("map"))
-					}
+					s.bus.Publish(communication.InternalMessage{
+						Caller: communication.CallerUDPServer,
+						Topic:  communication.TopicServerState,
+					})
 				}
 			case <-s.ctx.Done():
 				return
@@ -200,7 +385,7 @@ func (s *Server) handleInternal() {
 }
 
 func (s *Server) handleTrace() {
-	s.shutdownWg.Go(func() {
+	s.goroutines.Go(&s.shutdownWg, "handleTrace", func() {
 		for {
 			s.mu.Lock()
 			udpServer := s.udpServer
@@ -221,91 +406,65 @@ func (s *Server) handleTrace() {
 			case trace := <-s.udpServer.TraceCh:
 				s.traceMu.Lock()
 				s.traces = append(s.traces, trace)
-				log.WithFields(log.Fields{
+				s.logger.WithFields(log.Fields{
 					"caller": "web",
 					"cid":    trace.ClientID,
 				}).Debugf("Received trace: %+v", trace)
 				s.traceMu.Unlock()
+				communication.Traces.Publish(s.bus, communication.InternalMessage{
+					Caller:   communication.CallerUDPServer,
+					ClientID: trace.ClientID,
+				}, trace)
 			}
 		}
 	})
 }
 
-func (s *Server) Shutdown(timeout time.Duration) error {
-	fmt.Println("Shutting down server...")
-
-	// Signals all go routines to cancel
-	s.cancel()
-
-	// Cancel the WebSocketHub
-	if s.wsHub != nil {
-		s.wsHub.Cancel()
-	}
-
-	// Create a context with the given timeout for all shutdown operations
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+// Helper functions for UDP client management
 
-	// Shutdown HTTP server
-	httpDone := make(chan error, 1)
-	go func() {
-		if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
-			httpDone <- fmt.Errorf("error shutting down HTTP server: %w", err)
-		} else {
-			httpDone <- nil
-		}
-	}()
-
-	// Wait for all goroutines to finish or timeout
-	wgDone := make(chan struct{})
-	go func() {
-		s.shutdownWg.Wait()
-		close(wgDone)
-	}()
-
-	// Wait for either all goroutines or context timeout
-	select {
-	case <-wgDone:
-		fmt.Println("All connections closed")
-	case <-shutdownCtx.Done():
-		fmt.Println("Warning: Shutdown timeout reached, some connections may not have closed gracefully")
-	}
-
-	// Wait for HTTP server shutdown result with timeout
-	select {
-	case err := <-httpDone:
+// genUDPClient creates and registers a new UDP client with the given
+// labels. Without a name one is generated, a given name must not be in use.
+func (s *Server) genUDPClient(ctx context.Context, port int, name string, group string, tags []string) (api.UDPClient, error) {
+	generate := name == ""
+	for {
+		if generate {
+			name = s.names.Next(s.clients.HasName)
+		} else if s.clients.HasName(name) {
+			return api.UDPClient{}, services.ErrClientNameTaken
+		}
+		id := services.GetNextID()
+		udpClient := api.UDPClient{
+			ID:        id,
+			Client:    client.NewDebugClient("localhost", port, id),
+			Name:      name,
+			Datagrams: []api.Datagram{},
+			Running:   false,
+			Group:     group,
+			Tags:      tags,
+			CreatedAt: activityTime(),
+		}
+		scope := services.NewScope(s.ctx)
+		err := s.clients.Add(udpClient, scope)
+		if errors.Is(err, services.ErrClientNameTaken) && generate {
+			// Another client got the generated name first
+			scope.Cancel()
+			continue
+		}
 		if err != nil {
-			return err
+			scope.Cancel()
+			return api.UDPClient{}, err
 		}
-	case <-shutdownCtx.Done():
-		fmt.Println("HTTP server shutdown timeout")
+		// Start listening to the client commands
+		s.handleClientCommands(scope, id, udpClient.Client.OutCommandCh)
+		s.logger.WithContext(ctx).WithField("cid", id).Infof("UDP client started: %s with id %d", name, id)
+		return udpClient, nil
 	}
-
-	fmt.Println("Server shutdown complete")
-	return nil
 }
 
-// Helper functions for UDP client management
-
-// genUDPClient creates a new UDP client and returns its name
-func (s *Server) genUDPClient(port int) string {
-	name := util.GetFirstName()
-	id := services.GetNextID()
-	client := client.NewDebugClient("localhost", port, id)
-	s.mu.Lock()
-	s.udpClients[name] = api.UDPClient{
-		ID:        id,
-		Client:    client,
-		Name:      name,
-		Datagrams: []api.Datagram{},
-		Running:   false,
-	}
-	// Register client command channel and start listening
-	s.clientCommandChs[id] = client.OutCommandCh
-	s.handleClientCommands(id, client.OutCommandCh)
-	s.mu.Unlock()
-	log.Infof("UDP client started: %s with id %d", name, id)
-	return name
+// activityTime returns the current wall clock time without monotonic reading,
+// so client times compare the same way as the times decoded from list cursors
+func activityTime() time.Time {
+	return time.Now().Round(0)
 }
 
 // convertMessageToBytes converts a message string to []byte based on format
@@ -319,128 +478,317 @@ func convertMessageToBytes(message string, format string) ([]byte, error) {
 	return []byte(message), nil
 }
 
-// handleAllClient handles all incoming packets from UDP clients
-func (s *Server) handleAllClient(name string, packet []byte) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// encodeDatagram builds the bytes SendDatagram puts on the wire together with
+// the packet type written into the header. By default the payload is wrapped in
+// a PacketTypeDebugAny header, req.Header overrides single header fields and
+// req.Raw skips the header completely.
+func encodeDatagram(req api.SendDatagramRequest, payload []byte) ([]byte, protocol.PacketType, error) {
+	var packetType protocol.PacketType
+	if req.Raw {
+		return payload, packetType, nil
+	}
+	packet := &protocol.Packet{
+		PacketHeader: protocol.Header{
+			Magic:      protocol.Magic,
+			Version:    protocol.Version,
+			PacketType: protocol.PacketTypeDebugAny,
+			Length:     uint32(len(payload)),
+		},
+		Payload: payload,
+	}
+	if o := req.Header; o != nil {
+		header := &packet.PacketHeader
+		if err := overrideField(&header.PacketType, o.PacketType, "packetType"); err != nil {
+			return nil, packetType, err
+		}
+		if err := overrideField(&header.Magic, o.Magic, "magic"); err != nil {
+			return nil, packetType, err
+		}
+		if err := overrideField(&header.Version, o.Version, "version"); err != nil {
+			return nil, packetType, err
+		}
+		if err := overrideField(&header.Length, o.Length, "length"); err != nil {
+			return nil, packetType, err
+		}
+	}
+	return packet.Encode(), packet.PacketHeader.PacketType, nil
+}
 
-	udpClient, ok := s.udpClients[name]
-	if !ok {
-		log.Errorf("UDP client not found: %s", name)
-		return fmt.Errorf("UDP client not found: %s", name)
+// newDatagram creates the datagram record for a client and dissects its
+// message. Raw datagrams have no known packet type and stay undissected.
+func (s *Server) newDatagram(ctx context.Context, direction api.DatagramDirection, packetType protocol.PacketType, raw bool, message []byte) api.Datagram {
+	d := api.Datagram{
+		Direction:  direction,
+		Message:    message,
+		PacketType: packetType,
+		Raw:        raw,
 	}
+	if raw {
+		return d
+	}
+	fields, err := dissect.Default.Dissect(packetType, message)
+	if err != nil {
+		s.logger.WithContext(ctx).WithField("caller", "web").WithError(err).Debug("Can't dissect datagram")
+	}
+	d.Fields = fields
+	return d
+}
 
-	newDatagram := api.Datagram{
-		Direction: api.ServerToClient,
-		Message:   packet,
+// recordDatagram numbers the datagram and appends it to the client's history
+func recordDatagram(uc *api.UDPClient, d api.Datagram) api.Datagram {
+	uc.LastSeq++
+	d.Seq = uc.LastSeq
+	uc.Datagrams = append(uc.Datagrams, d)
+	uc.LastActivity = activityTime()
+	return d
+}
+
+// overrideField sets a header field to v if v is given. It works for every
+// unsigned header field width and rejects values that would be truncated.
+func overrideField[T ~uint8 | ~uint16 | ~uint32 | ~uint64](field *T, v *uint64, name string) error {
+	if v == nil {
+		return nil
 	}
-	udpClient.Datagrams = append(udpClient.Datagrams, newDatagram)
-	s.udpClients[name] = udpClient
-	if s.wsHub != nil {
-		s.wsHub.Broadcast([]byte("usu" + strconv.Itoa(udpClient.ID)))
-		s.wsHub.Broadcast([]byte("map"))
-		// pkt,fromId,toId,dir: server->client = from=0, to=clientId, dir=2
-		s.wsHub.Broadcast([]byte("pkt,0," + strconv.Itoa(udpClient.ID) + ",2"))
+	if uint64(T(*v)) != *v {
+		return fmt.Errorf("%s %d does not fit into the header field", name, *v)
 	}
+	*field = T(*v)
+	return nil
+}
+
+// handleAllClient handles all incoming packets from UDP clients. Only the
+// receiving client is locked while the datagram is recorded.
+func (s *Server) handleAllClient(id int, packet *protocol.Packet) error {
+	var datagram api.Datagram
+	udpClient, ok := s.clients.Update(id, func(uc *api.UDPClient) {
+		datagram = recordDatagram(uc, s.newDatagram(s.ctx, api.ServerToClient, packet.PacketHeader.PacketType, false, packet.Payload))
+	})
+	if !ok {
+		s.logger.WithField("cid", id).Errorf("UDP client not found: %d", id)
+		return fmt.Errorf("UDP client not found: %d", id)
+	}
+
+	// From the server to the client
+	communication.Packets.Publish(s.bus, communication.InternalMessage{
+		Caller:   communication.CallerUDPClient,
+		ClientID: udpClient.ID,
+	}, packetEvent(0, udpClient.ID, datagram))
 
 	return nil
 }
 
+// clientPacketHandler returns the packet handler of a client. Packets
+// arriving after the client stopped are dropped.
+func (s *Server) clientPacketHandler(id int, scope *services.Scope) func(*protocol.Packet) error {
+	return func(packet *protocol.Packet) error {
+		var err error
+		scope.Do(func() {
+			err = s.handleAllClient(id, packet)
+		})
+		return err
+	}
+}
+
+// onEveryPacketType registers h for all packet types, not only
+// PacketTypeDebugAny, so packets of every type are recorded and dissected
+func onEveryPacketType[H any](onPacket func(protocol.PacketType, H), h H) {
+	for packetType := range math.MaxUint8 + 1 {
+		onPacket(protocol.PacketType(packetType), h)
+	}
+}
+
 // UDP Client Handler Methods
 
 func (s *Server) StartUDPClient(w http.ResponseWriter, r *http.Request) {
-	s.mu.Lock()
-	name := s.genUDPClient(s.config.UDPPort)
-	udpClient := s.udpClients[name]
-	udpClient.Client.OnPacket(protocol.PacketTypeDebugAny, func(packet *protocol.Packet) error {
-		return s.handleAllClient(name, packet.Payload)
-	})
-	s.mu.Unlock()
-	s.shutdownWg.Go(func() {
-		udpClient.Client.Run()
-	})
+	// The body is optional, without it the client gets a generated name
+	var req api.StartUDPClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name != "" && !clientNamePattern.MatchString(name) {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidClientName,
+			Message:   "Invalid client name",
+			Details:   "names have 1 to 64 letters, digits, spaces, '_', '.' or '-'",
+		}
+		apiError.Send(w)
+		return
+	}
+
+	udpClient, err := s.startUDPClient(r.Context(), name, strings.TrimSpace(req.Group), normalizeTags(req.Tags))
+	if errors.Is(err, services.ErrClientNameTaken) {
+		apiError := api.ApiError{
+			Code:      http.StatusConflict,
+			ErrorCode: api.ErrClientNameTaken,
+			Message:   "Client name is already taken",
+		}
+		apiError.Send(w)
+		return
+	}
+	if err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusInternalServerError,
+			ErrorCode: api.ErrInternal,
+			Message:   "Can't start UDP client",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
 	udpClientResponse := api.UDPClientResponse{
-		Name: name,
+		Name: udpClient.Name,
 		Id:   udpClient.ID,
 	}
+	udpClientResponse.Send(w)
+}
 
-	if s.wsHub != nil {
-		s.wsHub.Broadcast([]byte("cnu"))
-		s.wsHub.Broadcast([]byte("map"))
+// SetNameGenerator reseeds the generator for the names of new clients
+func (s *Server) SetNameGenerator(w http.ResponseWriter, r *http.Request) {
+	var req api.NameGeneratorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
 	}
-	udpClientResponse.Send(w)
+	s.names.Reset(req.Seed, req.FullNames)
+	apiSuccess := api.ApiSuccess{
+		Message: "Name generator reset",
+	}
+	apiSuccess.Send(w)
+}
+
+// startUDPClient creates a debug client with the given name and labels and runs it
+func (s *Server) startUDPClient(ctx context.Context, name string, group string, tags []string) (api.UDPClient, error) {
+	udpClient, err := s.genUDPClient(ctx, s.config.UDPPort, name, group, tags)
+	if err != nil {
+		return api.UDPClient{}, err
+	}
+	scope := s.clients.Scope(udpClient.ID)
+	onEveryPacketType(udpClient.Client.OnPacket, s.clientPacketHandler(udpClient.ID, scope))
+	scope.Go(s.goroutines.Wrap("udpClient", func() {
+		udpClient.Client.Run()
+	}))
+
+	s.bus.Publish(communication.InternalMessage{
+		Caller:   communication.CallerWebServer,
+		Topic:    communication.TopicClientStarted,
+		ClientID: udpClient.ID,
+	})
+	return udpClient, nil
 }
 
 func (s *Server) StopUDPClient(w http.ResponseWriter, r *http.Request) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	name := r.URL.Query().Get("name")
 	if name == "" {
 		apiError := api.ApiError{
-			Code:    http.StatusBadRequest,
-			Message: "Name is required",
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrMissingParameter,
+			Message:   "Name is required",
 		}
 		apiError.Send(w)
 		return
 	}
-	udpClient, ok := s.udpClients[name]
+	udpClient, ok := s.clients.GetByName(name)
 	if !ok {
 		apiError := api.ApiError{
-			Code:    http.StatusNotFound,
-			Message: "UDP client not found",
+			Code:      http.StatusNotFound,
+			ErrorCode: api.ErrClientNotFound,
+			Message:   "UDP client not found",
 		}
 		apiError.Send(w)
 		return
 	}
-	udpClient.Client.Stop()
-	// Remove client command channel from map
-	delete(s.clientCommandChs, udpClient.ID)
-	if s.wsHub != nil {
-		s.wsHub.Broadcast([]byte("map"))
-	}
+	s.waitClients(s.stopUDPClient(udpClient))
+	communication.ClientsStopped.Publish(s.bus, communication.InternalMessage{
+		Caller:   communication.CallerWebServer,
+		ClientID: udpClient.ID,
+	}, []int{udpClient.ID})
 	apiSuccess := api.ApiSuccess{
 		Message: "UDP client stopped",
 	}
 	apiSuccess.Send(w)
 }
 
+// stopUDPClient stops a client together with everything bound to it and
+// returns its scope to wait for
+func (s *Server) stopUDPClient(udpClient api.UDPClient) *services.Scope {
+	if udpClient.Client != nil {
+		udpClient.Client.Stop()
+	}
+	// Periodic send jobs end with their client
+	s.sendJobs.CancelClient(udpClient.ID)
+	scope := s.clients.Scope(udpClient.ID)
+	if scope != nil {
+		scope.Cancel()
+	}
+	return scope
+}
+
+// clientStopTimeout limits how long stopping clients waits for their goroutines
+const clientStopTimeout = 5 * time.Second
+
+// waitClients waits until the goroutines of the stopped clients returned
+// and logs the clients that didn't stop within clientStopTimeout
+func (s *Server) waitClients(scopes ...*services.Scope) {
+	ctx, cancel := context.WithTimeout(context.Background(), clientStopTimeout)
+	defer cancel()
+	for _, scope := range scopes {
+		if scope == nil {
+			continue
+		}
+		if err := scope.Wait(ctx); err != nil {
+			s.logger.WithField("caller", "web").WithError(err).Warn("Client goroutines did not stop")
+			return
+		}
+	}
+}
+
 func (s *Server) GetUDPClientStateByName(w http.ResponseWriter, r *http.Request) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	name := r.URL.Query().Get("name")
 	if name == "" {
 		apiError := api.ApiError{
-			Code:    http.StatusBadRequest,
-			Message: "Name is required",
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrMissingParameter,
+			Message:   "Name is required",
 		}
 		apiError.Send(w)
 		return
 	}
-	udpClient, ok := s.udpClients[name]
+	udpClient, ok := s.clients.GetByName(name)
 	if !ok {
 		apiError := api.ApiError{
-			Code:    http.StatusNotFound,
-			Message: "UDP client not found",
+			Code:      http.StatusNotFound,
+			ErrorCode: api.ErrClientNotFound,
+			Message:   "UDP client not found",
 		}
 		apiError.Send(w)
 		return
 	}
-	udpClientStateResponse := api.UDPClientStateResponse{
-		Id:        udpClient.ID,
-		Running:   udpClient.Running,
-		Datagrams: udpClient.Datagrams,
-	}
+	udpClientStateResponse := newUDPClientStateResponse(udpClient, true)
 	udpClientStateResponse.Send(w)
 }
 
 func (s *Server) GetUDPClientStateById(w http.ResponseWriter, r *http.Request) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	id := r.URL.Query().Get("id")
 	if id == "" {
 		apiError := api.ApiError{
-			Code:    http.StatusBadRequest,
-			Message: "ID is required",
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrMissingParameter,
+			Message:   "ID is required",
 		}
 		apiError.Send(w)
 		return
@@ -448,49 +796,41 @@ func (s *Server) GetUDPClientStateById(w http.ResponseWriter, r *http.Request) {
 	idInt, err := strconv.Atoi(id)
 	if err != nil {
 		apiError := api.ApiError{
-			Code:    http.StatusBadRequest,
-			Message: "ID is invalid",
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidParameter,
+			Message:   "ID is invalid",
 		}
 		apiError.Send(w)
 		return
 	}
-	for _, udpClient := range s.udpClients {
-		if udpClient.ID == idInt {
-			udpClientStateResponse := api.UDPClientStateResponse{
-				Id:        udpClient.ID,
-				Running:   udpClient.Running,
-				Datagrams: udpClient.Datagrams,
-			}
-			udpClientStateResponse.Send(w)
-			return
-		}
+	if udpClient, ok := s.clients.Get(idInt); ok {
+		// datagrams=false leaves out the history, GET /api/client/datagrams fetches it incrementally
+		udpClientStateResponse := newUDPClientStateResponse(udpClient, r.URL.Query().Get("datagrams") != "false")
+		udpClientStateResponse.Send(w)
+		return
 	}
 	apiError := api.ApiError{
-		Code:    http.StatusNotFound,
-		Message: "UDP client not found",
+		Code:      http.StatusNotFound,
+		ErrorCode: api.ErrClientNotFound,
+		Message:   "UDP client not found",
 	}
 	apiError.Send(w)
 }
 
 func (s *Server) GetAllUDPClients(w http.ResponseWriter, r *http.Request) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	allUDPClientResponse := api.AllUDPClientResponse{
 		UDPClients: []api.UDPClientResponse{},
 	}
-	for name, udpClient := range s.udpClients {
+	for _, udpClient := range s.clients.Snapshot() {
 		allUDPClientResponse.UDPClients = append(allUDPClientResponse.UDPClients, api.UDPClientResponse{
 			Id:   udpClient.ID,
-			Name: name,
+			Name: udpClient.Name,
 		})
 	}
 	allUDPClientResponse.Send(w)
 }
 
 func (s *Server) GetAllUDPClientPaginated(w http.ResponseWriter, r *http.Request) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	// Parse page parameter with default value 1
 	pageStr := r.URL.Query().Get("page")
 	pageInt := 1
@@ -499,8 +839,9 @@ func (s *Server) GetAllUDPClientPaginated(w http.ResponseWriter, r *http.Request
 		pageInt, err = strconv.Atoi(pageStr)
 		if err != nil || pageInt < 1 {
 			apiError := api.ApiError{
-				Code:    http.StatusBadRequest,
-				Message: "Page must be a positive integer",
+				Code:      http.StatusBadRequest,
+				ErrorCode: api.ErrInvalidParameter,
+				Message:   "Page must be a positive integer",
 			}
 			apiError.Send(w)
 			return
@@ -515,43 +856,53 @@ func (s *Server) GetAllUDPClientPaginated(w http.ResponseWriter, r *http.Request
 		pageSizeInt, err = strconv.Atoi(pageSizeStr)
 		if err != nil || pageSizeInt < 1 {
 			apiError := api.ApiError{
-				Code:    http.StatusBadRequest,
-				Message: "Page size must be a positive integer",
+				Code:      http.StatusBadRequest,
+				ErrorCode: api.ErrInvalidParameter,
+				Message:   "Page size must be a positive integer",
 			}
 			apiError.Send(w)
 			return
 		}
 	}
 
-	// Parse search query parameter
-	searchQuery := r.URL.Query().Get("q")
+	query, err := parseClientQuery(r.URL.Query())
+	if err != nil {
+		invalidClientQuery(w, err)
+		return
+	}
 
-	// Filter and collect all matching UDP clients (only basic info: id and name)
-	allItems := []api.UDPClientListItem{}
-	for name, udpClient := range s.udpClients {
-		// Apply search filter if provided
-		if searchQuery != "" {
-			// Case-insensitive search in client name
-			if !strings.Contains(strings.ToLower(name), strings.ToLower(searchQuery)) {
-				continue
-			}
+	// Filter and sort the matching UDP clients, the order is stable between requests
+	now := time.Now()
+	matching := s.clients.Filter(func(udpClient api.UDPClient) bool {
+		return query.match(udpClient, now)
+	})
+	cursorStart := query.sort(matching)
+
+	// Page by cursor, which stays consistent while clients are added
+	if query.Cursor != nil {
+		endIndex := min(cursorStart+pageSizeInt, len(matching))
+		paginatedResponse := api.UDPClientPaginatedRespone{
+			Items:    listItems(matching[cursorStart:endIndex]),
+			PageSize: pageSizeInt,
+			Total:    len(matching),
 		}
-
-		allItems = append(allItems, api.UDPClientListItem{
-			Id:   udpClient.ID,
-			Name: name,
-		})
+		if endIndex < len(matching) {
+			paginatedResponse.NextCursor = query.cursorAfter(matching[endIndex-1])
+		}
+		paginatedResponse.Send(w)
+		return
 	}
 
 	// Calculate pagination
-	total := len(allItems)
+	total := len(matching)
 	totalPages := int(math.Ceil(float64(total) / float64(pageSizeInt)))
 
 	// Validate page number
 	if pageInt > totalPages && totalPages > 0 {
 		apiError := api.ApiError{
-			Code:    http.StatusBadRequest,
-			Message: "Page is out of range",
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidParameter,
+			Message:   "Page is out of range",
 		}
 		apiError.Send(w)
 		return
@@ -567,7 +918,7 @@ func (s *Server) GetAllUDPClientPaginated(w http.ResponseWriter, r *http.Request
 	// Extract items for current page
 	var items []api.UDPClientListItem
 	if startIndex < total {
-		items = allItems[startIndex:endIndex]
+		items = listItems(matching[startIndex:endIndex])
 	} else {
 		items = []api.UDPClientListItem{}
 	}
@@ -579,16 +930,21 @@ func (s *Server) GetAllUDPClientPaginated(w http.ResponseWriter, r *http.Request
 		PageSize: pageSizeInt,
 		Total:    total,
 	}
+	if endIndex < total {
+		paginatedResponse.NextCursor = query.cursorAfter(matching[endIndex-1])
+	}
 	paginatedResponse.Send(w)
 }
 
 func (s *Server) GetClientMap(w http.ResponseWriter, r *http.Request) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	clients := make([]api.UDPClientListItem, 0, len(s.udpClients))
-	connections := make([]api.ClientMapConnection, 0)
-	for name, uc := range s.udpClients {
-		clients = append(clients, api.UDPClientListItem{Id: uc.ID, Name: name})
+	tags := r.URL.Query()["tag"]
+	matching := s.clients.Filter(func(uc api.UDPClient) bool {
+		return hasTags(uc, tags)
+	})
+	clients := make([]api.UDPClientListItem, 0, len(matching))
+	connections := make([]api.ClientMapConnection, 0, len(matching))
+	for _, uc := range matching {
+		clients = append(clients, newUDPClientListItem(uc))
 		// Star topology: each client is connected to server (0)
 		connections = append(connections, api.ClientMapConnection{FromClientID: uc.ID, ToClientID: 0})
 	}
@@ -601,139 +957,139 @@ func (s *Server) SendDatagram(w http.ResponseWriter, r *http.Request) {
 	var req api.SendDatagramRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		apiError := api.ApiError{
-			Code:    http.StatusBadRequest,
-			Message: "Invalid request body",
-			Details: err.Error(),
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
 		}
 		apiError.Send(w)
 		return
 	}
 
-	// Validate format
-	if req.Format != "hex" && req.Format != "text" {
-		apiError := api.ApiError{
-			Code:    http.StatusBadRequest,
-			Message: "Format must be 'hex' or 'text'",
-		}
+	if apiError := s.sendDatagram(r.Context(), req); apiError != nil {
 		apiError.Send(w)
 		return
 	}
 
-	// Find client by ID and validate (with lock)
-	s.mu.Lock()
-	var clientToSend *client.Client
-	var clientName string
-	var clientRunning bool
-	for name, uc := range s.udpClients {
-		if uc.ID == req.Id {
-			clientToSend = uc.Client
-			clientName = name
-			clientRunning = uc.Running
-			break
+	// Send success response
+	response := api.SendDatagramResponse{
+		Message: "Datagram sent successfully",
+	}
+	response.Send(w)
+}
+
+// sendDatagram sends one datagram from a debug client and records it. It is
+// shared by the send endpoint and the periodic send jobs, ctx is the one of
+// the request for the log entries.
+func (s *Server) sendDatagram(ctx context.Context, req api.SendDatagramRequest) *api.ApiError {
+	// Validate format, templates bring their own
+	if req.Template == "" && req.Format != "hex" && req.Format != "text" {
+		return &api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidPayload,
+			Message:   "Format must be 'hex' or 'text'",
 		}
 	}
-	s.mu.Unlock()
 
-	if clientToSend == nil {
-		apiError := api.ApiError{
-			Code:    http.StatusNotFound,
-			Message: "UDP client not found",
+	// Find client by ID and validate
+	udpClient, ok := s.clients.Get(req.Id)
+	if !ok {
+		return &api.ApiError{
+			Code:      http.StatusNotFound,
+			ErrorCode: api.ErrClientNotFound,
+			Message:   "UDP client not found",
 		}
-		apiError.Send(w)
-		return
 	}
 
 	// Check if client is running
-	if !clientRunning {
-		apiError := api.ApiError{
-			Code:    http.StatusBadRequest,
-			Message: "Client is not running",
+	if !udpClient.Running {
+		return &api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrClientNotRunning,
+			Message:   "Client is not running",
 		}
-		apiError.Send(w)
-		return
 	}
 
-	// Convert message to []byte based on format
-	messageBytes, err := convertMessageToBytes(req.Message, req.Format)
-	if err != nil {
-		apiError := api.ApiError{
-			Code:    http.StatusBadRequest,
-			Message: "Invalid hex string",
-			Details: err.Error(),
+	// Convert message to []byte based on format or render the template
+	var messageBytes []byte
+	var err error
+	if req.Template != "" {
+		messageBytes, err = s.renderTemplate(req.Template, req.Variables, req.Id, udpClient.Name)
+		if errors.Is(err, payload.ErrNotFound) {
+			apiError := templateError(err)
+			return &apiError
+		}
+		if err != nil {
+			return &api.ApiError{
+				Code:      http.StatusBadRequest,
+				ErrorCode: api.ErrInvalidPayload,
+				Message:   "Can't render template",
+				Details:   err.Error(),
+			}
+		}
+	} else {
+		messageBytes, err = convertMessageToBytes(req.Message, req.Format)
+		if err != nil {
+			return &api.ApiError{
+				Code:      http.StatusBadRequest,
+				ErrorCode: api.ErrInvalidPayload,
+				Message:   "Invalid hex string",
+				Details:   err.Error(),
+			}
 		}
-		apiError.Send(w)
-		return
 	}
 
-	packet := &protocol.Packet{
-		PacketHeader: protocol.Header{
-			Magic:      protocol.Magic,
-			Version:    protocol.Version,
-			PacketType: protocol.PacketTypeDebugAny,
-			Length:     uint32(len(messageBytes)),
-		},
-		Payload: messageBytes,
+	wireBytes, packetType, err := encodeDatagram(req, messageBytes)
+	if err != nil {
+		return &api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidPayload,
+			Message:   "Invalid header override",
+			Details:   err.Error(),
+		}
 	}
 
-	// Send message via client (außerhalb des Locks, damit es nicht blockiert)
-	if err := clientToSend.Send(packet.Encode()); err != nil {
-		apiError := api.ApiError{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to send datagram",
-			Details: err.Error(),
+	// Send message via client, without holding any lock
+	if err := udpClient.Client.Send(wireBytes); err != nil {
+		return &api.ApiError{
+			Code:      http.StatusInternalServerError,
+			ErrorCode: api.ErrSendFailed,
+			Message:   "Failed to send datagram",
+			Details:   err.Error(),
 		}
-		apiError.Send(w)
-		return
 	}
 
-	// Lock wieder holen für Map-Update
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	// Find client again (könnte sich geändert haben)
-	udpClient, ok := s.udpClients[clientName]
+	// Store datagram in client's datagrams list, the client may be gone meanwhile
+	var datagram api.Datagram
+	_, ok = s.clients.Update(req.Id, func(uc *api.UDPClient) {
+		datagram = recordDatagram(uc, s.newDatagram(ctx, api.ClientToServer, packetType, req.Raw, messageBytes))
+	})
 	if !ok {
-		apiError := api.ApiError{
-			Code:    http.StatusNotFound,
-			Message: "UDP client not found",
+		return &api.ApiError{
+			Code:      http.StatusNotFound,
+			ErrorCode: api.ErrClientNotFound,
+			Message:   "UDP client not found",
 		}
-		apiError.Send(w)
-		return
 	}
 
-	// Store datagram in client's datagrams list
-	newDatagram := api.Datagram{
-		Direction: api.ClientToServer,
-		Message:   messageBytes,
-	}
-	udpClient.Datagrams = append(udpClient.Datagrams, newDatagram)
-	s.udpClients[clientName] = udpClient
+	// From the client to the server
+	communication.Packets.Publish(s.bus, communication.InternalMessage{
+		Caller:   communication.CallerWebServer,
+		ClientID: req.Id,
+	}, packetEvent(req.Id, 0, datagram))
 
-	// Broadcast WebSocket update
-	if s.wsHub != nil {
-		s.wsHub.Broadcast([]byte("usu" + strconv.Itoa(req.Id)))
-		s.wsHub.Broadcast([]byte("map"))
-		// pkt,fromId,toId,dir: client->server = from=clientId, to=0, dir=1
-		s.wsHub.Broadcast([]byte("pkt," + strconv.Itoa(req.Id) + ",0,1"))
-	}
-
-	// Send success response
-	response := api.SendDatagramResponse{
-		Message: "Datagram sent successfully",
-	}
-	log.Infof("Datagram sent successfully: %s", string(messageBytes))
-	response.Send(w)
+	s.logger.WithContext(ctx).WithField("cid", req.Id).Infof("Datagram sent successfully: %s", string(messageBytes))
+	return nil
 }
 
 func (s *Server) GetTraces(w http.ResponseWriter, r *http.Request) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	clientName := r.URL.Query().Get("name")
-	udpClient, ok := s.udpClients[clientName]
+	udpClient, ok := s.clients.GetByName(clientName)
 	if !ok {
 		apiError := api.ApiError{
-			Code:    http.StatusNotFound,
-			Message: "UDP client not found",
+			Code:      http.StatusNotFound,
+			ErrorCode: api.ErrClientNotFound,
+			Message:   "UDP client not found",
 		}
 		apiError.Send(w)
 		return
@@ -759,69 +1115,84 @@ func (s *Server) GetTraces(w http.ResponseWriter, r *http.Request) {
 // UDP Server Handler Methods
 
 func (s *Server) StartUDPServer(w http.ResponseWriter, r *http.Request) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	udpServerService := services.NewUDPServerService(s.ctx, s.cfg, s.wsHub)
-	if err := udpServerService.Start(s.config.UDPPort); err != nil {
+	if err := s.startUDPServer(); err != nil {
 		apiError := api.ApiError{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to create server (DTLS config error)",
+			Code:      http.StatusInternalServerError,
+			ErrorCode: api.ErrServerStartFailed,
+			Message:   "Failed to create server (DTLS config error)",
 		}
 		apiError.Send(w)
 		return
 	}
 
+	apiSuccess := api.ApiSuccess{
+		Message: "UDP server started",
+	}
+	apiSuccess.Send(w)
+}
+
+// startUDPServer creates a new UDP server on the configured port and runs it
+func (s *Server) startUDPServer() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	udpServerService := services.NewUDPServerService(s.ctx, s.cfg, s.bus, s.logger)
+	if err := udpServerService.Start(s.config.UDPPort); err != nil {
+		return err
+	}
+
 	udpServer := udpServerService.GetServer()
 	if udpServer == nil {
-		apiError := api.ApiError{
-			Code:    http.StatusInternalServerError,
-			Message: "Failed to create server (DTLS config error)",
-		}
-		apiError.Send(w)
-		return
+		return errors.New("failed to create server")
 	}
 
 	s.udpServer = udpServer
-	udpServer.OnPacket(protocol.PacketTypeDebugAny, func(packet *protocol.Packet, clientAddr string) error {
+	onEveryPacketType(udpServer.OnPacket, func(packet *protocol.Packet, clientAddr string) error {
 		return udpServerService.HandleAll(clientAddr, packet.Payload)
 	})
 
-	s.shutdownWg.Go(func() {
+	s.goroutines.Go(&s.shutdownWg, "udpServer", func() {
 		if err := udpServer.Run(); err != nil {
-			log.WithField("caller", "web").WithError(err).Error("error starting udp server")
+			s.logger.WithField("caller", "web").WithError(err).Error("error starting udp server")
 		}
 	})
-
-	apiSuccess := api.ApiSuccess{
-		Message: "UDP server started",
-	}
-	apiSuccess.Send(w)
+	return nil
 }
 
 func (s *Server) StopUDPServer(w http.ResponseWriter, r *http.Request) {
-	s.mu.Lock()
-	udpServer := s.udpServer
-	s.mu.Unlock()
-
-	if udpServer == nil {
-		log.WithField("caller", "web").Warn("UDP server is not running")
+	if err := s.stopUDPServer(); err != nil {
+		middleware.Log(s.logger, r).Warn("UDP server is not running")
 		apiError := api.ApiError{
-			Code:    http.StatusBadRequest,
-			Message: "UDP server is not running",
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrServerNotRunning,
+			Message:   "UDP server is not running",
 		}
 		apiError.Send(w)
 		return
 	}
 
-	udpServer.Stop()
-	log.WithField("caller", "web").Info("UDP server stopped")
+	middleware.Log(s.logger, r).Info("UDP server stopped")
 	apiSuccess := api.ApiSuccess{
 		Message: "UDP server stopped",
 	}
 	apiSuccess.Send(w)
 }
 
+var errUDPServerNotRunning = errors.New("UDP server is not running")
+
+// stopUDPServer stops the current UDP server
+func (s *Server) stopUDPServer() error {
+	s.mu.Lock()
+	udpServer := s.udpServer
+	s.mu.Unlock()
+
+	if udpServer == nil {
+		return errUDPServerNotRunning
+	}
+	udpServer.Stop()
+	return nil
+}
+
 func (s *Server) GetUDPServerState(w http.ResponseWriter, r *http.Request) {
 	s.mu.Lock()
 	udpServer := s.udpServer
@@ -2,14 +2,29 @@ package app
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/communication"
+	"github.com/auraspeak/debug-ui/internal/dissect"
+	"github.com/auraspeak/debug-ui/internal/services"
+	"github.com/auraspeak/debug-ui/internal/util"
+	"github.com/auraspeak/protocol"
 	"github.com/auraspeak/server/pkg/debugui"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,8 +37,7 @@ func TestNewServer(t *testing.T) {
 	assert.Equal(t, 8080, server.Port)
 	assert.Equal(t, 9090, server.config.UDPPort)
 	assert.NotNil(t, server.wsHub)
-	assert.NotNil(t, server.udpClients)
-	assert.NotNil(t, server.clientCommandChs)
+	assert.NotNil(t, server.clients)
 	assert.NotNil(t, server.ctx)
 }
 
@@ -118,6 +132,7 @@ func TestServer_GetUDPClientStateByName_NotFound(t *testing.T) {
 	err := json.Unmarshal(rr.Body.Bytes(), &response)
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, api.ErrClientNotFound, response.ErrorCode)
 	assert.Contains(t, response.Message, "not found")
 }
 
@@ -386,11 +401,314 @@ func TestServer_Shutdown_WithoutStart(t *testing.T) {
 	cfg := debugui.Config{}
 	server := NewServer(8080, 9090, cfg)
 
-	// Cancel context first to avoid blocking
-	server.cancel()
+	report, err := server.Shutdown(time.Second)
 
-	// Shutdown will panic if httpServer is nil, so we skip this test
-	// In real usage, httpServer is set in Run(), so this scenario shouldn't occur
-	// We test that Shutdown works when server is properly initialized
-	_ = server
+	require.NoError(t, err)
+	assert.False(t, report.ServerStopped)
+	assert.Zero(t, report.StoppedClients)
+}
+
+func TestEncodeDatagram_Default(t *testing.T) {
+	payload := []byte("hello")
+
+	result, packetType, err := encodeDatagram(api.SendDatagramRequest{}, payload)
+
+	require.NoError(t, err)
+	assert.Equal(t, protocol.PacketTypeDebugAny, packetType)
+	expected := (&protocol.Packet{
+		PacketHeader: protocol.Header{
+			Magic:      protocol.Magic,
+			Version:    protocol.Version,
+			PacketType: protocol.PacketTypeDebugAny,
+			Length:     uint32(len(payload)),
+		},
+		Payload: payload,
+	}).Encode()
+	assert.Equal(t, expected, result)
+}
+
+func TestEncodeDatagram_HeaderOverride(t *testing.T) {
+	payload := []byte("hello")
+	packetType, version, length := uint64(1), uint64(0), uint64(1000)
+
+	result, resultType, err := encodeDatagram(api.SendDatagramRequest{
+		Header: &api.HeaderOverride{
+			PacketType: &packetType,
+			Version:    &version,
+			Length:     &length,
+		},
+	}, payload)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, packetType, resultType)
+	// The header is magic (2 bytes), version, packet type and length (4 bytes, big endian)
+	require.Len(t, result, 8+len(payload))
+	assert.Equal(t, binary.BigEndian.AppendUint16(nil, protocol.Magic), result[0:2])
+	assert.Equal(t, []byte{0, 1}, result[2:4])
+	assert.Equal(t, binary.BigEndian.AppendUint32(nil, 1000), result[4:8])
+	assert.Equal(t, payload, result[8:])
+}
+
+func TestEncodeDatagram_HeaderOverrideOutOfRange(t *testing.T) {
+	length := uint64(1) << 40
+
+	_, _, err := encodeDatagram(api.SendDatagramRequest{
+		Header: &api.HeaderOverride{Length: &length},
+	}, []byte("hello"))
+
+	assert.Error(t, err)
+}
+
+func TestEncodeDatagram_Raw(t *testing.T) {
+	payload := []byte{0xde, 0xad, 0xbe, 0xef}
+	packetType := uint64(1)
+
+	result, _, err := encodeDatagram(api.SendDatagramRequest{
+		Raw:    true,
+		Header: &api.HeaderOverride{PacketType: &packetType},
+	}, payload)
+
+	require.NoError(t, err)
+	assert.Equal(t, payload, result)
+}
+
+func TestNewDatagram_Dissects(t *testing.T) {
+	datagram := NewServer(8080, 9090, debugui.Config{}).newDatagram(context.Background(), api.ClientToServer, protocol.PacketTypeDebugAny, false, []byte("hello"))
+
+	assert.Equal(t, api.ClientToServer, datagram.Direction)
+	assert.Equal(t, protocol.PacketTypeDebugAny, datagram.PacketType)
+	require.Len(t, datagram.Fields, 1)
+	assert.Equal(t, "hello", datagram.Fields[0].Value)
+}
+
+func TestNewDatagram_RawIsNotDissected(t *testing.T) {
+	datagram := NewServer(8080, 9090, debugui.Config{}).newDatagram(context.Background(), api.ClientToServer, 0, true, []byte{0x01, 0x02})
+
+	assert.True(t, datagram.Raw)
+	assert.Empty(t, datagram.Fields)
+}
+
+func TestServer_ReceivesEveryPacketType(t *testing.T) {
+	// A packet type debug-ui has no handler of its own for
+	const packetType protocol.PacketType = 0x42
+	dissect.Default.Register(packetType, func(payload []byte) ([]dissect.Field, error) {
+		return []dissect.Field{{Name: "counter", Type: dissect.FieldUint, Offset: 0, Length: 1, Value: uint64(payload[0])}}, nil
+	})
+	server := NewServer(8080, 9090, debugui.Config{})
+	scope := services.NewScope(server.ctx)
+	require.NoError(t, server.clients.Add(api.UDPClient{ID: 1, Name: "alice"}, scope))
+	var events []api.PacketEvent
+	communication.Packets.Subscribe(server.bus, func(_ communication.InternalMessage, event api.PacketEvent) {
+		events = append(events, event)
+	})
+
+	handlers := map[protocol.PacketType]func(*protocol.Packet) error{}
+	onEveryPacketType(func(packetType protocol.PacketType, h func(*protocol.Packet) error) {
+		handlers[packetType] = h
+	}, server.clientPacketHandler(1, scope))
+	require.Len(t, handlers, 256)
+	packet := &protocol.Packet{PacketHeader: protocol.Header{PacketType: packetType}, Payload: []byte{7}}
+	require.NoError(t, handlers[packetType](packet))
+
+	uc := clientByName(t, server, "alice")
+	require.Len(t, uc.Datagrams, 1)
+	assert.Equal(t, packetType, uc.Datagrams[0].PacketType)
+	want := []dissect.Field{{Name: "counter", Type: dissect.FieldUint, Offset: 0, Length: 1, Value: uint64(7)}}
+	assert.Equal(t, want, uc.Datagrams[0].Fields)
+	require.Len(t, events, 1)
+	assert.Equal(t, packetType, events[0].PacketType)
+	assert.Equal(t, want, events[0].Fields)
+}
+
+func TestServer_StartUDPClient_InvalidName(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	req := httptest.NewRequest("POST", "/api/client/start", strings.NewReader(`{"name":"a,b"}`))
+	rr := httptest.NewRecorder()
+
+	server.StartUDPClient(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Zero(t, server.clients.Len())
+}
+
+func TestServer_StartUDPClient_NameTaken(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	addClient(t, server, api.UDPClient{ID: 1, Name: "Alice Stonebrook"})
+
+	req := httptest.NewRequest("POST", "/api/client/start", strings.NewReader(`{"name":" Alice Stonebrook "}`))
+	rr := httptest.NewRecorder()
+
+	server.StartUDPClient(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, 1, server.clients.Len())
+}
+
+func TestServer_StartUDPClient_Failed(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	services.ResetIDs(1000)
+	addClient(t, server, api.UDPClient{ID: 1000, Name: "Alice Stonebrook"})
+
+	req := httptest.NewRequest("POST", "/api/client/start", strings.NewReader(`{"name":"Bob"}`))
+	rr := httptest.NewRecorder()
+
+	server.StartUDPClient(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Contains(t, rr.Body.String(), string(api.ErrInternal))
+	assert.Equal(t, 1, server.clients.Len())
+}
+
+func TestServer_SetNameGenerator(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	never := func(string) bool { return false }
+
+	req := httptest.NewRequest("POST", "/api/client/names", strings.NewReader(`{"seed":42,"fullNames":true}`))
+	rr := httptest.NewRecorder()
+
+	server.SetNameGenerator(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, util.NewNameGenerator(42, true).Next(never), server.names.Next(never))
+}
+
+func TestServer_RequestID(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	hook := logtest.NewLocal(server.logger)
+
+	req := httptest.NewRequest("POST", "/api/v1/server/stop", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	rr := httptest.NewRecorder()
+
+	server.Handler().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "req-42", rr.Header().Get("X-Request-ID"))
+	var response api.ApiError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, api.ErrServerNotRunning, response.ErrorCode)
+	assert.Equal(t, "req-42", response.RequestID)
+
+	// The log entries of the request carry its ID
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, "req-42", hook.LastEntry().Data["requestId"])
+}
+
+// startLeakTestClients starts n clients, each with a send job, and returns
+// the goroutine count from before
+func startLeakTestClients(t *testing.T, server *Server, n int) (baseline int) {
+	t.Helper()
+	// Without a UDP server the datagrams would fail and end the jobs
+	server.sendJobs = services.NewSendJobService(server.ctx, func(api.SendDatagramRequest) error { return nil }, func(api.SendJob) {})
+	baseline = runtime.NumGoroutine()
+	for i := range n {
+		uc, err := server.startUDPClient(context.Background(), fmt.Sprintf("client-%d", i), "leak", nil)
+		require.NoError(t, err)
+		_, err = server.sendJobs.StartIn(server.clients.Scope(uc.ID), api.StartSendJobRequest{ClientID: uc.ID, IntervalMs: 1000})
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return server.goroutines.Running()["handleClientCommands"] == n
+	}, time.Second, 5*time.Millisecond)
+	for _, job := range server.sendJobs.List(nil) {
+		require.Equal(t, api.SendJobRunning, job.State)
+	}
+	return baseline
+}
+
+func TestServer_StopUDPClient_ReleasesGoroutines(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	baseline := startLeakTestClients(t, server, 20)
+
+	for i := range 20 {
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/client/stop?name=client-%d", i), nil)
+		rr := httptest.NewRecorder()
+		server.StopUDPClient(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+	}
+
+	// StopUDPClient waits for the goroutines of the client
+	assert.Empty(t, server.goroutines.Running())
+	for _, job := range server.sendJobs.List(nil) {
+		assert.Equal(t, api.SendJobCancelled, job.State)
+	}
+	assertNoLeak(t, baseline)
+}
+
+func TestServer_StartSendJob_StoppedClient(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	uc, err := server.startUDPClient(context.Background(), "alice", "", nil)
+	require.NoError(t, err)
+	server.clients.Update(uc.ID, func(uc *api.UDPClient) {
+		uc.Running = true
+	})
+	server.waitClients(server.stopUDPClient(uc))
+
+	req := httptest.NewRequest("POST", "/api/client/jobs/start", strings.NewReader(fmt.Sprintf(`{"clientId":%d,"intervalMs":10}`, uc.ID)))
+	rr := httptest.NewRecorder()
+	server.StartSendJob(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), string(api.ErrClientNotRunning))
+	assert.Empty(t, server.sendJobs.List(nil))
+}
+
+// assertNoLeak waits until no more goroutines run than before the test
+func assertNoLeak(t *testing.T, baseline int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), baseline, "goroutines leaked")
+}
+
+// addClient registers a client without goroutines
+func addClient(t *testing.T, server *Server, uc api.UDPClient) {
+	t.Helper()
+	require.NoError(t, server.clients.Add(uc, nil))
+}
+
+func clientByName(t *testing.T, server *Server, name string) api.UDPClient {
+	t.Helper()
+	uc, ok := server.clients.GetByName(name)
+	require.True(t, ok, "client %s not found", name)
+	return uc
+}
+
+// BenchmarkServer_handleAllClient receives packets for 1,000 clients in
+// parallel while the client list is requested, as with many chatty clients
+func BenchmarkServer_handleAllClient(b *testing.B) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	server.logger.SetLevel(log.WarnLevel)
+	const clients = 1000
+	for id := range clients {
+		require.NoError(b, server.clients.Add(api.UDPClient{ID: id, Name: fmt.Sprintf("client-%d", id)}, nil))
+	}
+	packet := &protocol.Packet{PacketHeader: protocol.Header{PacketType: protocol.PacketTypeDebugAny}, Payload: []byte("ping")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	listed := 0
+	wg.Go(func() {
+		for ctx.Err() == nil {
+			server.GetAllUDPClientPaginated(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/client/get/all/paginated?pageSize=50", nil))
+			listed++
+		}
+	})
+	var next atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := server.handleAllClient(int(next.Add(1)%clients), packet); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+	b.StopTimer()
+	cancel()
+	wg.Wait()
+	b.ReportMetric(float64(listed)/b.Elapsed().Seconds(), "lists/s")
 }
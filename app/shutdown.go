@@ -0,0 +1,251 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	"github.com/auraspeak/debug-ui/internal/services"
+	"github.com/auraspeak/debug-ui/internal/ws"
+)
+
+// Steps of Shutdown, in the order they run
+const (
+	ShutdownHTTP       = "http"
+	ShutdownClients    = "clients"
+	ShutdownUDPServer  = "udpServer"
+	ShutdownLoops      = "loops"
+	ShutdownFlush      = "flush"
+	ShutdownWebSockets = "websockets"
+)
+
+// shutdownMessage is the content of the last WebSocket message
+const shutdownMessage = "server shutting down"
+
+// ShutdownStep is how a step of Shutdown went
+type ShutdownStep struct {
+	Name     string
+	Duration time.Duration
+	// Err is set when the step failed or didn't finish before the deadline
+	Err error
+	// Pending are the goroutines of the step still running at the deadline,
+	// by component
+	Pending map[string]int
+}
+
+// ShutdownReport tells what Shutdown stopped and what didn't stop in time
+type ShutdownReport struct {
+	Steps          []ShutdownStep
+	StoppedClients int
+	ServerStopped  bool
+	// Traces and log entries kept after the flush
+	Traces int
+	Logs   int
+}
+
+// Err joins the errors of the steps, nil when everything stopped in time
+func (r ShutdownReport) Err() error {
+	var errs []error
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", step.Name, step.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Step returns the step with the given name
+func (r ShutdownReport) Step(name string) (ShutdownStep, bool) {
+	for _, step := range r.Steps {
+		if step.Name == name {
+			return step, true
+		}
+	}
+	return ShutdownStep{}, false
+}
+
+func (r ShutdownReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "stopped %d clients, server stopped: %t, kept %d traces and %d log entries",
+		r.StoppedClients, r.ServerStopped, r.Traces, r.Logs)
+	for _, step := range r.Steps {
+		fmt.Fprintf(&b, "\n  %-10s %8s", step.Name, step.Duration.Round(time.Millisecond))
+		if step.Err != nil {
+			fmt.Fprintf(&b, "  %v", step.Err)
+		}
+		if len(step.Pending) > 0 {
+			fmt.Fprintf(&b, "  pending: %v", step.Pending)
+		}
+	}
+	return b.String()
+}
+
+// refuseWhileClosing answers API calls and new WebSockets with 503 once
+// Shutdown started. The probes and UI files stay available.
+func (s *Server) refuseWhileClosing(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.closing.Load() && (r.URL.Path == "/ws" || strings.HasPrefix(r.URL.Path, "/api/")) {
+			w.Header().Set("Connection", "close")
+			apiError := api.ApiError{
+				Code:      http.StatusServiceUnavailable,
+				ErrorCode: api.ErrUnavailable,
+				Message:   "Server is shutting down",
+			}
+			apiError.Send(w)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Shutdown stops the server in order: it stops accepting API calls, stops
+// the clients and the UDP server, ends the remaining goroutines, flushes
+// traces and logs and closes the WebSockets with a final SHD message. All
+// steps share the timeout; a step that runs out of time is reported and
+// the next one starts. The error is report.Err().
+func (s *Server) Shutdown(timeout time.Duration) (ShutdownReport, error) {
+	fmt.Println("Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var report ShutdownReport
+
+	// Refuse new API calls and stop listening. Open event streams keep the
+	// HTTP server busy until the WebSockets step, so its result is collected last.
+	start := time.Now()
+	s.closing.Store(true)
+	s.mu.Lock()
+	httpServer := s.httpServer
+	s.mu.Unlock()
+	httpDone := make(chan error, 1)
+	if httpServer != nil {
+		go func() {
+			httpDone <- httpServer.Shutdown(ctx)
+		}()
+	} else {
+		httpDone <- nil
+	}
+	httpStep := len(report.Steps)
+	report.Steps = append(report.Steps, ShutdownStep{Name: ShutdownHTTP})
+
+	report.Steps = append(report.Steps, s.shutdownStep(ctx, ShutdownClients, func() error {
+		s.stopFuzz()
+		var scopes []*services.Scope
+		for _, uc := range s.clients.Snapshot() {
+			if uc.Running {
+				report.StoppedClients++
+			}
+			scopes = append(scopes, s.stopUDPClient(uc))
+			s.clients.Update(uc.ID, func(uc *api.UDPClient) {
+				uc.Running = false
+			})
+		}
+		for _, scope := range scopes {
+			if err := scope.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, "udpClient", "handleClientCommands", "fuzz"))
+
+	report.Steps = append(report.Steps, s.shutdownStep(ctx, ShutdownUDPServer, func() error {
+		err := s.stopUDPServer()
+		report.ServerStopped = err == nil
+		if errors.Is(err, errUDPServerNotRunning) {
+			return nil
+		}
+		return err
+	}, "udpServer"))
+
+	// The loops, send jobs and fuzz runs end with the server context
+	report.Steps = append(report.Steps, s.shutdownStep(ctx, ShutdownLoops, func() error {
+		s.cancel()
+		return nil
+	}, "handleInternal", "handleTrace"))
+	if !waitCtx(ctx, s.sendJobs.Wait) {
+		step := &report.Steps[len(report.Steps)-1]
+		step.Err = errors.Join(step.Err, fmt.Errorf("send jobs: %w", ctx.Err()))
+	}
+
+	report.Steps = append(report.Steps, s.shutdownStep(ctx, ShutdownFlush, func() error {
+		report.Traces = s.flushTraces()
+		s.logger.WithField("caller", "web").Infof("Shutdown: stopped %d clients, kept %d traces", report.StoppedClients, report.Traces)
+		// Stop feeding the log store and the websockets
+		logstore.RemoveHook(s.logger, s.logHook)
+		s.logLevels.Uninstall()
+		report.Logs = s.logs.Len()
+		return nil
+	}))
+
+	report.Steps = append(report.Steps, s.shutdownStep(ctx, ShutdownWebSockets, func() error {
+		return s.wsHub.Close(ctx, ws.WebSocketMessage{Type: ws.TypeShutdown, Content: shutdownMessage})
+	}))
+
+	var err error
+	select {
+	case err = <-httpDone:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	report.Steps[httpStep].Duration = time.Since(start)
+	report.Steps[httpStep].Err = err
+
+	err = report.Err()
+	if err != nil {
+		fmt.Printf("Warning: shutdown incomplete: %v\n", err)
+	} else {
+		fmt.Println("Server shutdown complete")
+	}
+	return report, err
+}
+
+// shutdownStep runs stop and waits until the goroutines of the components
+// have returned
+func (s *Server) shutdownStep(ctx context.Context, name string, stop func() error, components ...string) ShutdownStep {
+	start := time.Now()
+	step := ShutdownStep{Name: name}
+	if err := stop(); err != nil {
+		step.Err = err
+	}
+	if pending := s.goroutines.WaitFor(ctx, components...); len(pending) > 0 {
+		step.Pending = pending
+		step.Err = errors.Join(step.Err, ctx.Err())
+	}
+	step.Duration = time.Since(start)
+	return step
+}
+
+// flushTraces stores the traces still queued by the UDP server and returns
+// the number of stored traces
+func (s *Server) flushTraces() int {
+	s.mu.Lock()
+	udpServer := s.udpServer
+	s.mu.Unlock()
+
+	s.traceMu.Lock()
+	defer s.traceMu.Unlock()
+	// handleTrace has returned, nothing else receives from the channel
+	for udpServer != nil && len(udpServer.TraceCh) > 0 {
+		s.traces = append(s.traces, <-udpServer.TraceCh)
+	}
+	return len(s.traces)
+}
+
+// waitCtx runs wait and reports whether it returned before ctx ended
+func waitCtx(ctx context.Context, wait func()) bool {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/ws"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestServer_Shutdown_InOrder(t *testing.T) {
+	server := NewServerWithConfig(0, Config{TemplateDir: t.TempDir()}, debugui.Config{})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(l)
+	}()
+	url := "http://" + l.Addr().String()
+	require.Eventually(t, func() bool { return server.Addr() != "" }, time.Second, 10*time.Millisecond)
+	conn, err := websocket.Dial("ws://"+l.Addr().String()+"/ws", "", url)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Eventually(t, func() bool { return server.wsHub.ConnectionCount() == 1 }, time.Second, 10*time.Millisecond)
+	_, err = server.startUDPClient(context.Background(), "alice", "", nil)
+	require.NoError(t, err)
+
+	report, err := server.Shutdown(5 * time.Second)
+
+	require.NoError(t, err)
+	var steps []string
+	for _, step := range report.Steps {
+		steps = append(steps, step.Name)
+	}
+	assert.Equal(t, []string{ShutdownHTTP, ShutdownClients, ShutdownUDPServer, ShutdownLoops, ShutdownFlush, ShutdownWebSockets}, steps)
+	assert.False(t, report.ServerStopped)
+	assert.Positive(t, report.Logs)
+	assert.Empty(t, server.goroutines.Running())
+	assert.ErrorIs(t, <-served, http.ErrServerClosed)
+
+	// The last WebSocket message announces the shutdown
+	var last string
+	for {
+		var msg string
+		if err := websocket.Message.Receive(conn, &msg); err != nil {
+			break
+		}
+		last = msg
+	}
+	var msg ws.WebSocketMessage
+	require.NoError(t, json.Unmarshal([]byte(last), &msg))
+	assert.Equal(t, ws.TypeShutdown, msg.Type)
+}
+
+func TestServer_RefuseWhileClosing(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	server.closing.Store(true)
+	handler := server.Handler()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/server/get", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), string(api.ErrUnavailable))
+
+	// The probes still answer
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestShutdownReport_Err(t *testing.T) {
+	errTest := errors.New("client hangs")
+	report := ShutdownReport{Steps: []ShutdownStep{
+		{Name: ShutdownHTTP},
+		{Name: ShutdownClients, Err: errTest, Pending: map[string]int{"udpClient": 2}},
+	}}
+
+	assert.ErrorIs(t, report.Err(), errTest)
+	assert.Contains(t, report.Err().Error(), "clients: ")
+	assert.Contains(t, report.String(), "pending: map[udpClient:2]")
+	step, ok := report.Step(ShutdownClients)
+	assert.True(t, ok)
+	assert.Equal(t, 2, step.Pending["udpClient"])
+	assert.NoError(t, ShutdownReport{}.Err())
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/ws"
+)
+
+// streamFilter matches events whose data contains the filter query parameter, ignoring case
+func streamFilter(r *http.Request) func(ws.Event) bool {
+	filter := bytes.ToLower([]byte(r.URL.Query().Get("filter")))
+	return func(e ws.Event) bool {
+		return len(filter) == 0 || bytes.Contains(bytes.ToLower(e.Data), filter)
+	}
+}
+
+// eventClientID returns the client a packet event belongs to, parsed from
+// "pkt,fromId,toId,dir" or "dgm,clientId,seq". The server is 0 in pkt
+// events like the first client, so the direction tells which side is the
+// client.
+func eventClientID(e ws.Event) (int, bool) {
+	parts := strings.Split(e.Content, ",")
+	var id string
+	switch {
+	case parts[0] == "pkt" && len(parts) >= 4 && parts[3] == strconv.Itoa(int(api.ClientToServer)):
+		id = parts[1]
+	case parts[0] == "pkt" && len(parts) >= 4 && parts[3] == strconv.Itoa(int(api.ServerToClient)):
+		id = parts[2]
+	case parts[0] == "dgm" && len(parts) >= 2:
+		id = parts[1]
+	default:
+		return 0, false
+	}
+	clientID, err := strconv.Atoi(id)
+	return clientID, err == nil
+}
+
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request, kind ws.EventKind, match func(ws.Event) bool) {
+	if s.wsHub == nil {
+		apiError := api.ApiError{
+			Code:      http.StatusServiceUnavailable,
+			ErrorCode: api.ErrUnavailable,
+			Message:   "Event streams are not available",
+		}
+		apiError.Send(w)
+		return
+	}
+	s.wsHub.ServeSSE(w, r, kind, match)
+}
+
+func (s *Server) StreamLogs(w http.ResponseWriter, r *http.Request) {
+	s.serveStream(w, r, ws.EventLog, streamFilter(r))
+}
+
+func (s *Server) StreamPackets(w http.ResponseWriter, r *http.Request) {
+	filter := streamFilter(r)
+	clientID := 0
+	client := r.URL.Query().Get("client")
+	hasClient := client != ""
+	if hasClient {
+		var err error
+		clientID, err = strconv.Atoi(client)
+		if err != nil {
+			apiError := api.ApiError{
+				Code:      http.StatusBadRequest,
+				ErrorCode: api.ErrInvalidParameter,
+				Message:   "Client is invalid",
+			}
+			apiError.Send(w)
+			return
+		}
+	}
+	s.serveStream(w, r, ws.EventPacket, func(e ws.Event) bool {
+		if hasClient {
+			if id, ok := eventClientID(e); !ok || id != clientID {
+				return false
+			}
+		}
+		return filter(e)
+	})
+}
+
+func (s *Server) StreamState(w http.ResponseWriter, r *http.Request) {
+	s.serveStream(w, r, ws.EventState, streamFilter(r))
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auraspeak/debug-ui/internal/ws"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventClientID(t *testing.T) {
+	tests := []struct {
+		content string
+		id      int
+		ok      bool
+	}{
+		{"pkt,3,0,1", 3, true},
+		{"pkt,0,3,2", 3, true},
+		{"pkt,0,0,1", 0, true},
+		{"dgm,5,12", 5, true},
+		{"dgm,0,1", 0, true},
+		{"pkt,3,0", 0, false},
+		{"usu5", 0, false},
+	}
+
+	for _, tt := range tests {
+		id, ok := eventClientID(ws.Event{Content: tt.content})
+		assert.Equal(t, tt.ok, ok, tt.content)
+		assert.Equal(t, tt.id, id, tt.content)
+	}
+}
+
+func TestServer_StreamPackets_ClientFilter(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	require.NoError(t, server.wsHub.BroadcastMessage(ws.WebSocketMessage{Type: ws.TypePacket, Content: "pkt,1,0,1", Data: "one"}))
+	require.NoError(t, server.wsHub.BroadcastMessage(ws.WebSocketMessage{Type: ws.TypePacket, Content: "pkt,0,2,2", Data: "two"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("GET", "/api/stream/packets?client=2", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	server.StreamPackets(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"data":"two"`)
+	assert.NotContains(t, rr.Body.String(), `"data":"one"`)
+}
+
+func TestServer_StreamPackets_FirstClient(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	require.NoError(t, server.wsHub.BroadcastMessage(ws.WebSocketMessage{Type: ws.TypePacket, Content: "pkt,0,0,1", Data: "zero"}))
+	require.NoError(t, server.wsHub.BroadcastMessage(ws.WebSocketMessage{Type: ws.TypePacket, Content: "pkt,0,2,2", Data: "two"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("GET", "/api/stream/packets?client=0", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	server.StreamPackets(rr, req)
+
+	assert.Contains(t, rr.Body.String(), `"data":"zero"`)
+	assert.NotContains(t, rr.Body.String(), `"data":"two"`)
+}
+
+func TestServer_StreamPackets_InvalidClient(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	req := httptest.NewRequest("GET", "/api/stream/packets?client=abc", nil)
+	rr := httptest.NewRecorder()
+
+	server.StreamPackets(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestServer_StreamState_Filter(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	server.wsHub.Broadcast([]byte("map"))
+	server.wsHub.Broadcast([]byte("USU4"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("GET", "/api/stream/state?filter=usu", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	server.StreamState(rr, req)
+
+	assert.Equal(t, "id: 2\nevent: state\ndata: USU4\n\n", rr.Body.String())
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/payload"
+)
+
+// renderTemplate renders a stored template for one client, the sequence
+// counter advances per template and client
+func (s *Server) renderTemplate(name string, variables map[string]string, clientID int, clientName string) ([]byte, error) {
+	tmpl, err := s.templates.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Render(payload.Vars{
+		Seq:        s.templates.NextSeq(name, clientID),
+		ClientID:   clientID,
+		ClientName: clientName,
+		Time:       time.Now(),
+		Overrides:  variables,
+	})
+}
+
+func (s *Server) GetAllTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := s.templates.List()
+	if err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusInternalServerError,
+			ErrorCode: api.ErrInternal,
+			Message:   "Can't read templates",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	resp := api.AllTemplatesResponse{Templates: templates}
+	resp.Send(w)
+}
+
+func (s *Server) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrMissingParameter,
+			Message:   "Name is required",
+		}
+		apiError.Send(w)
+		return
+	}
+	tmpl, err := s.templates.Get(name)
+	if err != nil {
+		apiError := templateError(err)
+		apiError.Send(w)
+		return
+	}
+	resp := api.TemplateResponse{Template: tmpl}
+	resp.Send(w)
+}
+
+func (s *Server) SaveTemplate(w http.ResponseWriter, r *http.Request) {
+	var tmpl payload.Template
+	if err := json.NewDecoder(r.Body).Decode(&tmpl); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	if err := tmpl.Validate(); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidTemplate,
+			Message:   "Invalid template",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	if err := s.templates.Save(tmpl); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusInternalServerError,
+			ErrorCode: api.ErrInternal,
+			Message:   "Can't save template",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	apiSuccess := api.ApiSuccess{
+		Message: "Template saved",
+	}
+	apiSuccess.Send(w)
+}
+
+func (s *Server) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrMissingParameter,
+			Message:   "Name is required",
+		}
+		apiError.Send(w)
+		return
+	}
+	if err := s.templates.Delete(name); err != nil {
+		apiError := templateError(err)
+		apiError.Send(w)
+		return
+	}
+	apiSuccess := api.ApiSuccess{
+		Message: "Template deleted",
+	}
+	apiSuccess.Send(w)
+}
+
+// templateError maps template store errors to API errors
+func templateError(err error) api.ApiError {
+	if errors.Is(err, payload.ErrNotFound) {
+		return api.ApiError{
+			Code:      http.StatusNotFound,
+			ErrorCode: api.ErrTemplateNotFound,
+			Message:   "Template not found",
+		}
+	}
+	return api.ApiError{
+		Code:      http.StatusInternalServerError,
+		ErrorCode: api.ErrInternal,
+		Message:   "Can't access template",
+		Details:   err.Error(),
+	}
+}
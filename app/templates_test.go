@@ -0,0 +1,98 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/payload"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTemplateTestServer(t *testing.T) *Server {
+	server := NewServer(8080, 9090, debugui.Config{})
+	server.templates = payload.NewStore(t.TempDir())
+	return server
+}
+
+func TestServer_SaveAndGetTemplate(t *testing.T) {
+	server := newTemplateTestServer(t)
+
+	body, _ := json.Marshal(payload.Template{Name: "greet", Format: "text", Body: "hi {{clientName}}"})
+	req := httptest.NewRequest("POST", "/api/templates/save", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	server.SaveTemplate(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest("GET", "/api/templates/get?name=greet", nil)
+	rr = httptest.NewRecorder()
+	server.GetTemplate(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response api.TemplateResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "hi {{clientName}}", response.Body)
+
+	req = httptest.NewRequest("GET", "/api/templates/all", nil)
+	rr = httptest.NewRecorder()
+	server.GetAllTemplates(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var all api.AllTemplatesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &all))
+	assert.Len(t, all.Templates, 1)
+}
+
+func TestServer_SaveTemplate_Invalid(t *testing.T) {
+	server := newTemplateTestServer(t)
+
+	body, _ := json.Marshal(payload.Template{Name: "bad", Format: "hex", Body: "xyz"})
+	req := httptest.NewRequest("POST", "/api/templates/save", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.SaveTemplate(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestServer_DeleteTemplate_NotFound(t *testing.T) {
+	server := newTemplateTestServer(t)
+
+	req := httptest.NewRequest("POST", "/api/templates/delete?name=missing", nil)
+	rr := httptest.NewRecorder()
+
+	server.DeleteTemplate(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestServer_RenderTemplate_Sequence(t *testing.T) {
+	server := newTemplateTestServer(t)
+	require.NoError(t, server.templates.Save(payload.Template{Name: "seq", Format: "text", Body: "{{clientName}}:{{seq}}"}))
+
+	first, err := server.renderTemplate("seq", nil, 1, "Bobo")
+	require.NoError(t, err)
+	second, err := server.renderTemplate("seq", nil, 1, "Bobo")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bobo:0", string(first))
+	assert.Equal(t, "Bobo:1", string(second))
+}
+
+func TestServer_SendDatagram_TemplateWithoutFormat(t *testing.T) {
+	server := newTemplateTestServer(t)
+
+	body, _ := json.Marshal(api.SendDatagramRequest{Id: 999, Template: "greet"})
+	req := httptest.NewRequest("POST", "/api/client/send", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	server.SendDatagram(rr, req)
+
+	// Format validation is skipped for templates, so the unknown client is reported
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/communication"
+	"github.com/auraspeak/debug-ui/internal/middleware"
+	"github.com/auraspeak/debug-ui/internal/services"
+	"github.com/auraspeak/debug-ui/internal/ws"
+)
+
+func (s *Server) ResetWorkspace(w http.ResponseWriter, r *http.Request) {
+	var req api.WorkspaceResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError := api.ApiError{
+			Code:      http.StatusBadRequest,
+			ErrorCode: api.ErrInvalidBody,
+			Message:   "Invalid request body",
+			Details:   err.Error(),
+		}
+		apiError.Send(w)
+		return
+	}
+	if req.All {
+		req = api.WorkspaceResetRequest{
+			StopClients:   true,
+			RemoveClients: true,
+			StopServer:    true,
+			ClearTraces:   true,
+			ClearLogs:     true,
+			ResetIDs:      true,
+		}
+	}
+
+	response := s.resetWorkspace(r.Context(), req)
+	middleware.Log(s.logger, r).Infof("Workspace reset: %+v", response)
+	communication.Resets.Publish(s.bus, communication.InternalMessage{
+		Caller: communication.CallerWebServer,
+	}, response)
+	response.Send(w)
+}
+
+// resetWorkspace wipes the state selected by req. The fuzzer is stopped
+// before its client or the UDP server go away.
+func (s *Server) resetWorkspace(ctx context.Context, req api.WorkspaceResetRequest) api.WorkspaceResetResponse {
+	response := api.WorkspaceResetResponse{}
+
+	if req.StopClients || req.RemoveClients || req.StopServer {
+		response.FuzzStopped = s.stopFuzz()
+	}
+
+	var scopes []*services.Scope
+	if req.StopClients || req.RemoveClients {
+		for _, uc := range s.clients.Snapshot() {
+			if uc.Running {
+				response.StoppedClients++
+			}
+			scopes = append(scopes, s.stopUDPClient(uc))
+			if req.RemoveClients {
+				s.clients.Remove(uc.ID)
+				response.RemovedClients++
+			}
+		}
+	}
+	if req.ResetIDs {
+		next := 0
+		for _, uc := range s.clients.Snapshot() {
+			next = max(next, uc.ID+1)
+		}
+		services.ResetIDs(next)
+		response.NextID = &next
+	}
+	s.mu.Lock()
+	udpServer := s.udpServer
+	s.mu.Unlock()
+
+	s.waitClients(scopes...)
+	if req.RemoveClients {
+		// After the wait, so the cancelled jobs are finished
+		s.sendJobs.RemoveFinished()
+	}
+
+	if req.StopServer && udpServer != nil && udpServer.ServerState.IsAlive {
+		if err := s.stopUDPServer(); err != nil {
+			s.logger.WithContext(ctx).WithField("caller", "web").WithError(err).Error("Can't stop UDP server")
+		} else {
+			response.ServerStopped = true
+		}
+	}
+
+	if req.ClearTraces {
+		s.traceMu.Lock()
+		response.ClearedTraces = len(s.traces)
+		s.traces = nil
+		s.traceMu.Unlock()
+	}
+	if req.ClearLogs {
+		if s.logs != nil {
+			response.ClearedLogs = s.logs.Clear()
+		}
+		if s.wsHub != nil {
+			response.ClearedLogEvents = s.wsHub.ClearEvents(ws.EventLog)
+		}
+	}
+	return response
+}
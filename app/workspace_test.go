@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/services"
+	"github.com/auraspeak/debug-ui/internal/ws"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/auraspeak/server/pkg/tracer"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ResetWorkspace_InvalidBody(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	req := httptest.NewRequest("POST", "/api/workspace/reset", strings.NewReader("{"))
+	rr := httptest.NewRecorder()
+
+	server.ResetWorkspace(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestServer_ResetWorkspace_TracesLogsAndIDs(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	addClient(t, server, api.UDPClient{ID: 4, Name: "alice"})
+	server.traces = []tracer.TraceEvent{{ClientID: 4}, {ClientID: 4}}
+	server.wsHub.BroadcastLog([]byte(`{"msg":"old"}`))
+	addLog(server, log.InfoLevel, "old", nil)
+	addLog(server, log.InfoLevel, "older", nil)
+	server.wsHub.Broadcast([]byte("uss"))
+
+	req := httptest.NewRequest("POST", "/api/workspace/reset", strings.NewReader(`{"clearTraces":true,"clearLogs":true,"resetIds":true}`))
+	rr := httptest.NewRecorder()
+
+	server.ResetWorkspace(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response api.WorkspaceResetResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.ClearedTraces)
+	assert.Equal(t, 2, response.ClearedLogs)
+	assert.Equal(t, 1, response.ClearedLogEvents)
+	require.NotNil(t, response.NextID)
+	// IDs continue after the remaining client
+	assert.Equal(t, 5, *response.NextID)
+	assert.Equal(t, 5, services.GetNextID())
+	assert.Empty(t, server.traces)
+	assert.Equal(t, 1, server.clients.Len())
+
+	// Logs of the reset itself may follow, but the old entry is gone
+	backlog, _, unsubscribe := server.wsHub.Subscribe(0, nil)
+	defer unsubscribe()
+	for _, e := range backlog {
+		assert.NotContains(t, string(e.Data), `"old"`)
+	}
+	assert.Equal(t, "uss", backlog[0].Content)
+	assert.Equal(t, ws.EventState, backlog[0].Kind)
+}
+
+func TestServer_ResetWorkspace_RemoveClientsWithoutClients(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+
+	req := httptest.NewRequest("POST", "/api/workspace/reset", strings.NewReader(`{"all":true}`))
+	rr := httptest.NewRecorder()
+
+	server.ResetWorkspace(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var response api.WorkspaceResetResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.False(t, response.ServerStopped)
+	require.NotNil(t, response.NextID)
+	assert.Equal(t, 0, *response.NextID)
+}
+
+func TestServer_ResetWorkspace_RemoveClientsReleasesGoroutines(t *testing.T) {
+	server := NewServer(8080, 9090, debugui.Config{})
+	baseline := startLeakTestClients(t, server, 10)
+
+	response := server.resetWorkspace(context.Background(), api.WorkspaceResetRequest{RemoveClients: true})
+
+	assert.Equal(t, 10, response.RemovedClients)
+	assert.Zero(t, server.clients.Len())
+	assert.Empty(t, server.goroutines.Running())
+	// The cancelled jobs are finished and removed
+	assert.Empty(t, server.sendJobs.List(nil))
+	assertNoLeak(t, baseline)
+}
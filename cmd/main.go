@@ -13,7 +13,7 @@ import (
 
 func main() {
 	cfg := debugui.LoadConfig()
-	server := app.NewServer(8080, 9090, *cfg)
+	server := app.NewServerWithConfig(8080, app.ConfigFromEnv(9090), *cfg)
 
 	// Starte Server in Goroutine
 	go func() {
@@ -34,7 +34,8 @@ func main() {
 	println("\nReceived shutdown signal")
 
 	// Graceful shutdown mit 10 Sekunden Timeout
-	if err := server.Shutdown(10 * time.Second); err != nil {
-		panic(err)
+	if report, err := server.Shutdown(10 * time.Second); err != nil {
+		println(report.String())
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// Values of HealthResponse.Status
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+)
+
+// HealthCheck is a single check of /healthz or /readyz
+type HealthCheck struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// HealthResponse is the response of /healthz and /readyz. The status code
+// is 200 when all checks pass, 503 otherwise.
+type HealthResponse struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// NewHealthResponse sets the status from the checks
+func NewHealthResponse(checks []HealthCheck) HealthResponse {
+	status := HealthOK
+	for _, check := range checks {
+		if !check.OK {
+			status = HealthUnavailable
+		}
+	}
+	return HealthResponse{Status: status, Checks: checks}
+}
+
+func (hr *HealthResponse) Send(w http.ResponseWriter) {
+	code := http.StatusOK
+	if hr.Status != HealthOK {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, code, hr)
+}
+
+// GoroutineDiagnostics counts goroutines. Components are the goroutines the
+// server started itself, like the loop of a client.
+type GoroutineDiagnostics struct {
+	Total      int            `json:"total"`
+	Components map[string]int `json:"components"`
+}
+
+// ChannelDiagnostics is the backlog of a channel
+type ChannelDiagnostics struct {
+	Name string `json:"name"`
+	// ClientID is set for the channels of a client
+	ClientID *int `json:"clientId,omitempty"`
+	Len      int  `json:"len"`
+	Cap      int  `json:"cap"`
+}
+
+// LockDiagnostics are the wait and hold times of a lock in milliseconds
+type LockDiagnostics struct {
+	Name         string  `json:"name"`
+	Acquisitions uint64  `json:"acquisitions"`
+	Contended    uint64  `json:"contended"`
+	TotalWaitMs  float64 `json:"totalWaitMs"`
+	MaxWaitMs    float64 `json:"maxWaitMs"`
+	TotalHoldMs  float64 `json:"totalHoldMs"`
+	MaxHoldMs    float64 `json:"maxHoldMs"`
+	Held         bool    `json:"held"`
+	HeldForMs    float64 `json:"heldForMs"`
+}
+
+// WebSocketDiagnostics counts the live connections of the hub
+type WebSocketDiagnostics struct {
+	Connections int `json:"connections"`
+	// Subscribers are the Server-Sent Event streams
+	Subscribers int `json:"subscribers"`
+}
+
+// EventDiagnostics counts the messages of the internal event bus
+type EventDiagnostics struct {
+	Subscribers int `json:"subscribers"`
+	// Published messages by topic since the start
+	Published map[string]uint64 `json:"published"`
+}
+
+// MemoryDiagnostics is a subset of runtime.MemStats
+type MemoryDiagnostics struct {
+	HeapAlloc    uint64  `json:"heapAlloc"`
+	HeapInuse    uint64  `json:"heapInuse"`
+	HeapObjects  uint64  `json:"heapObjects"`
+	Sys          uint64  `json:"sys"`
+	TotalAlloc   uint64  `json:"totalAlloc"`
+	NumGC        uint32  `json:"numGC"`
+	PauseTotalMs float64 `json:"pauseTotalMs"`
+}
+
+// DiagnosticsResponse is the response of GET /api/v1/diagnostics
+type DiagnosticsResponse struct {
+	StartedAt  time.Time            `json:"startedAt"`
+	UptimeMs   int64                `json:"uptimeMs"`
+	Goroutines GoroutineDiagnostics `json:"goroutines"`
+	Channels   []ChannelDiagnostics `json:"channels"`
+	Locks      []LockDiagnostics    `json:"locks"`
+	WebSocket  WebSocketDiagnostics `json:"websocket"`
+	Events     EventDiagnostics     `json:"events"`
+	Memory     MemoryDiagnostics    `json:"memory"`
+	Clients    int                  `json:"clients"`
+	UDPServer  bool                 `json:"udpServer"`
+}
+
+func (dr *DiagnosticsResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, dr)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/auraspeak/debug-ui/internal/openapi"
+	"github.com/auraspeak/debug-ui/internal/payload"
+)
+
+// endpoint is a REST route. Its Path is relative to the API base path.
+type endpoint struct {
+	handle func(h Handlers, w http.ResponseWriter, r *http.Request)
+	openapi.Route
+}
+
+// Query parameters shared by several routes
+var (
+	idParam       = openapi.Param{Name: "id", Type: 0, Required: true, Description: "Client ID"}
+	nameParam     = openapi.Param{Name: "name", Required: true, Description: "Client name"}
+	groupParam    = openapi.Param{Name: "group", Required: true}
+	jobIDParam    = openapi.Param{Name: "id", Type: 0, Required: true, Description: "Send job ID"}
+	tagParam      = openapi.Param{Name: "tag", Repeated: true, Description: "Only clients with all these tags"}
+	templateParam = openapi.Param{Name: "name", Required: true, Description: "Template name"}
+	filterParam   = openapi.Param{Name: "filter", Description: "Only events containing this text, ignoring case"}
+	lastEventID   = openapi.Param{Name: "lastEventId", Type: 0, Description: "Resume after this event, like the Last-Event-ID header"}
+)
+
+// endpoints are the REST routes. RegisterRoutes serves them under every
+// API version and the OpenAPI document describes them, so a new endpoint
+// only needs a Handlers method and an entry here.
+var endpoints = []endpoint{
+	{Handlers.StartUDPServer, openapi.Route{Method: "POST", Path: "/server/start", Tag: "server", Summary: "Start the UDP server", Response: ApiSuccess{}}},
+	{Handlers.StopUDPServer, openapi.Route{Method: "POST", Path: "/server/stop", Tag: "server", Summary: "Stop the UDP server", Response: ApiSuccess{}}},
+	{Handlers.GetUDPServerState, openapi.Route{Method: "GET", Path: "/server/get", Tag: "server", Summary: "State of the UDP server", Response: ServerStateResponse{}}},
+
+	{Handlers.StartUDPClient, openapi.Route{Method: "POST", Path: "/client/start", Tag: "client", Summary: "Start a UDP client", Request: StartUDPClientRequest{}, OptionalBody: true, Response: UDPClientResponse{}}},
+	{Handlers.StopUDPClient, openapi.Route{Method: "POST", Path: "/client/stop", Tag: "client", Summary: "Stop a UDP client", Query: []openapi.Param{nameParam}, Response: ApiSuccess{}}},
+	{Handlers.SendDatagram, openapi.Route{Method: "POST", Path: "/client/send", Tag: "client", Summary: "Send a datagram", Request: SendDatagramRequest{}, Response: SendDatagramResponse{}}},
+	{Handlers.GetUDPClientStateByName, openapi.Route{Method: "GET", Path: "/client/get/name", Tag: "client", Summary: "Client state by name", Query: []openapi.Param{nameParam}, Response: UDPClientStateResponse{}}},
+	{Handlers.GetUDPClientStateById, openapi.Route{Method: "GET", Path: "/client/get/id", Tag: "client", Summary: "Client state by ID", Query: []openapi.Param{
+		idParam,
+		{Name: "datagrams", Type: true, Description: "false omits the datagram history"},
+	}, Response: UDPClientStateResponse{}}},
+	{Handlers.GetAllUDPClients, openapi.Route{Method: "GET", Path: "/client/get/all", Tag: "client", Summary: "All clients", Response: AllUDPClientResponse{}}},
+	{Handlers.GetAllUDPClientPaginated, openapi.Route{Method: "GET", Path: "/client/get/all/paginated", Tag: "client", Summary: "Sorted, filtered and paged client list", Query: []openapi.Param{
+		{Name: "page", Type: 0},
+		{Name: "pageSize", Type: 0},
+		{Name: "cursor", Description: "nextCursor of the previous page"},
+		{Name: "q", Description: "Search in name, group and tags"},
+		tagParam,
+		{Name: "sort", Description: "id, name, created or activity"},
+		{Name: "order", Description: "asc or desc"},
+		{Name: "running", Type: true},
+		{Name: "activeWithin", Type: 0, Description: "Seconds since the last activity"},
+		{Name: "minDatagrams", Type: 0},
+		{Name: "maxDatagrams", Type: 0},
+	}, Response: UDPClientPaginatedRespone{}}},
+	{Handlers.GetClientDatagrams, openapi.Route{Method: "GET", Path: "/client/datagrams", Tag: "client", Summary: "Datagrams of a client by sequence number", Query: []openapi.Param{
+		idParam,
+		{Name: "since", Type: 0, Description: "Only datagrams after this sequence number"},
+		{Name: "limit", Type: 0},
+		{Name: "reverse", Type: true, Description: "Newest first"},
+	}, Response: DatagramPageResponse{}}},
+	{Handlers.GetClientMap, openapi.Route{Method: "GET", Path: "/client/map", Tag: "client", Summary: "Clients and their connections", Query: []openapi.Param{tagParam}, Response: ClientMapResponse{}}},
+	{Handlers.SetClientLabels, openapi.Route{Method: "POST", Path: "/client/labels", Tag: "client", Summary: "Set group and tags of a client", Request: SetClientLabelsRequest{}, Response: UDPClientListItem{}}},
+	{Handlers.SetNameGenerator, openapi.Route{Method: "POST", Path: "/client/names", Tag: "client", Summary: "Reseed the client name generator", Request: NameGeneratorRequest{}, Response: ApiSuccess{}}},
+
+	{Handlers.StartSendJob, openapi.Route{Method: "POST", Path: "/client/jobs/start", Tag: "jobs", Summary: "Start a periodic send job", Request: StartSendJobRequest{}, Response: SendJob{}}},
+	{Handlers.GetAllSendJobs, openapi.Route{Method: "GET", Path: "/client/jobs/all", Tag: "jobs", Summary: "All send jobs", Query: []openapi.Param{{Name: "clientId", Type: 0}}, Response: AllSendJobsResponse{}}},
+	{Handlers.PauseSendJob, openapi.Route{Method: "POST", Path: "/client/jobs/pause", Tag: "jobs", Summary: "Pause a send job", Query: []openapi.Param{jobIDParam}, Response: SendJob{}}},
+	{Handlers.ResumeSendJob, openapi.Route{Method: "POST", Path: "/client/jobs/resume", Tag: "jobs", Summary: "Resume a send job", Query: []openapi.Param{jobIDParam}, Response: SendJob{}}},
+	{Handlers.CancelSendJob, openapi.Route{Method: "POST", Path: "/client/jobs/cancel", Tag: "jobs", Summary: "Cancel a send job", Query: []openapi.Param{jobIDParam}, Response: SendJob{}}},
+
+	{Handlers.GetAllGroups, openapi.Route{Method: "GET", Path: "/groups/all", Tag: "groups", Summary: "All client groups", Response: AllGroupsResponse{}}},
+	{Handlers.StartGroup, openapi.Route{Method: "POST", Path: "/groups/start", Tag: "groups", Summary: "Start clients in a group", Request: StartGroupRequest{}, Response: GroupActionResponse{}}},
+	{Handlers.StopGroup, openapi.Route{Method: "POST", Path: "/groups/stop", Tag: "groups", Summary: "Stop the clients of a group", Query: []openapi.Param{groupParam}, Response: GroupActionResponse{}}},
+	{Handlers.SendGroup, openapi.Route{Method: "POST", Path: "/groups/send", Tag: "groups", Summary: "Send a datagram from every client of a group", Request: SendGroupRequest{}, Response: GroupActionResponse{}}},
+	{Handlers.ClearGroup, openapi.Route{Method: "POST", Path: "/groups/clear", Tag: "groups", Summary: "Clear the datagrams of a group", Query: []openapi.Param{groupParam}, Response: GroupActionResponse{}}},
+
+	{Handlers.GetTraces, openapi.Route{Method: "GET", Path: "/traces/all", Tag: "traces", Summary: "Mermaid sequence diagrams of the traces", Query: []openapi.Param{{Name: "name", Description: "Only this client"}}, Response: MermaidResponse{}}},
+
+	{Handlers.StartFuzz, openapi.Route{Method: "POST", Path: "/fuzz/start", Tag: "fuzz", Summary: "Start a fuzz run", Request: StartFuzzRequest{}, Response: ApiSuccess{}}},
+	{Handlers.StopFuzz, openapi.Route{Method: "POST", Path: "/fuzz/stop", Tag: "fuzz", Summary: "Stop the fuzz run", Response: ApiSuccess{}}},
+	{Handlers.GetFuzzSummary, openapi.Route{Method: "GET", Path: "/fuzz/get", Tag: "fuzz", Summary: "Summary of the last fuzz run", Response: FuzzSummaryResponse{}}},
+
+	{Handlers.GetAllTemplates, openapi.Route{Method: "GET", Path: "/templates/all", Tag: "templates", Summary: "All payload templates", Response: AllTemplatesResponse{}}},
+	{Handlers.GetTemplate, openapi.Route{Method: "GET", Path: "/templates/get", Tag: "templates", Summary: "A payload template", Query: []openapi.Param{templateParam}, Response: TemplateResponse{}}},
+	{Handlers.SaveTemplate, openapi.Route{Method: "POST", Path: "/templates/save", Tag: "templates", Summary: "Save a payload template", Request: payload.Template{}, Response: ApiSuccess{}}},
+	{Handlers.DeleteTemplate, openapi.Route{Method: "POST", Path: "/templates/delete", Tag: "templates", Summary: "Delete a payload template", Query: []openapi.Param{templateParam}, Response: ApiSuccess{}}},
+
+	{Handlers.ResetWorkspace, openapi.Route{Method: "POST", Path: "/workspace/reset", Tag: "workspace", Summary: "Reset the workspace", Request: WorkspaceResetRequest{}, Response: WorkspaceResetResponse{}}},
+
+	{Handlers.GetLogs, openapi.Route{Method: "GET", Path: "/logs", Tag: "logs", Summary: "Stored log entries", Query: []openapi.Param{
+		{Name: "level", Description: "Only entries at least this severe"},
+		{Name: "caller"},
+		{Name: "clientId", Type: 0},
+		{Name: "since", Description: "RFC 3339 time"},
+		{Name: "until", Description: "RFC 3339 time"},
+		{Name: "q", Description: "Search in message and fields"},
+		{Name: "limit", Type: 0},
+		{Name: "format", Description: "json or ndjson, which downloads one entry per line"},
+	}, Response: LogsResponse{}}},
+	{Handlers.GetLogLevels, openapi.Route{Method: "GET", Path: "/logs/levels", Tag: "logs", Summary: "Configured and effective log levels", Response: LogLevelsResponse{}}},
+	{Handlers.SetLogLevel, openapi.Route{Method: "POST", Path: "/logs/levels", Tag: "logs", Summary: "Change a log level", Request: SetLogLevelRequest{}, Response: LogLevelsResponse{}}},
+
+	{Handlers.StreamLogs, openapi.Route{Method: "GET", Path: "/stream/logs", Tag: "streams", Summary: "Log entries as Server-Sent Events", Query: []openapi.Param{filterParam, lastEventID}, ContentType: "text/event-stream"}},
+	{Handlers.StreamPackets, openapi.Route{Method: "GET", Path: "/stream/packets", Tag: "streams", Summary: "Packet events as Server-Sent Events", Query: []openapi.Param{filterParam, lastEventID, {Name: "client", Type: 0}}, ContentType: "text/event-stream"}},
+	{Handlers.StreamState, openapi.Route{Method: "GET", Path: "/stream/state", Tag: "streams", Summary: "State changes as Server-Sent Events", Query: []openapi.Param{filterParam, lastEventID}, ContentType: "text/event-stream"}},
+
+	{Handlers.GetDiagnostics, openapi.Route{Method: "GET", Path: "/diagnostics", Tag: "meta", Summary: "Goroutines, channel backlogs, lock times, connections and memory", Response: DiagnosticsResponse{}}},
+}
+
+func init() {
+	// Added here, because the document depends on endpoints itself
+	endpoints = append(endpoints, endpoint{
+		func(_ Handlers, w http.ResponseWriter, r *http.Request) { ServeOpenAPI(w, r) },
+		openapi.Route{Method: "GET", Path: "/openapi.json", Tag: "meta", Summary: "This document", Response: map[string]any{}},
+	})
+}
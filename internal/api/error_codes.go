@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/auraspeak/debug-ui/internal/middleware"
+)
+
+// ErrorCode is the machine-readable kind of an ApiError. Unlike the
+// message it doesn't change, so clients can branch on it.
+type ErrorCode string
+
+const (
+	// Request errors
+	ErrInvalidBody      ErrorCode = "invalid_body"
+	ErrInvalidParameter ErrorCode = "invalid_parameter"
+	ErrMissingParameter ErrorCode = "missing_parameter"
+	ErrInvalidPayload   ErrorCode = "invalid_payload"
+
+	// UDP server and clients
+	ErrServerNotRunning  ErrorCode = "server_not_running"
+	ErrServerStartFailed ErrorCode = "server_start_failed"
+	ErrClientNotFound    ErrorCode = "client_not_found"
+	ErrClientNotRunning  ErrorCode = "client_not_running"
+	ErrInvalidClientName ErrorCode = "invalid_client_name"
+	ErrClientNameTaken   ErrorCode = "client_name_taken"
+	ErrSendFailed        ErrorCode = "send_failed"
+	ErrGroupNotFound     ErrorCode = "group_not_found"
+
+	// Send jobs
+	ErrInvalidSendJob  ErrorCode = "invalid_send_job"
+	ErrSendJobNotFound ErrorCode = "send_job_not_found"
+	ErrSendJobFinished ErrorCode = "send_job_finished"
+
+	// Templates
+	ErrInvalidTemplate  ErrorCode = "invalid_template"
+	ErrTemplateNotFound ErrorCode = "template_not_found"
+
+	// Fuzzing
+	ErrFuzzRunning    ErrorCode = "fuzz_running"
+	ErrFuzzNotRunning ErrorCode = "fuzz_not_running"
+	ErrInvalidCorpus  ErrorCode = "invalid_corpus"
+	ErrNoFuzzSeeds    ErrorCode = "no_fuzz_seeds"
+
+	// Authentication and CORS, written by the middleware
+	ErrUnauthorized     ErrorCode = middleware.CodeUnauthorized
+	ErrForbidden        ErrorCode = middleware.CodeForbidden
+	ErrOriginNotAllowed ErrorCode = middleware.CodeOriginNotAllowed
+
+	// Generic codes, also used for errors without a specific code
+	ErrNotFound    ErrorCode = "not_found"
+	ErrConflict    ErrorCode = "conflict"
+	ErrUnavailable ErrorCode = "unavailable"
+	ErrInternal    ErrorCode = "internal"
+)
+
+// errorCodeFor is the code of an ApiError without one
+func errorCodeFor(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrInvalidParameter
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusServiceUnavailable:
+		return ErrUnavailable
+	default:
+		return ErrInternal
+	}
+}
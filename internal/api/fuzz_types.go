@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/auraspeak/debug-ui/internal/fuzz"
+)
+
+// StartFuzzRequest is the body of POST /api/fuzz/start. Seeds are the datagrams
+// the client sent so far plus the files in CorpusDir, zero values use the
+// fuzzer defaults. CorpusDir and CrashDir are relative to the fuzz directory
+// of the server, absolute paths and paths leaving it are rejected.
+type StartFuzzRequest struct {
+	ClientID       int    `json:"clientId"`
+	CorpusDir      string `json:"corpusDir,omitempty"`
+	CrashDir       string `json:"crashDir,omitempty"`
+	Iterations     int    `json:"iterations,omitempty"`
+	Seed           int64  `json:"seed,omitempty"`
+	MaxMutations   int    `json:"maxMutations,omitempty"`
+	ProbeEvery     int    `json:"probeEvery,omitempty"`
+	ProbeTimeoutMs int    `json:"probeTimeoutMs,omitempty"`
+	MinimizeBudget int    `json:"minimizeBudget,omitempty"`
+}
+
+// FuzzSummaryResponse is the response for GET /api/fuzz/get
+type FuzzSummaryResponse struct {
+	fuzz.Summary
+}
+
+func (f *FuzzSummaryResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, f)
+}
@@ -0,0 +1,60 @@
+package api
+
+import "net/http"
+
+// SetClientLabelsRequest is the body of POST /api/client/labels. Group and
+// Tags replace the current labels of the client, an empty Group removes it
+// from its group.
+type SetClientLabelsRequest struct {
+	Id    int      `json:"id"`
+	Group string   `json:"group"`
+	Tags  []string `json:"tags"`
+}
+
+// StartGroupRequest is the body of POST /api/groups/start
+type StartGroupRequest struct {
+	Group string   `json:"group"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// SendGroupRequest is the body of POST /api/groups/send. Request is sent from
+// every member of Group, its Id is ignored.
+type SendGroupRequest struct {
+	Group   string              `json:"group"`
+	Request SendDatagramRequest `json:"request"`
+}
+
+// GroupMemberResult is the outcome of a group-wide action for one member.
+// Clients a group start couldn't start have Id -1 and the Error.
+type GroupMemberResult struct {
+	Id    int    `json:"id"`
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+// GroupActionResponse is the response of the group-wide actions
+type GroupActionResponse struct {
+	Group   string              `json:"group"`
+	Results []GroupMemberResult `json:"results"`
+}
+
+func (g *GroupActionResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, g)
+}
+
+// ClientGroup lists the members of a group
+type ClientGroup struct {
+	Name      string `json:"name"`
+	ClientIDs []int  `json:"clientIds"`
+	Running   int    `json:"running"`
+}
+
+// AllGroupsResponse is the response for GET /api/groups/all
+type AllGroupsResponse struct {
+	Groups []ClientGroup `json:"groups"`
+}
+
+func (a *AllGroupsResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, a)
+}
@@ -0,0 +1,99 @@
+package api
+
+import "net/http"
+
+// Handlers serve the routes of RegisterRoutes. app.Server implements them;
+// the interfaces keep this package free of an import of app.
+type Handlers interface {
+	WebSocketHandlers
+	UDPServerHandlers
+	UDPClientHandlers
+	SendJobHandlers
+	GroupHandlers
+	TraceHandlers
+	FuzzHandlers
+	TemplateHandlers
+	WorkspaceHandlers
+	LogHandlers
+	StreamHandlers
+	DiagnosticsHandlers
+}
+
+type WebSocketHandlers interface {
+	ServeWS(w http.ResponseWriter, r *http.Request)
+}
+
+type UDPServerHandlers interface {
+	StartUDPServer(w http.ResponseWriter, r *http.Request)
+	StopUDPServer(w http.ResponseWriter, r *http.Request)
+	GetUDPServerState(w http.ResponseWriter, r *http.Request)
+}
+
+type UDPClientHandlers interface {
+	StartUDPClient(w http.ResponseWriter, r *http.Request)
+	StopUDPClient(w http.ResponseWriter, r *http.Request)
+	SendDatagram(w http.ResponseWriter, r *http.Request)
+	GetUDPClientStateByName(w http.ResponseWriter, r *http.Request)
+	GetUDPClientStateById(w http.ResponseWriter, r *http.Request)
+	GetAllUDPClients(w http.ResponseWriter, r *http.Request)
+	GetAllUDPClientPaginated(w http.ResponseWriter, r *http.Request)
+	GetClientDatagrams(w http.ResponseWriter, r *http.Request)
+	GetClientMap(w http.ResponseWriter, r *http.Request)
+	SetClientLabels(w http.ResponseWriter, r *http.Request)
+	SetNameGenerator(w http.ResponseWriter, r *http.Request)
+}
+
+type SendJobHandlers interface {
+	StartSendJob(w http.ResponseWriter, r *http.Request)
+	GetAllSendJobs(w http.ResponseWriter, r *http.Request)
+	PauseSendJob(w http.ResponseWriter, r *http.Request)
+	ResumeSendJob(w http.ResponseWriter, r *http.Request)
+	CancelSendJob(w http.ResponseWriter, r *http.Request)
+}
+
+type GroupHandlers interface {
+	GetAllGroups(w http.ResponseWriter, r *http.Request)
+	StartGroup(w http.ResponseWriter, r *http.Request)
+	StopGroup(w http.ResponseWriter, r *http.Request)
+	SendGroup(w http.ResponseWriter, r *http.Request)
+	ClearGroup(w http.ResponseWriter, r *http.Request)
+}
+
+type TraceHandlers interface {
+	GetTraces(w http.ResponseWriter, r *http.Request)
+}
+
+type FuzzHandlers interface {
+	StartFuzz(w http.ResponseWriter, r *http.Request)
+	StopFuzz(w http.ResponseWriter, r *http.Request)
+	GetFuzzSummary(w http.ResponseWriter, r *http.Request)
+}
+
+type TemplateHandlers interface {
+	GetAllTemplates(w http.ResponseWriter, r *http.Request)
+	GetTemplate(w http.ResponseWriter, r *http.Request)
+	SaveTemplate(w http.ResponseWriter, r *http.Request)
+	DeleteTemplate(w http.ResponseWriter, r *http.Request)
+}
+
+type WorkspaceHandlers interface {
+	ResetWorkspace(w http.ResponseWriter, r *http.Request)
+}
+
+type LogHandlers interface {
+	GetLogs(w http.ResponseWriter, r *http.Request)
+	GetLogLevels(w http.ResponseWriter, r *http.Request)
+	SetLogLevel(w http.ResponseWriter, r *http.Request)
+}
+
+type StreamHandlers interface {
+	StreamLogs(w http.ResponseWriter, r *http.Request)
+	StreamPackets(w http.ResponseWriter, r *http.Request)
+	StreamState(w http.ResponseWriter, r *http.Request)
+}
+
+type DiagnosticsHandlers interface {
+	Healthz(w http.ResponseWriter, r *http.Request)
+	Readyz(w http.ResponseWriter, r *http.Request)
+	GetDiagnostics(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/auraspeak/debug-ui/internal/logstore"
+)
+
+// LogsResponse is the response for GET /api/logs
+type LogsResponse struct {
+	Entries []logstore.Entry `json:"entries"`
+	// Stored is the number of entries in the store, before filtering
+	Stored int `json:"stored"`
+}
+
+func (l *LogsResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, l)
+}
+
+// SetLogLevelRequest is the body of POST /api/logs/levels. Without caller
+// and client it sets the global level. An empty level or "reset" removes
+// the level of a caller or client, so the next less specific one applies.
+type SetLogLevelRequest struct {
+	Caller   string `json:"caller,omitempty"`
+	ClientID *int   `json:"clientId,omitempty"`
+	Level    string `json:"level"`
+}
+
+// EffectiveLogLevel is the level that applies to a caller or a client
+type EffectiveLogLevel struct {
+	Caller   string `json:"caller"`
+	ClientID *int   `json:"clientId,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Level    string `json:"level"`
+	// Override is set if the level is configured for exactly this caller or client
+	Override bool `json:"override"`
+}
+
+// LogLevelsResponse is the response for GET and POST /api/logs/levels
+type LogLevelsResponse struct {
+	Global    string              `json:"global"`
+	Callers   map[string]string   `json:"callers"`
+	Clients   map[int]string      `json:"clients"`
+	Effective []EffectiveLogLevel `json:"effective"`
+}
+
+func (l *LogLevelsResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, l)
+}
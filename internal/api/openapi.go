@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/auraspeak/debug-ui/internal/openapi"
+	log "github.com/sirupsen/logrus"
+)
+
+// OpenAPI returns the OpenAPI document of the REST API
+var OpenAPI = sync.OnceValue(func() *openapi.Document {
+	builder := openapi.New(openapi.Info{
+		Title:       "debug-ui",
+		Version:     "1.0.0",
+		Description: "Drives a DTLS/UDP server and its clients. Live updates come over the /ws WebSocket.",
+	}, ApiError{})
+	builder.AddResponseField("requestId", &openapi.Schema{Type: "string", Description: "ID of the request, as in the X-Request-ID header"})
+	for _, version := range apiVersions {
+		for _, e := range endpoints {
+			route := e.Route
+			route.Path = version.BasePath + route.Path
+			route.Deprecated = version.Deprecated
+			builder.Add(route)
+		}
+	}
+	builder.Add(versionsRoute)
+	builder.Add(healthzRoute)
+	builder.Add(readyzRoute)
+	return builder.Document()
+})
+
+// ServeOpenAPI serves the OpenAPI document
+func ServeOpenAPI(w http.ResponseWriter, r *http.Request) {
+	b, err := json.Marshal(OpenAPI())
+	if err != nil {
+		log.WithField("caller", "web").WithError(err).Error("Can't marshal OpenAPI document to json")
+		apiError := ApiError{
+			Code:      http.StatusInternalServerError,
+			ErrorCode: ErrInternal,
+			Message:   "Can't create the OpenAPI document",
+		}
+		apiError.Send(w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+	w.Write([]byte("\n"))
+}
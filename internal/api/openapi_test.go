@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allRoutes registers every route with mock handlers
+func allRoutes() *Router {
+	return RegisterRoutes(&mockHandlers{})
+}
+
+func TestOpenAPI_CoversRoutes(t *testing.T) {
+	doc := OpenAPI()
+	registered := map[string]bool{}
+	for _, pattern := range allRoutes().Patterns() {
+		method, path, found := strings.Cut(pattern, " ")
+		if !found {
+			// /ws and the UI files are not part of the REST API
+			continue
+		}
+		registered[method+" "+path] = true
+		assert.True(t, doc.Has(method, path), "%s is registered but missing from the OpenAPI document", pattern)
+	}
+	for _, op := range doc.Operations() {
+		assert.True(t, registered[op], "%s is in the OpenAPI document but not registered", op)
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	ServeOpenAPI(rr, httptest.NewRequest("GET", "/api/v1/openapi.json", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	send := schemas["SendDatagramRequest"].(map[string]any)
+	properties := send["properties"].(map[string]any)
+	assert.Contains(t, properties, "message")
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/HeaderOverride"}, properties["header"])
+	assert.Contains(t, schemas, "ApiError")
+
+	paths := doc["paths"].(map[string]any)
+	current := paths["/api/v1/server/get"].(map[string]any)["get"].(map[string]any)
+	legacy := paths["/api/server/get"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, "getServerGet", current["operationId"])
+	assert.NotContains(t, current, "deprecated")
+	assert.Equal(t, "getServerGetDeprecated", legacy["operationId"])
+	assert.Equal(t, true, legacy["deprecated"])
+
+	// Success bodies carry the request ID besides the fields of their type
+	success := current["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	allOf := success["allOf"].([]any)
+	require.Len(t, allOf, 2)
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/ServerStateResponse"}, allOf[0])
+	assert.Contains(t, allOf[1].(map[string]any)["properties"], "requestId")
+}
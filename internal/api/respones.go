@@ -3,7 +3,10 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"slices"
+	"time"
 
+	"github.com/auraspeak/debug-ui/internal/middleware"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -12,12 +15,31 @@ type ApiResponse interface {
 }
 
 type ApiError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message,omitempty"`
-	Details string `json:"details,omitempty"`
+	// Code is the HTTP status
+	Code      int       `json:"code"`
+	ErrorCode ErrorCode `json:"errorCode"`
+	Message   string    `json:"message,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	// RequestID is filled in by Send from the response header
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// Error makes ApiError usable as error, e.g. by code shared between handlers and background jobs
+func (e *ApiError) Error() string {
+	if e.Details != "" {
+		return e.Message + ": " + e.Details
+	}
+	return e.Message
 }
 
 func (e *ApiError) Send(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if e.ErrorCode == "" {
+		e.ErrorCode = errorCodeFor(e.Code)
+	}
+	if e.RequestID == "" {
+		e.RequestID = w.Header().Get(middleware.RequestIDHeader)
+	}
 	w.WriteHeader(e.Code)
 	b, err := json.Marshal(e)
 	if err != nil {
@@ -27,19 +49,41 @@ func (e *ApiError) Send(w http.ResponseWriter) {
 	w.Write([]byte("\n"))
 }
 
+// writeJSON writes v as JSON body with the status code. Objects get the
+// request ID of the response header as requestId, like ApiError.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.WithField("caller", "web").WithError(err).Errorf("Can't marshal %T to json", v)
+	}
+	w.Write(withRequestID(b, w.Header().Get(middleware.RequestIDHeader)))
+	w.Write([]byte("\n"))
+}
+
+// withRequestID adds the requestId field to the JSON object b
+func withRequestID(b []byte, id string) []byte {
+	if id == "" || len(b) < 2 || b[0] != '{' || b[len(b)-1] != '}' {
+		return b
+	}
+	field, _ := json.Marshal(id)
+	out := slices.Clip(b[:len(b)-1])
+	if len(b) > 2 {
+		out = append(out, ',')
+	}
+	out = append(out, `"requestId":`...)
+	out = append(out, field...)
+	return append(out, '}')
+}
+
 type ApiSuccess struct {
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
 }
 
 func (s *ApiSuccess) Send(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	b, err := json.Marshal(s)
-	if err != nil {
-		log.WithField("caller", "web").WithError(err).Error("Can't marshal ApiSuccess to json")
-	}
-	w.Write(b)
-	w.Write([]byte("\n"))
+	writeJSON(w, http.StatusOK, s)
 }
 
 type ServerStateResponse struct {
@@ -48,13 +92,22 @@ type ServerStateResponse struct {
 }
 
 func (s *ServerStateResponse) Send(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	b, err := json.Marshal(s)
-	if err != nil {
-		log.WithField("caller", "web").WithError(err).Error("Can't marshal ServerStateResponse to json")
-	}
-	w.Write(b)
-	w.Write([]byte("\n"))
+	writeJSON(w, http.StatusOK, s)
+}
+
+// StartUDPClientRequest is the optional body of POST /api/client/start. Without
+// Name the client gets a generated one.
+type StartUDPClientRequest struct {
+	Name  string   `json:"name,omitempty"`
+	Group string   `json:"group,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// NameGeneratorRequest is the body of POST /api/client/names. The same Seed
+// produces the same client names in the same order, 0 picks a random seed.
+type NameGeneratorRequest struct {
+	Seed      int64 `json:"seed"`
+	FullNames bool  `json:"fullNames"`
 }
 
 type UDPClientResponse struct {
@@ -63,29 +116,19 @@ type UDPClientResponse struct {
 }
 
 func (s *UDPClientResponse) Send(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	b, err := json.Marshal(s)
-	if err != nil {
-		log.WithField("caller", "web").WithError(err).Error("Can't marshal UDPClientResponse to json")
-	}
-	w.Write(b)
-	w.Write([]byte("\n"))
+	writeJSON(w, http.StatusOK, s)
 }
 
 type UDPClientStateResponse struct {
-	Id        int        `json:"id"`
-	Running   bool       `json:"running"`
-	Datagrams []Datagram `json:"datagrams"`
+	Id            int        `json:"id"`
+	Running       bool       `json:"running"`
+	Datagrams     []Datagram `json:"datagrams"`
+	DatagramCount int        `json:"datagramCount"`
+	LastSeq       int        `json:"lastSeq"`
 }
 
 func (s *UDPClientStateResponse) Send(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	b, err := json.Marshal(s)
-	if err != nil {
-		log.WithField("caller", "web").WithError(err).Error("Can't marshal UDPClientStateResponse to json")
-	}
-	w.Write(b)
-	w.Write([]byte("\n"))
+	writeJSON(w, http.StatusOK, s)
 }
 
 type AllUDPClientResponse struct {
@@ -93,41 +136,59 @@ type AllUDPClientResponse struct {
 }
 
 func (a *AllUDPClientResponse) Send(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	b, err := json.Marshal(a)
-	if err != nil {
-		log.WithField("caller", "web").WithError(err).Error("Can't marshal AllUDPClientResponse to json")
-	}
-	w.Write(b)
-	w.Write([]byte("\n"))
+	writeJSON(w, http.StatusOK, a)
 }
 
 type UDPClientListItem struct {
-	Id   int    `json:"id"`
-	Name string `json:"name"`
+	Id            int       `json:"id"`
+	Name          string    `json:"name"`
+	Group         string    `json:"group,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	Running       bool      `json:"running"`
+	DatagramCount int       `json:"datagramCount"`
+	CreatedAt     time.Time `json:"createdAt,omitzero"`
+	LastActivity  time.Time `json:"lastActivity,omitzero"`
+}
+
+func (u *UDPClientListItem) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, u)
 }
 
 type UDPClientPaginatedRespone struct {
 	Items    []UDPClientListItem `json:"items"`
-	Page     int                 `json:"page"`
+	Page     int                 `json:"page"` // 0 when paging by cursor
 	PageSize int                 `json:"pageSize"`
 	Total    int                 `json:"total"`
+	// NextCursor continues after the last item, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 func (p *UDPClientPaginatedRespone) Send(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	b, err := json.Marshal(p)
-	if err != nil {
-		log.WithField("caller", "web").WithError(err).Error("Can't marshal PaginatedResponse to json")
-	}
-	w.Write(b)
-	w.Write([]byte("\n"))
+	writeJSON(w, http.StatusOK, p)
 }
 
 type SendDatagramRequest struct {
 	Id      int    `json:"id"`
 	Message string `json:"message"`
 	Format  string `json:"format"` // "hex" or "text"
+	// Header overrides single fields of the protocol header, nil keeps the default.
+	Header *HeaderOverride `json:"header,omitempty"`
+	// Raw sends the message bytes untouched, without a protocol header.
+	Raw bool `json:"raw,omitempty"`
+	// Template names a stored payload template used instead of Message and Format.
+	Template string `json:"template,omitempty"`
+	// Variables override template variables by name.
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// HeaderOverride replaces fields of the protocol header that SendDatagram would
+// otherwise fill in (PacketTypeDebugAny, Magic, Version and the payload length).
+// Values that don't fit into the header field are rejected.
+type HeaderOverride struct {
+	PacketType *uint64 `json:"packetType,omitempty"`
+	Magic      *uint64 `json:"magic,omitempty"`
+	Version    *uint64 `json:"version,omitempty"`
+	Length     *uint64 `json:"length,omitempty"`
 }
 
 type SendDatagramResponse struct {
@@ -135,13 +196,7 @@ type SendDatagramResponse struct {
 }
 
 func (s *SendDatagramResponse) Send(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	b, err := json.Marshal(s)
-	if err != nil {
-		log.WithField("caller", "web").WithError(err).Error("Can't marshal SendDatagramResponse to json")
-	}
-	w.Write(b)
-	w.Write([]byte("\n"))
+	writeJSON(w, http.StatusOK, s)
 }
 
 type MermaidResponse struct {
@@ -150,13 +205,7 @@ type MermaidResponse struct {
 }
 
 func (m *MermaidResponse) Send(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	b, err := json.Marshal(m)
-	if err != nil {
-		log.WithField("caller", "web").WithError(err).Error("Can't marshal MermaidDiagram to json")
-	}
-	w.Write(b)
-	w.Write([]byte("\n"))
+	writeJSON(w, http.StatusOK, m)
 }
 
 // ClientMapConnection represents an edge in the client map (from client to server or client to client).
@@ -172,11 +221,5 @@ type ClientMapResponse struct {
 }
 
 func (c *ClientMapResponse) Send(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	b, err := json.Marshal(c)
-	if err != nil {
-		log.WithField("caller", "web").WithError(err).Error("Can't marshal ClientMapResponse to json")
-	}
-	w.Write(b)
-	w.Write([]byte("\n"))
+	writeJSON(w, http.StatusOK, c)
 }
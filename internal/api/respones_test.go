@@ -31,6 +31,33 @@ func TestApiError_Send(t *testing.T) {
 	assert.Equal(t, apiError.Details, response.Details)
 }
 
+func TestApiError_Send_CodeAndRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		err  ApiError
+		want ErrorCode
+	}{
+		{"explicit", ApiError{Code: http.StatusNotFound, ErrorCode: ErrClientNotFound}, ErrClientNotFound},
+		{"bad request", ApiError{Code: http.StatusBadRequest}, ErrInvalidParameter},
+		{"not found", ApiError{Code: http.StatusNotFound}, ErrNotFound},
+		{"server error", ApiError{Code: http.StatusInternalServerError}, ErrInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			rr.Header().Set("X-Request-ID", "abc")
+
+			tt.err.Send(rr)
+
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			var response ApiError
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tt.want, response.ErrorCode)
+			assert.Equal(t, "abc", response.RequestID)
+		})
+	}
+}
+
 func TestApiSuccess_Send(t *testing.T) {
 	apiSuccess := ApiSuccess{
 		Message: "Test success",
@@ -50,6 +77,34 @@ func TestApiSuccess_Send(t *testing.T) {
 	assert.Equal(t, apiSuccess.Details, response.Details)
 }
 
+func TestWriteJSON_RequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		v    any
+		want string
+	}{
+		{"object", "abc", &ApiSuccess{Message: "ok"}, `{"message":"ok","requestId":"abc"}`},
+		{"empty object", "abc", struct{}{}, `{"requestId":"abc"}`},
+		{"without ID", "", &ApiSuccess{Message: "ok"}, `{"message":"ok"}`},
+		{"no object", "abc", []int{1}, `[1]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			if tt.id != "" {
+				rr.Header().Set("X-Request-ID", tt.id)
+			}
+
+			writeJSON(rr, http.StatusOK, tt.v)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.want, rr.Body.String())
+		})
+	}
+}
+
 func TestServerStateResponse_Send(t *testing.T) {
 	response := ServerStateResponse{
 		ShouldStop: true,
@@ -183,3 +238,18 @@ func TestMermaidResponse_Send(t *testing.T) {
 	assert.Equal(t, response.Heading, result.Heading)
 	assert.Equal(t, response.Diagram, result.Diagram)
 }
+
+func TestHealthResponse_Send(t *testing.T) {
+	ok := NewHealthResponse([]HealthCheck{{Name: "http", OK: true}})
+	rr := httptest.NewRecorder()
+	ok.Send(rr)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"ok","checks":[{"name":"http","ok":true}]}`, rr.Body.String())
+
+	failed := NewHealthResponse([]HealthCheck{{Name: "http", OK: true}, {Name: "shutdown", Message: "shutting down"}})
+	rr = httptest.NewRecorder()
+	failed.Send(rr)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, HealthUnavailable, failed.Status)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+}
@@ -2,56 +2,106 @@ package api
 
 import (
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/auraspeak/debug-ui/internal/middleware"
+	"github.com/auraspeak/debug-ui/internal/openapi"
 )
 
-// RegisterRoutes creates an HTTP handler with all API routes
-// Handler functions are passed as parameters to avoid import cycles
-func RegisterRoutes(
-	handleWS http.HandlerFunc,
-	startUDPServer http.HandlerFunc,
-	stopUDPServer http.HandlerFunc,
-	getUDPServerState http.HandlerFunc,
-	startUDPClient http.HandlerFunc,
-	stopUDPClient http.HandlerFunc,
-	sendDatagram http.HandlerFunc,
-	getUDPClientStateByName http.HandlerFunc,
-	getUDPClientStateById http.HandlerFunc,
-	getAllUDPClients http.HandlerFunc,
-	getTraces http.HandlerFunc,
-	getAllUDPClientPaginated http.HandlerFunc,
-	getClientMap http.HandlerFunc,
-) http.Handler {
-	mux := http.NewServeMux()
-	mux.HandleFunc("/ws", handleWS)
+// Router is the mux of the debug UI. It remembers the registered patterns,
+// so tests can compare them with the OpenAPI document.
+type Router struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func (r *Router) Handle(pattern string, handler http.Handler) {
+	r.ServeMux.Handle(pattern, handler)
+	r.patterns = append(r.patterns, pattern)
+}
+
+func (r *Router) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	r.ServeMux.HandleFunc(pattern, handler)
+	r.patterns = append(r.patterns, pattern)
+}
+
+// Patterns returns the registered patterns in registration order
+func (r *Router) Patterns() []string {
+	return slices.Clone(r.patterns)
+}
+
+// CurrentVersion is the API version new clients should use
+const CurrentVersion = "v1"
+
+// apiVersions are the base paths the endpoints are served under. The
+// unversioned /api paths are kept for old clients.
+var apiVersions = []APIVersion{
+	{Version: CurrentVersion, BasePath: "/api/" + CurrentVersion},
+	{Version: "unversioned", BasePath: "/api", Deprecated: true, Successor: CurrentVersion},
+}
+
+var versionsRoute = openapi.Route{Method: "GET", Path: "/api/versions", Tag: "meta", Summary: "Supported API versions", Response: VersionsResponse{}}
+
+// Probes for load balancers and orchestrators. They are outside of /api, so
+// they need no token.
+var (
+	healthzRoute = openapi.Route{Method: "GET", Path: "/healthz", Tag: "meta", Summary: "Liveness probe, 503 when the server is stuck", Response: HealthResponse{}}
+	readyzRoute  = openapi.Route{Method: "GET", Path: "/readyz", Tag: "meta", Summary: "Readiness probe, 503 until the server serves and while it shuts down", Response: HealthResponse{}}
+)
+
+// RegisterRoutes creates an HTTP handler with all API routes, served by h
+func RegisterRoutes(h Handlers) *Router {
+	mux := &Router{ServeMux: http.NewServeMux()}
+	mux.HandleFunc("/ws", h.ServeWS)
 	mux.Handle("/", http.FileServer(http.Dir("./bin")))
 
-	// UDP Server handlers
-	mux.HandleFunc("POST /api/server/start", startUDPServer)
-	mux.HandleFunc("POST /api/server/stop", stopUDPServer)
-	mux.HandleFunc("GET /api/server/get", getUDPServerState)
-
-	mux.HandleFunc("POST /api/client/start", startUDPClient)
-	mux.HandleFunc("POST /api/client/stop", stopUDPClient)
-	mux.HandleFunc("POST /api/client/send", sendDatagram)
-	mux.HandleFunc("GET /api/client/get/name", getUDPClientStateByName)
-	mux.HandleFunc("GET /api/client/get/id", getUDPClientStateById)
-	mux.HandleFunc("GET /api/client/get/all", getAllUDPClients)
-	mux.HandleFunc("GET /api/client/map", getClientMap)
-
-	// Trace handlers
-	mux.HandleFunc("GET /api/traces/all", getTraces)
-	// Paginated all UDP clients
-	mux.HandleFunc("GET /api/client/get/all/paginated", getAllUDPClientPaginated)
-
-	// Wrap the entire mux with CORS for all /api/ routes
+	for _, version := range apiVersions {
+		for _, e := range endpoints {
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				e.handle(h, w, r)
+			})
+			if version.Deprecated {
+				handler = deprecated(handler, "/api/"+version.Successor+e.Path)
+			}
+			mux.Handle(e.Method+" "+version.BasePath+e.Path, handler)
+		}
+	}
+	mux.HandleFunc(versionsRoute.Method+" "+versionsRoute.Path, ServeVersions)
+	mux.HandleFunc(healthzRoute.Method+" "+healthzRoute.Path, h.Healthz)
+	mux.HandleFunc(readyzRoute.Method+" "+readyzRoute.Path, h.Readyz)
+
+	return mux
+}
+
+// deprecated marks the responses of handler as deprecated and links the successor path
+func deprecated(handler http.Handler, successor string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Only apply CORS to /api/ routes
-		if len(r.URL.Path) >= 4 && r.URL.Path[:4] == "/api" {
-			middleware.CorsWrapper(mux).ServeHTTP(w, r)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// ServeVersions lists the supported API versions
+func ServeVersions(w http.ResponseWriter, r *http.Request) {
+	response := VersionsResponse{
+		Current:  CurrentVersion,
+		Versions: apiVersions,
+	}
+	response.Send(w)
+}
+
+// Protect applies CORS and authentication to the /api/ routes and the
+// WebSocket. The UI files stay public, so the browser can load them. Every
+// request gets a request ID.
+func Protect(handler http.Handler, cors middleware.Cors, auth middleware.Auth) http.Handler {
+	protected := cors.Wrap(auth.Wrap(handler))
+	return middleware.WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ws" || strings.HasPrefix(r.URL.Path, "/api/") {
+			protected.ServeHTTP(w, r)
 		} else {
-			mux.ServeHTTP(w, r)
+			handler.ServeHTTP(w, r)
 		}
-	})
+	}))
 }
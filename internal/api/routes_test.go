@@ -1,82 +1,207 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/auraspeak/debug-ui/internal/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// mockHandlers records which handler served a request
+type mockHandlers struct {
+	called string
+}
+
+func (m *mockHandlers) serve(name string, w http.ResponseWriter) {
+	m.called = name
+	w.WriteHeader(http.StatusOK)
+}
+
+func (m *mockHandlers) ServeWS(w http.ResponseWriter, r *http.Request) {
+	m.serve("ServeWS", w)
+}
+
+func (m *mockHandlers) StartUDPServer(w http.ResponseWriter, r *http.Request) {
+	m.serve("StartUDPServer", w)
+}
+
+func (m *mockHandlers) StopUDPServer(w http.ResponseWriter, r *http.Request) {
+	m.serve("StopUDPServer", w)
+}
+
+func (m *mockHandlers) GetUDPServerState(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetUDPServerState", w)
+}
+
+func (m *mockHandlers) StartUDPClient(w http.ResponseWriter, r *http.Request) {
+	m.serve("StartUDPClient", w)
+}
+
+func (m *mockHandlers) StopUDPClient(w http.ResponseWriter, r *http.Request) {
+	m.serve("StopUDPClient", w)
+}
+
+func (m *mockHandlers) SendDatagram(w http.ResponseWriter, r *http.Request) {
+	m.serve("SendDatagram", w)
+}
+
+func (m *mockHandlers) GetUDPClientStateByName(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetUDPClientStateByName", w)
+}
+
+func (m *mockHandlers) GetUDPClientStateById(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetUDPClientStateById", w)
+}
+
+func (m *mockHandlers) GetAllUDPClients(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetAllUDPClients", w)
+}
+
+func (m *mockHandlers) GetAllUDPClientPaginated(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetAllUDPClientPaginated", w)
+}
+
+func (m *mockHandlers) GetClientDatagrams(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetClientDatagrams", w)
+}
+
+func (m *mockHandlers) GetClientMap(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetClientMap", w)
+}
+
+func (m *mockHandlers) SetClientLabels(w http.ResponseWriter, r *http.Request) {
+	m.serve("SetClientLabels", w)
+}
+
+func (m *mockHandlers) SetNameGenerator(w http.ResponseWriter, r *http.Request) {
+	m.serve("SetNameGenerator", w)
+}
+
+func (m *mockHandlers) StartSendJob(w http.ResponseWriter, r *http.Request) {
+	m.serve("StartSendJob", w)
+}
+
+func (m *mockHandlers) GetAllSendJobs(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetAllSendJobs", w)
+}
+
+func (m *mockHandlers) PauseSendJob(w http.ResponseWriter, r *http.Request) {
+	m.serve("PauseSendJob", w)
+}
+
+func (m *mockHandlers) ResumeSendJob(w http.ResponseWriter, r *http.Request) {
+	m.serve("ResumeSendJob", w)
+}
+
+func (m *mockHandlers) CancelSendJob(w http.ResponseWriter, r *http.Request) {
+	m.serve("CancelSendJob", w)
+}
+
+func (m *mockHandlers) GetAllGroups(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetAllGroups", w)
+}
+
+func (m *mockHandlers) StartGroup(w http.ResponseWriter, r *http.Request) {
+	m.serve("StartGroup", w)
+}
+
+func (m *mockHandlers) StopGroup(w http.ResponseWriter, r *http.Request) {
+	m.serve("StopGroup", w)
+}
+
+func (m *mockHandlers) SendGroup(w http.ResponseWriter, r *http.Request) {
+	m.serve("SendGroup", w)
+}
+
+func (m *mockHandlers) ClearGroup(w http.ResponseWriter, r *http.Request) {
+	m.serve("ClearGroup", w)
+}
+
+func (m *mockHandlers) GetTraces(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetTraces", w)
+}
+
+func (m *mockHandlers) StartFuzz(w http.ResponseWriter, r *http.Request) {
+	m.serve("StartFuzz", w)
+}
+
+func (m *mockHandlers) StopFuzz(w http.ResponseWriter, r *http.Request) {
+	m.serve("StopFuzz", w)
+}
+
+func (m *mockHandlers) GetFuzzSummary(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetFuzzSummary", w)
+}
+
+func (m *mockHandlers) GetAllTemplates(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetAllTemplates", w)
+}
+
+func (m *mockHandlers) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetTemplate", w)
+}
+
+func (m *mockHandlers) SaveTemplate(w http.ResponseWriter, r *http.Request) {
+	m.serve("SaveTemplate", w)
+}
+
+func (m *mockHandlers) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	m.serve("DeleteTemplate", w)
+}
+
+func (m *mockHandlers) ResetWorkspace(w http.ResponseWriter, r *http.Request) {
+	m.serve("ResetWorkspace", w)
+}
+
+func (m *mockHandlers) GetLogs(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetLogs", w)
+}
+
+func (m *mockHandlers) GetLogLevels(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetLogLevels", w)
+}
+
+func (m *mockHandlers) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	m.serve("SetLogLevel", w)
+}
+
+func (m *mockHandlers) StreamLogs(w http.ResponseWriter, r *http.Request) {
+	m.serve("StreamLogs", w)
+}
+
+func (m *mockHandlers) StreamPackets(w http.ResponseWriter, r *http.Request) {
+	m.serve("StreamPackets", w)
+}
+
+func (m *mockHandlers) StreamState(w http.ResponseWriter, r *http.Request) {
+	m.serve("StreamState", w)
+}
+
+func (m *mockHandlers) Healthz(w http.ResponseWriter, r *http.Request) {
+	m.serve("Healthz", w)
+}
+
+func (m *mockHandlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	m.serve("Readyz", w)
+}
+
+func (m *mockHandlers) GetDiagnostics(w http.ResponseWriter, r *http.Request) {
+	m.serve("GetDiagnostics", w)
+}
+
 func TestRegisterRoutes(t *testing.T) {
-	// Create mock handlers
-	mockWS := func(w http.ResponseWriter, r *http.Request) {}
-	mockStartUDPServer := func(w http.ResponseWriter, r *http.Request) {}
-	mockStopUDPServer := func(w http.ResponseWriter, r *http.Request) {}
-	mockGetUDPServerState := func(w http.ResponseWriter, r *http.Request) {}
-	mockStartUDPClient := func(w http.ResponseWriter, r *http.Request) {}
-	mockStopUDPClient := func(w http.ResponseWriter, r *http.Request) {}
-	mockSendDatagram := func(w http.ResponseWriter, r *http.Request) {}
-	mockGetUDPClientStateByName := func(w http.ResponseWriter, r *http.Request) {}
-	mockGetUDPClientStateById := func(w http.ResponseWriter, r *http.Request) {}
-	mockGetAllUDPClients := func(w http.ResponseWriter, r *http.Request) {}
-	mockGetTraces := func(w http.ResponseWriter, r *http.Request) {}
-	mockGetAllUDPClientPaginated := func(w http.ResponseWriter, r *http.Request) {}
-	mockGetClientMap := func(w http.ResponseWriter, r *http.Request) {}
-
-	handler := RegisterRoutes(
-		mockWS,
-		mockStartUDPServer,
-		mockStopUDPServer,
-		mockGetUDPServerState,
-		mockStartUDPClient,
-		mockStopUDPClient,
-		mockSendDatagram,
-		mockGetUDPClientStateByName,
-		mockGetUDPClientStateById,
-		mockGetAllUDPClients,
-		mockGetTraces,
-		mockGetAllUDPClientPaginated,
-		mockGetClientMap,
-	)
+	handler := RegisterRoutes(&mockHandlers{})
 
 	require.NotNil(t, handler)
 }
 
 func TestRegisterRoutes_APIEndpoints(t *testing.T) {
-	// Track which handlers were called
-	called := make(map[string]bool)
-
-	mockWS := func(w http.ResponseWriter, r *http.Request) { called["ws"] = true }
-	mockStartUDPServer := func(w http.ResponseWriter, r *http.Request) { called["startUDPServer"] = true }
-	mockStopUDPServer := func(w http.ResponseWriter, r *http.Request) { called["stopUDPServer"] = true }
-	mockGetUDPServerState := func(w http.ResponseWriter, r *http.Request) { called["getUDPServerState"] = true }
-	mockStartUDPClient := func(w http.ResponseWriter, r *http.Request) { called["startUDPClient"] = true }
-	mockStopUDPClient := func(w http.ResponseWriter, r *http.Request) { called["stopUDPClient"] = true }
-	mockSendDatagram := func(w http.ResponseWriter, r *http.Request) { called["sendDatagram"] = true }
-	mockGetUDPClientStateByName := func(w http.ResponseWriter, r *http.Request) { called["getUDPClientStateByName"] = true }
-	mockGetUDPClientStateById := func(w http.ResponseWriter, r *http.Request) { called["getUDPClientStateById"] = true }
-	mockGetAllUDPClients := func(w http.ResponseWriter, r *http.Request) { called["getAllUDPClients"] = true }
-	mockGetTraces := func(w http.ResponseWriter, r *http.Request) { called["getTraces"] = true }
-	mockGetAllUDPClientPaginated := func(w http.ResponseWriter, r *http.Request) { called["getAllUDPClientPaginated"] = true }
-	mockGetClientMap := func(w http.ResponseWriter, r *http.Request) { called["getClientMap"] = true }
-
-	handler := RegisterRoutes(
-		mockWS,
-		mockStartUDPServer,
-		mockStopUDPServer,
-		mockGetUDPServerState,
-		mockStartUDPClient,
-		mockStopUDPClient,
-		mockSendDatagram,
-		mockGetUDPClientStateByName,
-		mockGetUDPClientStateById,
-		mockGetAllUDPClients,
-		mockGetTraces,
-		mockGetAllUDPClientPaginated,
-		mockGetClientMap,
-	)
+	mock := &mockHandlers{}
+	handler := RegisterRoutes(mock)
 
 	// Test API routes
 	tests := []struct {
@@ -84,64 +209,224 @@ func TestRegisterRoutes_APIEndpoints(t *testing.T) {
 		path   string
 		key    string
 	}{
-		{"POST", "/api/server/start", "startUDPServer"},
-		{"POST", "/api/server/stop", "stopUDPServer"},
-		{"GET", "/api/server/get", "getUDPServerState"},
-		{"POST", "/api/client/start", "startUDPClient"},
-		{"POST", "/api/client/stop", "stopUDPClient"},
-		{"POST", "/api/client/send", "sendDatagram"},
-		{"GET", "/api/client/get/name", "getUDPClientStateByName"},
-		{"GET", "/api/client/get/id", "getUDPClientStateById"},
-		{"GET", "/api/client/get/all", "getAllUDPClients"},
-		{"GET", "/api/traces/all", "getTraces"},
-		{"GET", "/api/client/get/all/paginated", "getAllUDPClientPaginated"},
-		{"GET", "/api/client/map", "getClientMap"},
+		{"POST", "/server/start", "StartUDPServer"},
+		{"POST", "/server/stop", "StopUDPServer"},
+		{"GET", "/server/get", "GetUDPServerState"},
+		{"POST", "/client/start", "StartUDPClient"},
+		{"POST", "/client/stop", "StopUDPClient"},
+		{"POST", "/client/send", "SendDatagram"},
+		{"GET", "/client/get/name", "GetUDPClientStateByName"},
+		{"GET", "/client/get/id", "GetUDPClientStateById"},
+		{"GET", "/client/get/all", "GetAllUDPClients"},
+		{"GET", "/client/datagrams", "GetClientDatagrams"},
+		{"GET", "/traces/all", "GetTraces"},
+		{"GET", "/client/get/all/paginated", "GetAllUDPClientPaginated"},
+		{"GET", "/client/map", "GetClientMap"},
+		{"POST", "/client/labels", "SetClientLabels"},
+		{"POST", "/client/names", "SetNameGenerator"},
+		{"GET", "/groups/all", "GetAllGroups"},
+		{"POST", "/groups/start", "StartGroup"},
+		{"POST", "/groups/stop", "StopGroup"},
+		{"POST", "/groups/send", "SendGroup"},
+		{"POST", "/groups/clear", "ClearGroup"},
+		{"POST", "/client/jobs/start", "StartSendJob"},
+		{"GET", "/client/jobs/all", "GetAllSendJobs"},
+		{"POST", "/client/jobs/pause", "PauseSendJob"},
+		{"POST", "/client/jobs/resume", "ResumeSendJob"},
+		{"POST", "/client/jobs/cancel", "CancelSendJob"},
+		{"POST", "/fuzz/start", "StartFuzz"},
+		{"POST", "/fuzz/stop", "StopFuzz"},
+		{"GET", "/fuzz/get", "GetFuzzSummary"},
+		{"POST", "/workspace/reset", "ResetWorkspace"},
+		{"GET", "/logs", "GetLogs"},
+		{"GET", "/logs/levels", "GetLogLevels"},
+		{"POST", "/logs/levels", "SetLogLevel"},
+		{"GET", "/stream/logs", "StreamLogs"},
+		{"GET", "/stream/packets", "StreamPackets"},
+		{"GET", "/stream/state", "StreamState"},
+		{"GET", "/templates/all", "GetAllTemplates"},
+		{"GET", "/templates/get", "GetTemplate"},
+		{"POST", "/templates/save", "SaveTemplate"},
+		{"POST", "/templates/delete", "DeleteTemplate"},
+		{"GET", "/diagnostics", "GetDiagnostics"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.path, func(t *testing.T) {
-			called = make(map[string]bool)
-			req := httptest.NewRequest(tt.method, tt.path, nil)
+			mock.called = ""
+			req := httptest.NewRequest(tt.method, "/api/v1"+tt.path, nil)
 			rr := httptest.NewRecorder()
 
 			handler.ServeHTTP(rr, req)
 
-			// Check that the handler was called (or at least route exists)
-			// Note: CORS middleware might affect the response, but route should exist
-			assert.NotEqual(t, http.StatusNotFound, rr.Code, "Route should exist")
+			assert.Equal(t, tt.key, mock.called)
+			assert.Empty(t, rr.Header().Get("Deprecation"))
+
+			// The unversioned path is a deprecated alias
+			mock.called = ""
+			req = httptest.NewRequest(tt.method, "/api"+tt.path, nil)
+			rr = httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.key, mock.called)
+			assert.Equal(t, "true", rr.Header().Get("Deprecation"))
+			assert.Equal(t, `</api/v1`+tt.path+`>; rel="successor-version"`, rr.Header().Get("Link"))
 		})
 	}
 }
 
-func TestRegisterRoutes_CORS(t *testing.T) {
-	mockHandler := func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
+func TestRegisterRoutes_WebSocket(t *testing.T) {
+	mock := &mockHandlers{}
+	handler := RegisterRoutes(mock)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ws", nil))
+
+	assert.Equal(t, "ServeWS", mock.called)
+}
+
+func TestRegisterRoutes_Probes(t *testing.T) {
+	mock := &mockHandlers{}
+	handler := Protect(RegisterRoutes(mock), middleware.Cors{}, middleware.Auth{AdminTokens: []string{"admin-token"}})
+
+	for path, key := range map[string]string{"/healthz": "Healthz", "/readyz": "Readyz"} {
+		mock.called = ""
+		rr := httptest.NewRecorder()
+
+		// The probes need no token
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code, path)
+		assert.Equal(t, key, mock.called)
 	}
+}
 
-	handler := RegisterRoutes(
-		mockHandler,
-		mockHandler,
-		mockHandler,
-		mockHandler,
-		mockHandler,
-		mockHandler,
-		mockHandler,
-		mockHandler,
-		mockHandler,
-		mockHandler,
-		mockHandler,
-		mockHandler,
-		mockHandler,
-	)
+func TestServeVersions(t *testing.T) {
+	handler := RegisterRoutes(&mockHandlers{})
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/versions", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var versions VersionsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &versions))
+	assert.Equal(t, "v1", versions.Current)
+	assert.Equal(t, []APIVersion{
+		{Version: "v1", BasePath: "/api/v1"},
+		{Version: "unversioned", BasePath: "/api", Deprecated: true, Successor: "v1"},
+	}, versions.Versions)
+}
+
+func TestRegisterRoutes_CORS(t *testing.T) {
+	handler := Protect(RegisterRoutes(&mockHandlers{}), middleware.Cors{}, middleware.Auth{})
 
 	// Test that CORS headers are applied to API routes
-	req := httptest.NewRequest("GET", "/api/server/get", nil)
+	req := httptest.NewRequest("GET", "/api/v1/server/get", nil)
 	req.Header.Set("Origin", "http://localhost:3000")
 	rr := httptest.NewRecorder()
 
 	handler.ServeHTTP(rr, req)
 
 	// CORS middleware should add headers
-	// The exact headers depend on the CORS implementation
 	assert.NotEqual(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rr.Header().Get("Access-Control-Expose-Headers"), "Deprecation")
+}
+
+func TestProtect_AuthAndAllowlist(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/server/get", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	mux.HandleFunc("/api/server/stop", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := Protect(mux,
+		middleware.Cors{AllowedOrigins: []string{"http://lab:5173"}},
+		middleware.Auth{AdminTokens: []string{"admin-token"}, ViewerTokens: []string{"viewer-token"}},
+	)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		origin string
+		want   int
+	}{
+		{"ui is public", "GET", "/", "", "", http.StatusOK},
+		{"missing token", "GET", "/api/server/get", "", "", http.StatusUnauthorized},
+		{"invalid token", "GET", "/api/server/get", "nope", "", http.StatusUnauthorized},
+		{"viewer reads", "GET", "/api/server/get", "viewer-token", "", http.StatusOK},
+		{"viewer writes", "POST", "/api/server/stop", "viewer-token", "", http.StatusForbidden},
+		{"admin writes", "POST", "/api/server/stop", "admin-token", "", http.StatusOK},
+		{"allowed origin", "POST", "/api/server/stop", "admin-token", "http://lab:5173", http.StatusOK},
+		{"other origin", "POST", "/api/server/stop", "admin-token", "http://evil.example", http.StatusForbidden},
+		{"preflight without token", "OPTIONS", "/api/server/stop", "", "http://lab:5173", http.StatusOK},
+		{"websocket needs token", "GET", "/ws", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.want, rr.Code)
+		})
+	}
+}
+
+func TestProtect_RequestID(t *testing.T) {
+	var seen string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/server/get", func(w http.ResponseWriter, r *http.Request) {
+		seen = middleware.RequestID(r.Context())
+	})
+	handler := Protect(mux, middleware.Cors{}, middleware.Auth{AdminTokens: []string{"admin-token"}})
+
+	tests := []struct {
+		name   string
+		sent   string
+		reused bool
+	}{
+		{"generated", "", false},
+		{"taken over", "trace-1234", true},
+		{"invalid is replaced", "bad id\nwith newline", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = ""
+			req := httptest.NewRequest("GET", "/api/server/get", nil)
+			req.Header.Set("Authorization", "Bearer admin-token")
+			if tt.sent != "" {
+				req.Header.Set("X-Request-ID", tt.sent)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			id := rr.Header().Get("X-Request-ID")
+			require.NotEmpty(t, id)
+			assert.Equal(t, id, seen)
+			assert.Equal(t, tt.reused, id == tt.sent)
+		})
+	}
+}
+
+func TestProtect_ErrorBody(t *testing.T) {
+	handler := Protect(http.NewServeMux(), middleware.Cors{}, middleware.Auth{AdminTokens: []string{"admin-token"}})
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/server/get", nil))
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var response ApiError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, ErrUnauthorized, response.ErrorCode)
+	assert.Equal(t, rr.Header().Get("X-Request-ID"), response.RequestID)
 }
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// SendJobState is the lifecycle state of a periodic send job
+type SendJobState string
+
+const (
+	SendJobRunning   SendJobState = "running"
+	SendJobPaused    SendJobState = "paused"
+	SendJobDone      SendJobState = "done"
+	SendJobCancelled SendJobState = "cancelled"
+	SendJobFailed    SendJobState = "failed"
+)
+
+// StartSendJobRequest is the body of POST /api/client/jobs/start. Request is
+// sent every IntervalMs (±JitterMs) until Count datagrams were sent or
+// DurationMs passed; without both the job runs until it is cancelled.
+type StartSendJobRequest struct {
+	ClientID   int                 `json:"clientId"`
+	IntervalMs int                 `json:"intervalMs"`
+	JitterMs   int                 `json:"jitterMs,omitempty"`
+	Count      int                 `json:"count,omitempty"`
+	DurationMs int                 `json:"durationMs,omitempty"`
+	Request    SendDatagramRequest `json:"request"`
+}
+
+// SendJob is a snapshot of a periodic send job
+type SendJob struct {
+	ID         int                 `json:"id"`
+	ClientID   int                 `json:"clientId"`
+	IntervalMs int                 `json:"intervalMs"`
+	JitterMs   int                 `json:"jitterMs"`
+	Count      int                 `json:"count"`
+	DurationMs int                 `json:"durationMs"`
+	Request    SendDatagramRequest `json:"request"`
+	State      SendJobState        `json:"state"`
+	Sent       int                 `json:"sent"`
+	LastError  string              `json:"lastError,omitempty"`
+	StartedAt  time.Time           `json:"startedAt"`
+	FinishedAt time.Time           `json:"finishedAt,omitzero"`
+}
+
+func (j *SendJob) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, j)
+}
+
+// AllSendJobsResponse is the response for GET /api/client/jobs/all
+type AllSendJobsResponse struct {
+	Jobs []SendJob `json:"jobs"`
+}
+
+func (a *AllSendJobsResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, a)
+}
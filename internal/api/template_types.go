@@ -0,0 +1,25 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/auraspeak/debug-ui/internal/payload"
+)
+
+// TemplateResponse is the response for GET /api/templates/get
+type TemplateResponse struct {
+	payload.Template
+}
+
+func (t *TemplateResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, t)
+}
+
+// AllTemplatesResponse is the response for GET /api/templates/all
+type AllTemplatesResponse struct {
+	Templates []payload.Template `json:"templates"`
+}
+
+func (a *AllTemplatesResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, a)
+}
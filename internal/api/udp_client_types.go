@@ -1,6 +1,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/auraspeak/client"
 )
 
@@ -19,8 +21,18 @@ type UDPClient struct {
 	Name string
 	// Datagram by the user
 	Datagrams []Datagram
+	// Sequence number of the newest datagram, kept when the history is cleared
+	LastSeq int
 	// is it running
 	Running bool
+	// Group the client belongs to, empty if none
+	Group string
+	// User-defined tags
+	Tags []string
+	// When the client was created
+	CreatedAt time.Time
+	// When the client last sent or received a datagram, zero if never
+	LastActivity time.Time
 }
 type UDPClientAction struct {
 	ID     int
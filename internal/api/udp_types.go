@@ -1,11 +1,11 @@
 package api
 
 import (
-	"encoding/json"
 	"net/http"
 	"time"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/auraspeak/debug-ui/internal/dissect"
+	"github.com/auraspeak/protocol"
 )
 
 type DatagramDirection int
@@ -16,18 +16,47 @@ const (
 )
 
 type Datagram struct {
+	// Seq numbers the datagrams of a client, starting at 1
+	Seq       int               `json:"seq"`
 	Direction DatagramDirection `json:"direction"`
 	Message   []byte            `json:"message"`
+	// PacketType from the protocol header, not set for raw datagrams
+	PacketType protocol.PacketType `json:"packetType"`
+	// Raw is true if the message was sent without protocol header
+	Raw bool `json:"raw,omitempty"`
+	// Fields of the dissected message, offsets are relative to Message
+	Fields []dissect.Field `json:"fields,omitempty"`
+}
+
+// PacketEvent is broadcast over the WebSocket for every datagram sent or received
+type PacketEvent struct {
+	FromClientID int                 `json:"fromClientId"`
+	ToClientID   int                 `json:"toClientId"` // 0 means server
+	Seq          int                 `json:"seq"`
+	Direction    DatagramDirection   `json:"direction"`
+	PacketType   protocol.PacketType `json:"packetType"`
+	Raw          bool                `json:"raw,omitempty"`
+	Message      []byte              `json:"message"`
+	Fields       []dissect.Field     `json:"fields,omitempty"`
 }
 
 func (d *Datagram) Send(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusOK)
-	b, err := json.Marshal(d)
-	if err != nil {
-		log.WithField("caller", "web").WithError(err).Error("Can't marshal Datagram to json")
-	}
-	w.Write(b)
-	w.Write([]byte("\n"))
+	writeJSON(w, http.StatusOK, d)
+}
+
+// DatagramPageResponse is the response for GET /api/client/datagrams
+type DatagramPageResponse struct {
+	ClientID  int        `json:"clientId"`
+	Datagrams []Datagram `json:"datagrams"`
+	// LastSeq is the newest sequence number of the client
+	LastSeq int `json:"lastSeq"`
+	// Next is the since value of the following page
+	Next    int  `json:"next"`
+	HasMore bool `json:"hasMore"`
+}
+
+func (d *DatagramPageResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, d)
 }
 
 type trace struct {
@@ -0,0 +1,23 @@
+package api
+
+import "net/http"
+
+// APIVersion is a base path the REST API is served under
+type APIVersion struct {
+	Version  string `json:"version"`
+	BasePath string `json:"basePath"`
+	// Deprecated versions answer with a Deprecation header
+	Deprecated bool `json:"deprecated"`
+	// Successor is the version that replaces a deprecated one
+	Successor string `json:"successor,omitempty"`
+}
+
+// VersionsResponse is the response for GET /api/versions
+type VersionsResponse struct {
+	Current  string       `json:"current"`
+	Versions []APIVersion `json:"versions"`
+}
+
+func (v *VersionsResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, v)
+}
@@ -0,0 +1,38 @@
+package api
+
+import "net/http"
+
+// WorkspaceResetRequest is the body of POST /api/workspace/reset. All turns
+// on every option.
+type WorkspaceResetRequest struct {
+	All bool `json:"all,omitempty"`
+	// StopClients stops all clients and cancels their send jobs
+	StopClients bool `json:"stopClients,omitempty"`
+	// RemoveClients stops all clients and removes them with their history
+	RemoveClients bool `json:"removeClients,omitempty"`
+	StopServer    bool `json:"stopServer,omitempty"`
+	ClearTraces   bool `json:"clearTraces,omitempty"`
+	ClearLogs     bool `json:"clearLogs,omitempty"`
+	// ResetIDs restarts the client IDs after the highest remaining one, at 0 without clients
+	ResetIDs bool `json:"resetIds,omitempty"`
+}
+
+// WorkspaceResetResponse reports what a reset did. It is also the data of
+// the RST WebSocket message.
+type WorkspaceResetResponse struct {
+	StoppedClients int  `json:"stoppedClients"`
+	RemovedClients int  `json:"removedClients"`
+	FuzzStopped    bool `json:"fuzzStopped"`
+	ServerStopped  bool `json:"serverStopped"`
+	ClearedTraces  int  `json:"clearedTraces"`
+	// ClearedLogs counts the entries of the log store, ClearedLogEvents the
+	// log events recorded for the SSE streams
+	ClearedLogs      int `json:"clearedLogs"`
+	ClearedLogEvents int `json:"clearedLogEvents"`
+	// NextID is the ID of the next client if the IDs were reset
+	NextID *int `json:"nextId,omitempty"`
+}
+
+func (wr *WorkspaceResetResponse) Send(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, wr)
+}
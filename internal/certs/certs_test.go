@@ -0,0 +1,49 @@
+package certs
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOrCreate_PersistsCertificate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "certs")
+
+	first, err := LoadOrCreate(dir, "lab.local", "10.0.0.5")
+	require.NoError(t, err)
+	second, err := LoadOrCreate(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, Fingerprint(first), Fingerprint(second))
+	assert.Contains(t, first.Leaf.DNSNames, "lab.local")
+	assert.Contains(t, first.Leaf.DNSNames, "localhost")
+	assert.Len(t, first.Leaf.IPAddresses, 3)
+
+	info, err := os.Stat(filepath.Join(dir, KeyFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestLoad_UserSuppliedFiles(t *testing.T) {
+	dir := t.TempDir()
+	generated, err := LoadOrCreate(dir)
+	require.NoError(t, err)
+
+	cert, err := Load(filepath.Join(dir, CertFile), filepath.Join(dir, KeyFile))
+	require.NoError(t, err)
+	assert.Equal(t, Fingerprint(generated), Fingerprint(cert))
+
+	_, err = Load(filepath.Join(dir, "missing.pem"), filepath.Join(dir, KeyFile))
+	assert.Error(t, err)
+}
+
+func TestFingerprint_Format(t *testing.T) {
+	cert, err := LoadOrCreate(t.TempDir())
+	require.NoError(t, err)
+
+	assert.Regexp(t, regexp.MustCompile(`^([0-9A-F]{2}:){31}[0-9A-F]{2}$`), Fingerprint(cert))
+}
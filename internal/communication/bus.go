@@ -0,0 +1,106 @@
+package communication
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Handler receives the messages of a subscription. It runs in the goroutine
+// of Publish and must not block. Handlers of TopicLog must not log.
+type Handler func(msg InternalMessage)
+
+type subscription struct {
+	// topics is empty for all topics
+	topics  []Topic
+	handler Handler
+}
+
+// Bus is the in-process publish/subscribe bus of the server: the UDP
+// server, the clients, the trace collector and the API handlers publish
+// what happened, the WebSocket hub, the log store and the metrics
+// subscribe. Publish hands the message to the subscribers in the order
+// they subscribed, so every subscriber sees the messages of a publisher in
+// the order they were published.
+type Bus struct {
+	// mu orders Subscribe and unsubscribe, Publish reads subs without it
+	mu   sync.Mutex
+	subs atomic.Pointer[[]*subscription]
+	// invalid receives the dropped messages, see SetInvalidHandler
+	invalid atomic.Pointer[Handler]
+}
+
+func NewBus() *Bus {
+	b := &Bus{}
+	b.subs.Store(&[]*subscription{})
+	return b
+}
+
+// Subscribe calls handler for the messages of the topics, for all messages
+// without topics. It returns the func to unsubscribe.
+func (b *Bus) Subscribe(handler Handler, topics ...Topic) (unsubscribe func()) {
+	sub := &subscription{topics: topics, handler: handler}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs := append(slices.Clone(*b.subs.Load()), sub)
+	b.subs.Store(&subs)
+
+	return sync.OnceFunc(func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := slices.DeleteFunc(slices.Clone(*b.subs.Load()), func(s *subscription) bool { return s == sub })
+		b.subs.Store(&subs)
+	})
+}
+
+// SetInvalidHandler sets the handler of the messages Publish drops because
+// their Data doesn't have the type of the topic's Event
+func (b *Bus) SetInvalidHandler(handler Handler) {
+	b.invalid.Store(&handler)
+}
+
+// Publish hands msg to the subscribers of its topic and returns when all
+// handled it. Messages whose Data doesn't have the type of the topic's
+// Event are dropped.
+func (b *Bus) Publish(msg InternalMessage) {
+	if check, ok := payloadChecks[msg.Topic]; ok && !check(msg.Data) {
+		if handler := b.invalid.Load(); handler != nil {
+			(*handler)(msg)
+		}
+		return
+	}
+	for _, sub := range *b.subs.Load() {
+		if len(sub.topics) == 0 || slices.Contains(sub.topics, msg.Topic) {
+			sub.handler(msg)
+		}
+	}
+}
+
+// SubscriberCount returns the number of subscriptions
+func (b *Bus) SubscriberCount() int {
+	return len(*b.subs.Load())
+}
+
+// Counter counts the published messages by topic. Subscribe its Handle to
+// the bus.
+type Counter struct {
+	mu     sync.Mutex
+	counts map[Topic]uint64
+}
+
+func (c *Counter) Handle(msg InternalMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[Topic]uint64)
+	}
+	c.counts[msg.Topic]++
+}
+
+// Counts returns the number of messages per topic
+func (c *Counter) Counts() map[Topic]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.counts)
+}
@@ -0,0 +1,117 @@
+package communication
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_PublishToTopics(t *testing.T) {
+	bus := NewBus()
+	var all, selected []Topic
+	bus.Subscribe(func(msg InternalMessage) { all = append(all, msg.Topic) })
+	unsubscribe := bus.Subscribe(func(msg InternalMessage) { selected = append(selected, msg.Topic) }, TopicClientStarted, TopicServerState)
+	assert.Equal(t, 2, bus.SubscriberCount())
+
+	bus.Publish(InternalMessage{Topic: TopicClientStarted})
+	bus.Publish(InternalMessage{Topic: TopicClientState})
+	bus.Publish(InternalMessage{Topic: TopicServerState})
+	unsubscribe()
+	unsubscribe()
+	bus.Publish(InternalMessage{Topic: TopicClientStarted})
+
+	assert.Equal(t, []Topic{TopicClientStarted, TopicClientState, TopicServerState, TopicClientStarted}, all)
+	assert.Equal(t, []Topic{TopicClientStarted, TopicServerState}, selected)
+	assert.Equal(t, 1, bus.SubscriberCount())
+}
+
+func TestBus_SubscriberOrder(t *testing.T) {
+	bus := NewBus()
+	var order []string
+	bus.Subscribe(func(InternalMessage) { order = append(order, "store") }, TopicLogLevels)
+	bus.Subscribe(func(InternalMessage) { order = append(order, "hub") })
+
+	bus.Publish(InternalMessage{Topic: TopicLogLevels})
+
+	assert.Equal(t, []string{"store", "hub"}, order)
+}
+
+// TestBus_Concurrent publishes while subscribing; run it with -race
+func TestBus_Concurrent(t *testing.T) {
+	bus := NewBus()
+	var counter Counter
+	bus.Subscribe(counter.Handle)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 100 {
+				bus.Publish(InternalMessage{Topic: TopicClientStarted})
+			}
+		})
+	}
+	wg.Go(func() {
+		for range 20 {
+			bus.Subscribe(func(InternalMessage) {}, TopicServerState)()
+		}
+	})
+	wg.Wait()
+
+	assert.Equal(t, map[Topic]uint64{TopicClientStarted: 400}, counter.Counts())
+}
+
+func TestEvent_PublishSubscribe(t *testing.T) {
+	bus := NewBus()
+	var ids [][]int
+	var msgs []InternalMessage
+	ClientsStopped.Subscribe(bus, func(msg InternalMessage, data []int) {
+		msgs = append(msgs, msg)
+		ids = append(ids, data)
+	})
+
+	ClientsStopped.Publish(bus, InternalMessage{Caller: CallerWebServer, ClientID: 2}, []int{2})
+	DatagramsCleared.Publish(bus, InternalMessage{}, []int{3})
+
+	require.Len(t, msgs, 1)
+	assert.Equal(t, TopicClientsStopped, msgs[0].Topic)
+	assert.Equal(t, CallerWebServer, msgs[0].Caller)
+	assert.Equal(t, [][]int{{2}}, ids)
+	data, ok := ClientsStopped.Data(msgs[0])
+	assert.True(t, ok)
+	assert.Equal(t, []int{2}, data)
+	_, ok = DatagramsCleared.Data(msgs[0])
+	assert.False(t, ok)
+}
+
+func TestBus_DropsInvalidData(t *testing.T) {
+	bus := NewBus()
+	var counter Counter
+	bus.Subscribe(counter.Handle)
+	var invalid []InternalMessage
+	bus.SetInvalidHandler(func(msg InternalMessage) { invalid = append(invalid, msg) })
+
+	bus.Publish(InternalMessage{Topic: TopicClientsStopped, Data: "3"})
+	bus.Publish(InternalMessage{Topic: TopicClientsStopped})
+	bus.Publish(InternalMessage{Topic: TopicClientState})
+
+	assert.Len(t, invalid, 2)
+	assert.Equal(t, map[Topic]uint64{TopicClientState: 1}, counter.Counts())
+}
+
+func TestInternalMessage_JSON(t *testing.T) {
+	msg := InternalMessage{
+		Caller:   CallerUDPClient,
+		Target:   `web "ui"`,
+		Content:  `say "hi"` + "\n\\",
+		Topic:    TopicClientState,
+		ClientID: 3,
+	}
+
+	b, err := json.Marshal(msg)
+	require.NoError(t, err)
+	var decoded InternalMessage
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, msg, decoded)
+}
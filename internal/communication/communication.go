@@ -6,12 +6,55 @@ const (
 	CallerUDPClient Caller = "UDPClient"
 	CallerUDPServer Caller = "UDPServer"
 	CallerWebServer Caller = "WebServer"
+	// CallerLogger publishes the log entries
+	CallerLogger Caller = "Logger"
 )
 
+// Topic says what happened. Each topic notes the type of InternalMessage.Data,
+// the topics with data are published and subscribed through their Event.
+type Topic string
+
+const (
+	// TopicServerStarted: the web server started, no data
+	TopicServerStarted Topic = "serverStarted"
+	// TopicServerState: the state of the UDP server changed, no data
+	TopicServerState Topic = "serverState"
+	// TopicServerPacket: the UDP server received a payload, Data is []byte
+	TopicServerPacket Topic = "serverPacket"
+	// TopicClientStarted: a client was started, no data
+	TopicClientStarted Topic = "clientStarted"
+	// TopicClientState: the state or labels of a client changed, no data
+	TopicClientState Topic = "clientState"
+	// TopicClientsStopped: clients were stopped, Data is []int with their IDs
+	TopicClientsStopped Topic = "clientsStopped"
+	// TopicDatagramsCleared: the datagram history of clients was cleared,
+	// Data is []int with their IDs
+	TopicDatagramsCleared Topic = "datagramsCleared"
+	// TopicPacket: a client sent or received a datagram, Data is api.PacketEvent
+	TopicPacket Topic = "packet"
+	// TopicTrace: the UDP server traced a packet, Data is tracer.TraceEvent
+	TopicTrace Topic = "trace"
+	// TopicSendJob: a send job started, progressed or ended, Data is api.SendJob
+	TopicSendJob Topic = "sendJob"
+	// TopicFuzz: the fuzz run progressed, Data is fuzz.Summary
+	TopicFuzz Topic = "fuzz"
+	// TopicLogLevels: the log levels changed, no data
+	TopicLogLevels Topic = "logLevels"
+	// TopicReset: the workspace was reset, Data is api.WorkspaceResetResponse
+	TopicReset Topic = "reset"
+	// TopicLog: a log entry, Data is logstore.Record
+	TopicLog Topic = "log"
+)
+
+// InternalMessage is a message on the Bus. It encodes with encoding/json.
 type InternalMessage struct {
 	Caller  Caller `json:"caller"`
 	Target  string `json:"target"`
 	Content string `json:"content"`
+	Topic   Topic  `json:"topic"`
+	// ClientID is the client the message is about, for the client topics
+	ClientID int `json:"clientId"`
+	Data     any `json:"data,omitempty"`
 }
 
 func (m *InternalMessage) ToBytes() []byte {
@@ -21,7 +64,3 @@ func (m *InternalMessage) ToBytes() []byte {
 func (m *InternalMessage) FromBytes(data []byte) {
 	m.Content = string(data)
 }
-
-func (m *InternalMessage) ToJSON() string {
-	return `{"caller":"` + string(m.Caller) + `","target":"` + m.Target + `","content":"` + m.Content + `"}`
-}
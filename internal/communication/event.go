@@ -0,0 +1,68 @@
+package communication
+
+import (
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/fuzz"
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	"github.com/auraspeak/server/pkg/tracer"
+)
+
+// Event is a topic whose messages carry Data of type T. Publishing and
+// subscribing through it instead of the Bus, a wrong payload fails to
+// compile.
+type Event[T any] struct {
+	Topic Topic
+}
+
+// The topics with data
+var (
+	ServerPackets    = newEvent[[]byte](TopicServerPacket)
+	ClientsStopped   = newEvent[[]int](TopicClientsStopped)
+	DatagramsCleared = newEvent[[]int](TopicDatagramsCleared)
+	Packets          = newEvent[api.PacketEvent](TopicPacket)
+	Traces           = newEvent[tracer.TraceEvent](TopicTrace)
+	SendJobs         = newEvent[api.SendJob](TopicSendJob)
+	FuzzUpdates      = newEvent[fuzz.Summary](TopicFuzz)
+	Resets           = newEvent[api.WorkspaceResetResponse](TopicReset)
+	Logs             = newEvent[logstore.Record](TopicLog)
+)
+
+// payloadChecks report whether data has the type of the topic's Event.
+// Topics without data have none.
+var payloadChecks = map[Topic]func(data any) bool{}
+
+func newEvent[T any](topic Topic) Event[T] {
+	payloadChecks[topic] = func(data any) bool {
+		_, ok := data.(T)
+		return ok
+	}
+	return Event[T]{Topic: topic}
+}
+
+// Publish publishes msg with the topic and data
+func (e Event[T]) Publish(b *Bus, msg InternalMessage, data T) {
+	msg.Topic = e.Topic
+	msg.Data = data
+	b.Publish(msg)
+}
+
+// Subscribe calls handler with the messages of the topic and their data.
+// It returns the func to unsubscribe.
+func (e Event[T]) Subscribe(b *Bus, handler func(msg InternalMessage, data T)) (unsubscribe func()) {
+	return b.Subscribe(func(msg InternalMessage) {
+		if data, ok := e.Data(msg); ok {
+			handler(msg, data)
+		}
+	}, e.Topic)
+}
+
+// Data returns the data of msg if it is a message of the topic, for
+// subscribers of several topics
+func (e Event[T]) Data(msg InternalMessage) (T, bool) {
+	if msg.Topic != e.Topic {
+		var zero T
+		return zero, false
+	}
+	data, ok := msg.Data.(T)
+	return data, ok
+}
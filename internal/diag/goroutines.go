@@ -0,0 +1,78 @@
+package diag
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// waitInterval is how often WaitFor checks the counts
+const waitInterval = 10 * time.Millisecond
+
+// Goroutines counts the running goroutines by component, like
+// "handleTrace" or "client"
+type Goroutines struct {
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// Go runs f in a goroutine tracked by wg and counted under component
+func (g *Goroutines) Go(wg *sync.WaitGroup, component string, f func()) {
+	g.add(component, 1)
+	wg.Go(func() {
+		defer g.add(component, -1)
+		f()
+	})
+}
+
+// Wrap returns f counted under component while it runs, for goroutines
+// started by others
+func (g *Goroutines) Wrap(component string, f func()) func() {
+	return func() {
+		g.add(component, 1)
+		defer g.add(component, -1)
+		f()
+	}
+}
+
+func (g *Goroutines) add(component string, delta int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.running == nil {
+		g.running = make(map[string]int)
+	}
+	g.running[component] += delta
+	if g.running[component] == 0 {
+		delete(g.running, component)
+	}
+}
+
+// Running returns the number of running goroutines per component
+func (g *Goroutines) Running() map[string]int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return maps.Clone(g.running)
+}
+
+// WaitFor waits until the goroutines of the components have returned or ctx
+// ends. It returns the ones still running, empty when all returned.
+func (g *Goroutines) WaitFor(ctx context.Context, components ...string) map[string]int {
+	ticker := time.NewTicker(waitInterval)
+	defer ticker.Stop()
+	for {
+		pending := g.Running()
+		maps.DeleteFunc(pending, func(component string, _ int) bool {
+			return !slices.Contains(components, component)
+		})
+		if len(pending) == 0 {
+			return pending
+		}
+		select {
+		case <-ctx.Done():
+			return pending
+		case <-ticker.C:
+		}
+	}
+}
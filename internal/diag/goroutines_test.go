@@ -0,0 +1,54 @@
+package diag
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoroutines_Running(t *testing.T) {
+	var g Goroutines
+	var wg sync.WaitGroup
+	release := make(chan struct{})
+	for range 3 {
+		g.Go(&wg, "client", func() { <-release })
+	}
+	g.Go(&wg, "handleTrace", func() { <-release })
+
+	assert.Equal(t, map[string]int{"client": 3, "handleTrace": 1}, g.Running())
+
+	close(release)
+	wg.Wait()
+	assert.Empty(t, g.Running())
+}
+
+func TestGoroutines_Wrap(t *testing.T) {
+	var g Goroutines
+	var running map[string]int
+	f := g.Wrap("client", func() { running = g.Running() })
+
+	assert.Empty(t, g.Running())
+	f()
+	assert.Equal(t, map[string]int{"client": 1}, running)
+	assert.Empty(t, g.Running())
+}
+
+func TestGoroutines_WaitFor(t *testing.T) {
+	var g Goroutines
+	var wg sync.WaitGroup
+	release := make(chan struct{})
+	stuck := make(chan struct{})
+	defer close(stuck)
+	g.Go(&wg, "client", func() { <-release })
+	g.Go(&wg, "server", func() { <-stuck })
+
+	close(release)
+	assert.Empty(t, g.WaitFor(context.Background(), "client"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, map[string]int{"server": 1}, g.WaitFor(ctx, "client", "server"))
+}
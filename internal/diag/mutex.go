@@ -0,0 +1,76 @@
+// Package diag collects runtime diagnostics of the debug UI: how long locks
+// are held and which goroutines are running.
+package diag
+
+import (
+	"sync"
+	"time"
+)
+
+// Mutex is a sync.Mutex that records how long it is waited for and held.
+// The zero value is an unlocked mutex.
+type Mutex struct {
+	mu sync.Mutex
+
+	// stats is guarded by statsMu, so Stats works while mu is held
+	statsMu  sync.Mutex
+	stats    LockStats
+	lockedAt time.Time
+}
+
+// LockStats are the recorded times of a Mutex
+type LockStats struct {
+	Acquisitions uint64
+	// Contended counts the acquisitions that had to wait
+	Contended uint64
+	TotalWait time.Duration
+	MaxWait   time.Duration
+	TotalHold time.Duration
+	MaxHold   time.Duration
+	// Held tells whether the mutex is locked right now and HeldFor since when
+	Held    bool
+	HeldFor time.Duration
+}
+
+func (m *Mutex) Lock() {
+	start := time.Now()
+	contended := !m.mu.TryLock()
+	if contended {
+		m.mu.Lock()
+	}
+	acquired := time.Now()
+
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	wait := acquired.Sub(start)
+	m.stats.Acquisitions++
+	if contended {
+		m.stats.Contended++
+	}
+	m.stats.TotalWait += wait
+	m.stats.MaxWait = max(m.stats.MaxWait, wait)
+	m.lockedAt = acquired
+}
+
+func (m *Mutex) Unlock() {
+	m.statsMu.Lock()
+	hold := time.Since(m.lockedAt)
+	m.stats.TotalHold += hold
+	m.stats.MaxHold = max(m.stats.MaxHold, hold)
+	m.lockedAt = time.Time{}
+	m.statsMu.Unlock()
+
+	m.mu.Unlock()
+}
+
+// Stats returns the recorded times. It does not wait for the mutex.
+func (m *Mutex) Stats() LockStats {
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	stats := m.stats
+	if !m.lockedAt.IsZero() {
+		stats.Held = true
+		stats.HeldFor = time.Since(m.lockedAt)
+	}
+	return stats
+}
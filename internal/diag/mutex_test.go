@@ -0,0 +1,46 @@
+package diag
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutex_Stats(t *testing.T) {
+	var m Mutex
+	m.Lock()
+	time.Sleep(20 * time.Millisecond)
+	held := m.Stats()
+	m.Unlock()
+
+	assert.True(t, held.Held)
+	assert.GreaterOrEqual(t, held.HeldFor, 20*time.Millisecond)
+
+	stats := m.Stats()
+	assert.False(t, stats.Held)
+	assert.Zero(t, stats.HeldFor)
+	assert.Equal(t, uint64(1), stats.Acquisitions)
+	assert.Zero(t, stats.Contended)
+	assert.GreaterOrEqual(t, stats.MaxHold, 20*time.Millisecond)
+	assert.Equal(t, stats.MaxHold, stats.TotalHold)
+}
+
+func TestMutex_Contended(t *testing.T) {
+	var m Mutex
+	m.Lock()
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		m.Lock()
+		m.Unlock()
+	})
+	time.Sleep(20 * time.Millisecond)
+	m.Unlock()
+	wg.Wait()
+
+	stats := m.Stats()
+	assert.Equal(t, uint64(2), stats.Acquisitions)
+	assert.Equal(t, uint64(1), stats.Contended)
+	assert.GreaterOrEqual(t, stats.MaxWait, 10*time.Millisecond)
+}
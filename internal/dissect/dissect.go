@@ -0,0 +1,123 @@
+package dissect
+
+import (
+	"fmt"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/auraspeak/protocol"
+)
+
+// FieldType describes how the bytes of a Field are interpreted
+type FieldType string
+
+const (
+	FieldUint   FieldType = "uint"
+	FieldBytes  FieldType = "bytes"
+	FieldString FieldType = "string"
+	FieldStruct FieldType = "struct"
+)
+
+// Field is a named, typed part of a payload. Offset and Length are byte
+// positions relative to the start of the dissected payload, so a hex view can
+// highlight the field. Struct fields carry their parts in Children.
+type Field struct {
+	Name     string    `json:"name"`
+	Type     FieldType `json:"type"`
+	Offset   int       `json:"offset"`
+	Length   int       `json:"length"`
+	Value    any       `json:"value,omitempty"`
+	Children []Field   `json:"children,omitempty"`
+}
+
+// Dissector turns the payload of one packet type into a field tree
+type Dissector func(payload []byte) ([]Field, error)
+
+// Registry maps protocol packet types to their dissectors
+type Registry struct {
+	mu         sync.RWMutex
+	dissectors map[protocol.PacketType]Dissector
+}
+
+// Default is the registry used by debug-ui, it contains the built-in dissectors
+var Default = NewRegistry()
+
+func init() {
+	Default.Register(protocol.PacketTypeDebugAny, DissectDebugAny)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		mu:         sync.RWMutex{},
+		dissectors: make(map[protocol.PacketType]Dissector),
+	}
+}
+
+// Register adds or replaces the dissector for a packet type
+func (r *Registry) Register(packetType protocol.PacketType, d Dissector) {
+	r.mu.Lock()
+	r.dissectors[packetType] = d
+	r.mu.Unlock()
+}
+
+// Lookup returns the dissector registered for a packet type
+func (r *Registry) Lookup(packetType protocol.PacketType) (Dissector, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.dissectors[packetType]
+	return d, ok
+}
+
+// Dissect runs the dissector for packetType over payload. Unknown packet types
+// and payloads the dissector rejects fall back to a single bytes field, the
+// dissector error is returned alongside so callers can log it.
+func (r *Registry) Dissect(packetType protocol.PacketType, payload []byte) ([]Field, error) {
+	d, ok := r.Lookup(packetType)
+	if !ok {
+		return []Field{BytesField("payload", 0, payload)}, nil
+	}
+	fields, err := d(payload)
+	if err != nil {
+		return []Field{BytesField("payload", 0, payload)}, fmt.Errorf("dissect packet type %v: %w", packetType, err)
+	}
+	return fields, nil
+}
+
+// DissectDebugAny shows the free-form debug payload as text when it is
+// printable and as bytes otherwise
+func DissectDebugAny(payload []byte) ([]Field, error) {
+	if isPrintable(payload) {
+		return []Field{{
+			Name:   "text",
+			Type:   FieldString,
+			Offset: 0,
+			Length: len(payload),
+			Value:  string(payload),
+		}}, nil
+	}
+	return []Field{BytesField("data", 0, payload)}, nil
+}
+
+// BytesField creates a bytes field, the value is rendered as hex
+func BytesField(name string, offset int, b []byte) Field {
+	return Field{
+		Name:   name,
+		Type:   FieldBytes,
+		Offset: offset,
+		Length: len(b),
+		Value:  fmt.Sprintf("%x", b),
+	}
+}
+
+func isPrintable(b []byte) bool {
+	if len(b) == 0 || !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package dissect
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/auraspeak/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Dissect_DebugAnyText(t *testing.T) {
+	fields, err := Default.Dissect(protocol.PacketTypeDebugAny, []byte("hello"))
+
+	require.NoError(t, err)
+	require.Len(t, fields, 1)
+	assert.Equal(t, FieldString, fields[0].Type)
+	assert.Equal(t, 0, fields[0].Offset)
+	assert.Equal(t, 5, fields[0].Length)
+	assert.Equal(t, "hello", fields[0].Value)
+}
+
+func TestRegistry_Dissect_DebugAnyBinary(t *testing.T) {
+	fields, err := Default.Dissect(protocol.PacketTypeDebugAny, []byte{0x00, 0xff})
+
+	require.NoError(t, err)
+	require.Len(t, fields, 1)
+	assert.Equal(t, FieldBytes, fields[0].Type)
+	assert.Equal(t, "00ff", fields[0].Value)
+}
+
+func TestRegistry_Dissect_UnknownType(t *testing.T) {
+	registry := NewRegistry()
+
+	fields, err := registry.Dissect(protocol.PacketTypeDebugAny, []byte{0x01, 0x02})
+
+	require.NoError(t, err)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "payload", fields[0].Name)
+	assert.Equal(t, 2, fields[0].Length)
+}
+
+func TestRegistry_Dissect_Error(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(protocol.PacketTypeDebugAny, func(payload []byte) ([]Field, error) {
+		return nil, errors.New("broken")
+	})
+
+	fields, err := registry.Dissect(protocol.PacketTypeDebugAny, []byte{0x01})
+
+	assert.Error(t, err)
+	require.Len(t, fields, 1)
+	assert.Equal(t, FieldBytes, fields[0].Type)
+}
+
+func TestReader(t *testing.T) {
+	r := NewReader([]byte{0x01, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03, 'h', 'i', 0xaa})
+
+	assert.Equal(t, uint8(1), r.Uint8("a"))
+	assert.Equal(t, uint16(2), r.Uint16("b"))
+	assert.Equal(t, uint32(3), r.Uint32("c"))
+	assert.Equal(t, "hi", r.String("d", 2))
+	assert.Equal(t, []byte{0xaa}, r.Rest("e"))
+
+	fields, err := r.Fields()
+	require.NoError(t, err)
+	require.Len(t, fields, 5)
+	offsets := []int{0, 1, 3, 7, 9}
+	for i, f := range fields {
+		assert.Equal(t, offsets[i], f.Offset, f.Name)
+	}
+}
+
+func TestReader_ShortPayload(t *testing.T) {
+	r := NewReader([]byte{0x01})
+
+	r.Uint16("a")
+	r.Uint8("b")
+
+	fields, err := r.Fields()
+	assert.Error(t, err)
+	assert.Empty(t, fields)
+}
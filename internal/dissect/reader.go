@@ -0,0 +1,98 @@
+package dissect
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Reader walks a payload front to back and records the offset of every field
+// it reads. It is meant as the building block for dissectors.
+type Reader struct {
+	payload []byte
+	offset  int
+	fields  []Field
+	err     error
+}
+
+func NewReader(payload []byte) *Reader {
+	return &Reader{payload: payload}
+}
+
+// Uint8 reads one byte as unsigned integer field
+func (r *Reader) Uint8(name string) uint8 {
+	b := r.take(name, 1)
+	if b == nil {
+		return 0
+	}
+	r.add(Field{Name: name, Type: FieldUint, Offset: r.offset - 1, Length: 1, Value: b[0]})
+	return b[0]
+}
+
+// Uint16 reads a big endian uint16 field
+func (r *Reader) Uint16(name string) uint16 {
+	b := r.take(name, 2)
+	if b == nil {
+		return 0
+	}
+	v := binary.BigEndian.Uint16(b)
+	r.add(Field{Name: name, Type: FieldUint, Offset: r.offset - 2, Length: 2, Value: v})
+	return v
+}
+
+// Uint32 reads a big endian uint32 field
+func (r *Reader) Uint32(name string) uint32 {
+	b := r.take(name, 4)
+	if b == nil {
+		return 0
+	}
+	v := binary.BigEndian.Uint32(b)
+	r.add(Field{Name: name, Type: FieldUint, Offset: r.offset - 4, Length: 4, Value: v})
+	return v
+}
+
+// Bytes reads n bytes as bytes field
+func (r *Reader) Bytes(name string, n int) []byte {
+	b := r.take(name, n)
+	if b == nil {
+		return nil
+	}
+	r.add(BytesField(name, r.offset-n, b))
+	return b
+}
+
+// String reads n bytes as string field
+func (r *Reader) String(name string, n int) string {
+	b := r.take(name, n)
+	if b == nil {
+		return ""
+	}
+	r.add(Field{Name: name, Type: FieldString, Offset: r.offset - n, Length: n, Value: string(b)})
+	return string(b)
+}
+
+// Rest reads all remaining bytes as bytes field
+func (r *Reader) Rest(name string) []byte {
+	return r.Bytes(name, len(r.payload)-r.offset)
+}
+
+// Fields returns the fields read so far and the first error that occurred
+func (r *Reader) Fields() ([]Field, error) {
+	return r.fields, r.err
+}
+
+func (r *Reader) take(name string, n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.offset+n > len(r.payload) {
+		r.err = fmt.Errorf("field %s: need %d bytes at offset %d, payload has %d", name, n, r.offset, len(r.payload))
+		return nil
+	}
+	b := r.payload[r.offset : r.offset+n]
+	r.offset += n
+	return b
+}
+
+func (r *Reader) add(f Field) {
+	r.fields = append(r.fields, f)
+}
@@ -0,0 +1,48 @@
+package fuzz
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/auraspeak/protocol"
+)
+
+// LoadCorpus reads every regular file in dir as seed payload. The payloads
+// are wrapped in a valid header of packetType.
+func LoadCorpus(dir string, packetType protocol.PacketType) ([]Input, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read corpus dir: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	seeds := make([]Input, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read corpus file: %w", err)
+		}
+		seeds = append(seeds, SeedFromPayload(packetType, b))
+	}
+	return seeds, nil
+}
+
+// SaveCrash writes the bytes of a finding to dir, named by kind and content
+// hash so the same crash is stored only once. It returns the file path.
+func SaveCrash(dir string, kind FindingKind, b []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create crash dir: %w", err)
+	}
+	sum := sha1.Sum(b)
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.bin", kind, hex.EncodeToString(sum[:])[:12]))
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return "", fmt.Errorf("write crash file: %w", err)
+	}
+	return path, nil
+}
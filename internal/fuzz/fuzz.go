@@ -0,0 +1,256 @@
+package fuzz
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Target is the system under test. The fuzzer sends inputs through it and
+// uses it as oracle to detect crashes and hangs.
+type Target interface {
+	// Send puts b on the wire through a debug client
+	Send(b []byte) error
+	// Alive reports whether the server is still running
+	Alive() bool
+	// Probe sends a valid packet and waits for the server to echo it
+	Probe(timeout time.Duration) error
+	// ErrorLogs returns the number of error log entries the server produced so far
+	ErrorLogs() uint64
+	// Restart brings a crashed or hanging server back up
+	Restart() error
+}
+
+// FindingKind classifies what went wrong after an input was sent
+type FindingKind string
+
+const (
+	FindingCrash    FindingKind = "crash"
+	FindingHang     FindingKind = "hang"
+	FindingErrorLog FindingKind = "errorlog"
+)
+
+// Config controls a fuzz run, zero values fall back to defaults
+type Config struct {
+	Iterations   int
+	Seed         int64
+	MaxMutations int
+	// ProbeEvery sends an echo probe after every n-th input
+	ProbeEvery   int
+	ProbeTimeout time.Duration
+	// MinimizeBudget is the maximum number of replays used to minimize one finding
+	MinimizeBudget int
+	CrashDir       string
+}
+
+func (c Config) withDefaults() Config {
+	if c.Iterations <= 0 {
+		c.Iterations = 1000
+	}
+	if c.Seed == 0 {
+		c.Seed = time.Now().UnixNano()
+	}
+	if c.MaxMutations <= 0 {
+		c.MaxMutations = 3
+	}
+	if c.ProbeEvery <= 0 {
+		c.ProbeEvery = 1
+	}
+	if c.ProbeTimeout <= 0 {
+		c.ProbeTimeout = time.Second
+	}
+	if c.MinimizeBudget <= 0 {
+		c.MinimizeBudget = 200
+	}
+	if c.CrashDir == "" {
+		c.CrashDir = "fuzz-crashes"
+	}
+	return c
+}
+
+// Finding is an input that crashed, hung or made the server log errors
+type Finding struct {
+	Kind      FindingKind `json:"kind"`
+	Iteration int         `json:"iteration"`
+	Mutations []string    `json:"mutations"`
+	Input     []byte      `json:"input"`
+	Minimized []byte      `json:"minimized"`
+	File      string      `json:"file,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// Summary describes the state and result of a fuzz run
+type Summary struct {
+	Running    bool      `json:"running"`
+	Seed       int64     `json:"seed"`
+	Seeds      int       `json:"seeds"`
+	Iterations int       `json:"iterations"`
+	Executed   int       `json:"executed"`
+	SendErrors int       `json:"sendErrors"`
+	Findings   []Finding `json:"findings"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitzero"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Fuzzer mutates seed inputs and sends them to a target
+type Fuzzer struct {
+	cfg    Config
+	target Target
+	seeds  []Input
+	rand   *rand.Rand
+
+	mu      sync.Mutex
+	summary Summary
+	// OnUpdate is called after every finding and when the run ends
+	OnUpdate func(Summary)
+}
+
+func New(cfg Config, target Target, seeds []Input) (*Fuzzer, error) {
+	if len(seeds) == 0 {
+		return nil, errors.New("no seeds")
+	}
+	cfg = cfg.withDefaults()
+	return &Fuzzer{
+		cfg:    cfg,
+		target: target,
+		seeds:  seeds,
+		rand:   rand.New(rand.NewSource(cfg.Seed)),
+		summary: Summary{
+			Seed:       cfg.Seed,
+			Seeds:      len(seeds),
+			Iterations: cfg.Iterations,
+			Findings:   []Finding{},
+		},
+	}, nil
+}
+
+// Summary returns a snapshot of the current run state
+func (f *Fuzzer) Summary() Summary {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.summary
+	s.Findings = append([]Finding(nil), f.summary.Findings...)
+	return s
+}
+
+// Run executes the configured number of iterations or until ctx is done
+func (f *Fuzzer) Run(ctx context.Context) Summary {
+	f.update(func(s *Summary) {
+		s.Running = true
+		s.StartedAt = time.Now()
+	})
+	if err := f.run(ctx); err != nil {
+		f.update(func(s *Summary) { s.Error = err.Error() })
+	}
+	f.update(func(s *Summary) {
+		s.Running = false
+		s.FinishedAt = time.Now()
+	})
+	f.notify()
+	return f.Summary()
+}
+
+func (f *Fuzzer) run(ctx context.Context) error {
+	for i := 0; i < f.cfg.Iterations; i++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+		seed := f.seeds[f.rand.Intn(len(f.seeds))]
+		in, mutations := Mutate(f.rand, seed, f.cfg.MaxMutations)
+		b := in.Encode()
+
+		errorLogs := f.target.ErrorLogs()
+		err := f.target.Send(b)
+		f.update(func(s *Summary) {
+			s.Executed++
+			if err != nil {
+				s.SendErrors++
+			}
+		})
+		if err != nil {
+			continue
+		}
+
+		kind := f.check(errorLogs, (i+1)%f.cfg.ProbeEvery == 0)
+		if kind == "" {
+			continue
+		}
+		if err := f.record(i, kind, mutations, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check asks the oracle whether the last input broke the server
+func (f *Fuzzer) check(errorLogs uint64, probe bool) FindingKind {
+	if !f.target.Alive() {
+		return FindingCrash
+	}
+	if probe {
+		if err := f.target.Probe(f.cfg.ProbeTimeout); err != nil {
+			return FindingHang
+		}
+	}
+	if f.target.ErrorLogs() > errorLogs {
+		return FindingErrorLog
+	}
+	return ""
+}
+
+// record minimizes and stores a finding. It fails if the server can't be
+// brought back up afterwards.
+func (f *Fuzzer) record(iteration int, kind FindingKind, mutations []string, b []byte) error {
+	if err := f.recover(); err != nil {
+		return err
+	}
+	minimized := Minimize(b, func(candidate []byte) bool {
+		if err := f.recover(); err != nil {
+			return false
+		}
+		errorLogs := f.target.ErrorLogs()
+		if err := f.target.Send(candidate); err != nil {
+			return false
+		}
+		return f.check(errorLogs, true) == kind
+	}, f.cfg.MinimizeBudget)
+
+	finding := Finding{
+		Kind:      kind,
+		Iteration: iteration,
+		Mutations: mutations,
+		Input:     b,
+		Minimized: minimized,
+	}
+	path, err := SaveCrash(f.cfg.CrashDir, kind, minimized)
+	if err != nil {
+		finding.Error = err.Error()
+	}
+	finding.File = path
+	f.update(func(s *Summary) { s.Findings = append(s.Findings, finding) })
+	f.notify()
+	return f.recover()
+}
+
+// recover restarts the server if it crashed or stopped answering probes
+func (f *Fuzzer) recover() error {
+	if f.target.Alive() && f.target.Probe(f.cfg.ProbeTimeout) == nil {
+		return nil
+	}
+	return f.target.Restart()
+}
+
+func (f *Fuzzer) update(fn func(s *Summary)) {
+	f.mu.Lock()
+	fn(&f.summary)
+	f.mu.Unlock()
+}
+
+func (f *Fuzzer) notify() {
+	if f.OnUpdate != nil {
+		f.OnUpdate(f.Summary())
+	}
+}
@@ -0,0 +1,155 @@
+package fuzz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/auraspeak/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTarget crashes whenever a datagram is shorter than crashBelow bytes
+type fakeTarget struct {
+	crashBelow int
+	alive      bool
+	sent       int
+	restarts   int
+}
+
+func (t *fakeTarget) Send(b []byte) error {
+	t.sent++
+	if len(b) < t.crashBelow {
+		t.alive = false
+	}
+	return nil
+}
+
+func (t *fakeTarget) Alive() bool { return t.alive }
+
+func (t *fakeTarget) Probe(timeout time.Duration) error {
+	if !t.alive {
+		return errors.New("no echo")
+	}
+	return nil
+}
+
+func (t *fakeTarget) ErrorLogs() uint64 { return 0 }
+
+func (t *fakeTarget) Restart() error {
+	t.restarts++
+	t.alive = true
+	return nil
+}
+
+func TestInput_Encode_Cut(t *testing.T) {
+	in := SeedFromPayload(protocol.PacketTypeDebugAny, []byte("hello"))
+	full := in.Encode()
+
+	in.Cut = 3
+	assert.Equal(t, full[:len(full)-3], in.Encode())
+
+	in.Cut = len(full) + 10
+	assert.Empty(t, in.Encode())
+}
+
+func TestMutate_DoesNotChangeSeed(t *testing.T) {
+	seed := SeedFromPayload(protocol.PacketTypeDebugAny, []byte("hello"))
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		_, applied := Mutate(r, seed, 3)
+		assert.NotEmpty(t, applied)
+		assert.LessOrEqual(t, len(applied), 3)
+	}
+	assert.Equal(t, []byte("hello"), seed.Payload)
+}
+
+func TestMutate_Deterministic(t *testing.T) {
+	seed := SeedFromPayload(protocol.PacketTypeDebugAny, []byte("hello"))
+
+	a, appliedA := Mutate(rand.New(rand.NewSource(42)), seed, 3)
+	b, appliedB := Mutate(rand.New(rand.NewSource(42)), seed, 3)
+
+	assert.Equal(t, appliedA, appliedB)
+	assert.Equal(t, a.Encode(), b.Encode())
+}
+
+func TestMinimize(t *testing.T) {
+	input := []byte("aaaaXbbbbbbbYcccc")
+
+	result := Minimize(input, func(b []byte) bool {
+		return bytes.IndexByte(b, 'X') >= 0 && bytes.IndexByte(b, 'Y') >= 0
+	}, 1000)
+
+	assert.Equal(t, []byte("XY"), result)
+}
+
+func TestMinimize_Budget(t *testing.T) {
+	calls := 0
+	Minimize(bytes.Repeat([]byte{1}, 64), func(b []byte) bool {
+		calls++
+		return false
+	}, 5)
+
+	assert.Equal(t, 5, calls)
+}
+
+func TestLoadCorpus(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b"), []byte("second"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), []byte("first"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+
+	seeds, err := LoadCorpus(dir, protocol.PacketTypeDebugAny)
+
+	require.NoError(t, err)
+	require.Len(t, seeds, 2)
+	assert.Equal(t, []byte("first"), seeds[0].Payload)
+	assert.Equal(t, uint32(5), seeds[0].Header.Length)
+	assert.Equal(t, []byte("second"), seeds[1].Payload)
+}
+
+func TestNew_NoSeeds(t *testing.T) {
+	_, err := New(Config{}, &fakeTarget{alive: true}, nil)
+	assert.Error(t, err)
+}
+
+func TestFuzzer_Run_FindsAndMinimizesCrash(t *testing.T) {
+	target := &fakeTarget{crashBelow: 4, alive: true}
+	seeds := []Input{SeedFromPayload(protocol.PacketTypeDebugAny, []byte("hello world"))}
+	dir := t.TempDir()
+
+	fuzzer, err := New(Config{Iterations: 200, Seed: 7, CrashDir: dir}, target, seeds)
+	require.NoError(t, err)
+	summary := fuzzer.Run(context.Background())
+
+	assert.False(t, summary.Running)
+	assert.Equal(t, 200, summary.Executed)
+	require.NotEmpty(t, summary.Findings)
+	finding := summary.Findings[0]
+	assert.Equal(t, FindingCrash, finding.Kind)
+	assert.Less(t, len(finding.Minimized), 4)
+	assert.FileExists(t, finding.File)
+	assert.True(t, target.alive, "target should be restarted after a crash")
+}
+
+func TestFuzzer_Run_Cancelled(t *testing.T) {
+	target := &fakeTarget{alive: true}
+	seeds := []Input{SeedFromPayload(protocol.PacketTypeDebugAny, []byte("hello"))}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fuzzer, err := New(Config{Iterations: 100}, target, seeds)
+	require.NoError(t, err)
+	summary := fuzzer.Run(ctx)
+
+	assert.Equal(t, 0, summary.Executed)
+	assert.Equal(t, 0, target.sent)
+}
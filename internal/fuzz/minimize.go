@@ -0,0 +1,33 @@
+package fuzz
+
+// Minimize shrinks b while reproduces keeps returning true. It removes chunks
+// of halving size until no single byte can be dropped any more, or until
+// budget calls to reproduces have been made.
+func Minimize(b []byte, reproduces func([]byte) bool, budget int) []byte {
+	current := append([]byte(nil), b...)
+	for chunk := len(current) / 2; chunk >= 1; {
+		removed := false
+		for start := 0; start+chunk <= len(current); {
+			if budget <= 0 {
+				return current
+			}
+			budget--
+			candidate := make([]byte, 0, len(current)-chunk)
+			candidate = append(candidate, current[:start]...)
+			candidate = append(candidate, current[start+chunk:]...)
+			if reproduces(candidate) {
+				current = candidate
+				removed = true
+				continue
+			}
+			start += chunk
+		}
+		if !removed {
+			chunk /= 2
+		}
+		if chunk > len(current)/2 {
+			chunk = len(current) / 2
+		}
+	}
+	return current
+}
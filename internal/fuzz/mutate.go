@@ -0,0 +1,123 @@
+package fuzz
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/auraspeak/protocol"
+)
+
+// Input is one fuzz case: a protocol packet plus the number of bytes cut from
+// the end of its encoding
+type Input struct {
+	Header  protocol.Header
+	Payload []byte
+	Cut     int
+}
+
+// Encode returns the bytes that go on the wire
+func (in Input) Encode() []byte {
+	packet := &protocol.Packet{
+		PacketHeader: in.Header,
+		Payload:      in.Payload,
+	}
+	b := packet.Encode()
+	if in.Cut > 0 {
+		b = b[:len(b)-min(in.Cut, len(b))]
+	}
+	return b
+}
+
+// SeedFromPayload wraps payload in a valid header of the given packet type
+func SeedFromPayload(packetType protocol.PacketType, payload []byte) Input {
+	return Input{
+		Header: protocol.Header{
+			Magic:      protocol.Magic,
+			Version:    protocol.Version,
+			PacketType: packetType,
+			Length:     uint32(len(payload)),
+		},
+		Payload: payload,
+	}
+}
+
+// Mutator changes one aspect of an input
+type Mutator struct {
+	Name  string
+	Apply func(r *rand.Rand, in Input) Input
+}
+
+// Mutators are the mutations the fuzzer picks from
+var Mutators = []Mutator{
+	{Name: "bitflip", Apply: mutateBitFlip},
+	{Name: "truncate", Apply: mutateTruncate},
+	{Name: "length", Apply: mutateLength},
+	{Name: "type", Apply: mutateType},
+	{Name: "magic", Apply: mutateMagic},
+	{Name: "version", Apply: mutateVersion},
+}
+
+// Mutate applies between one and maxMutations random mutators to a copy of in
+// and returns the names of the applied mutators
+func Mutate(r *rand.Rand, in Input, maxMutations int) (Input, []string) {
+	out := in
+	out.Payload = append([]byte(nil), in.Payload...)
+	if maxMutations < 1 {
+		maxMutations = 1
+	}
+	n := 1 + r.Intn(maxMutations)
+	applied := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		m := Mutators[r.Intn(len(Mutators))]
+		out = m.Apply(r, out)
+		applied = append(applied, m.Name)
+	}
+	return out, applied
+}
+
+func mutateBitFlip(r *rand.Rand, in Input) Input {
+	if len(in.Payload) == 0 {
+		return in
+	}
+	i := r.Intn(len(in.Payload))
+	in.Payload[i] ^= 1 << r.Intn(8)
+	return in
+}
+
+func mutateTruncate(r *rand.Rand, in Input) Input {
+	size := len(in.Encode()) + in.Cut
+	if size == 0 {
+		return in
+	}
+	in.Cut = 1 + r.Intn(size)
+	return in
+}
+
+// mutateLength makes the header length lie about the payload size
+func mutateLength(r *rand.Rand, in Input) Input {
+	actual := uint32(len(in.Payload))
+	candidates := []uint32{0, actual + 1, actual - 1, actual * 2, math.MaxUint32, r.Uint32()}
+	in.Header.Length = candidates[r.Intn(len(candidates))]
+	return in
+}
+
+func mutateType(r *rand.Rand, in Input) Input {
+	flipLowBit(&in.Header.PacketType, r)
+	return in
+}
+
+func mutateMagic(r *rand.Rand, in Input) Input {
+	flipLowBit(&in.Header.Magic, r)
+	return in
+}
+
+func mutateVersion(r *rand.Rand, in Input) Input {
+	flipLowBit(&in.Header.Version, r)
+	return in
+}
+
+// flipLowBit flips one of the lowest eight bits of a header field, which is
+// valid for every unsigned field width
+func flipLowBit[T ~uint8 | ~uint16 | ~uint32 | ~uint64](field *T, r *rand.Rand) {
+	*field ^= T(1) << r.Intn(8)
+}
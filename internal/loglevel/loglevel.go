@@ -0,0 +1,190 @@
+// Package loglevel changes the log level of a logger at runtime, globally
+// and per caller or client.
+package loglevel
+
+import (
+	"maps"
+	"sync"
+
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	log "github.com/sirupsen/logrus"
+)
+
+// Callers whose level can be set. They are the values of the "caller" log
+// field; other values are accepted as well. Entries of the AuraSpeak server
+// and client libraries get CallerServer and CallerClient by the package
+// that logged them, see logstore.Caller and standard. Client levels apply
+// to entries naming their client, e.g. with a cid field; the client
+// library's own entries don't, for them the CallerClient level applies.
+const (
+	CallerWeb    = "web"
+	CallerServer = "server"
+	CallerClient = "client"
+)
+
+// Levels is a snapshot of the configured levels
+type Levels struct {
+	Global  log.Level
+	Callers map[string]log.Level
+	Clients map[int]log.Level
+}
+
+// Controller decides which entries are logged. The most specific level
+// wins: the client's level, then the caller's level, then the global one.
+//
+// logrus only knows a single level, so the logger is set to the most
+// verbose configured level and the controller drops the other entries in
+// its formatter. Hooks have to ask Enabled themselves. While the controller
+// is installed, the logger also receives the entries of the standard logger,
+// see standard.
+type Controller struct {
+	mu      sync.RWMutex
+	logger  *log.Logger
+	global  log.Level
+	callers map[string]log.Level
+	clients map[int]log.Level
+	// formatter the logger had before Install
+	formatter log.Formatter
+}
+
+// New returns a controller for logger, starting at its current level
+func New(logger *log.Logger) *Controller {
+	return &Controller{
+		logger:  logger,
+		global:  logger.GetLevel(),
+		callers: make(map[string]log.Level),
+		clients: make(map[int]log.Level),
+	}
+}
+
+// Install wraps the formatter of the logger, so entries below their
+// effective level are dropped, and forwards the entries of the standard
+// logger to it. Controllers of the standard logger itself don't forward.
+func (c *Controller) Install() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.formatter != nil {
+		return
+	}
+	c.formatter = c.logger.Formatter
+	c.logger.SetFormatter(&filterFormatter{controller: c, next: c.formatter})
+	c.applyLocked()
+	if c.logger != log.StandardLogger() {
+		standard.add(c.logger)
+	}
+}
+
+// Uninstall restores the formatter and the global level of the logger and
+// stops forwarding to it. Controllers may be uninstalled in any order.
+func (c *Controller) Uninstall() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.formatter == nil {
+		return
+	}
+	c.logger.SetFormatter(c.formatter)
+	c.logger.SetLevel(c.global)
+	c.formatter = nil
+	standard.remove(c.logger)
+}
+
+func (c *Controller) SetGlobal(level log.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.global = level
+	c.applyLocked()
+}
+
+// SetCaller sets the level of a caller, nil removes it
+func (c *Controller) SetCaller(caller string, level *log.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if level == nil {
+		delete(c.callers, caller)
+	} else {
+		c.callers[caller] = *level
+	}
+	c.applyLocked()
+}
+
+// SetClient sets the level of a single client, nil removes it
+func (c *Controller) SetClient(id int, level *log.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if level == nil {
+		delete(c.clients, id)
+	} else {
+		c.clients[id] = *level
+	}
+	c.applyLocked()
+}
+
+// Levels returns the configured levels
+func (c *Controller) Levels() Levels {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Levels{
+		Global:  c.global,
+		Callers: maps.Clone(c.callers),
+		Clients: maps.Clone(c.clients),
+	}
+}
+
+// Effective returns the level that applies to a caller and an optional client
+func (c *Controller) Effective(caller string, clientID *int) log.Level {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.effectiveLocked(caller, clientID)
+}
+
+// Enabled reports whether an entry is at or above its effective level
+func (c *Controller) Enabled(entry *log.Entry) bool {
+	caller := logstore.Caller(entry)
+	var clientID *int
+	if id, ok := logstore.ClientID(entry.Data); ok {
+		clientID = &id
+	}
+	return entry.Level <= c.Effective(caller, clientID)
+}
+
+func (c *Controller) effectiveLocked(caller string, clientID *int) log.Level {
+	if clientID != nil {
+		if level, ok := c.clients[*clientID]; ok {
+			return level
+		}
+	}
+	if level, ok := c.callers[caller]; ok {
+		return level
+	}
+	return c.global
+}
+
+// applyLocked lets the logger pass the most verbose configured level
+func (c *Controller) applyLocked() {
+	if c.formatter == nil {
+		return
+	}
+	level := c.global
+	for _, l := range c.callers {
+		level = max(level, l)
+	}
+	for _, l := range c.clients {
+		level = max(level, l)
+	}
+	c.logger.SetLevel(level)
+	standard.apply()
+}
+
+// filterFormatter formats nothing for dropped entries, so logrus writes
+// nothing. The others get their caller field.
+type filterFormatter struct {
+	controller *Controller
+	next       log.Formatter
+}
+
+func (f *filterFormatter) Format(entry *log.Entry) ([]byte, error) {
+	if !f.controller.Enabled(entry) {
+		return nil, nil
+	}
+	return f.next.Format(logstore.WithCaller(entry, true))
+}
@@ -0,0 +1,134 @@
+package loglevel
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLogger() (*log.Logger, *bytes.Buffer) {
+	var out bytes.Buffer
+	logger := log.New()
+	logger.Out = &out
+	logger.SetLevel(log.InfoLevel)
+	logger.SetFormatter(&log.TextFormatter{DisableTimestamp: true})
+	return logger, &out
+}
+
+// install installs a controller for logger until the test ends
+func install(t *testing.T, logger *log.Logger) *Controller {
+	t.Helper()
+	c := New(logger)
+	c.Install()
+	t.Cleanup(c.Uninstall)
+	return c
+}
+
+func TestController_PerCallerLevel(t *testing.T) {
+	logger, out := newLogger()
+	c := install(t, logger)
+	debug := log.DebugLevel
+	c.SetCaller(CallerServer, &debug)
+
+	logger.WithField("caller", CallerServer).Debug("server debug")
+	logger.WithField("caller", CallerWeb).Debug("web debug")
+	logger.WithField("caller", CallerWeb).Info("web info")
+
+	assert.Contains(t, out.String(), "server debug")
+	assert.NotContains(t, out.String(), "web debug")
+	assert.Contains(t, out.String(), "web info")
+	assert.Equal(t, log.DebugLevel, logger.GetLevel())
+}
+
+func TestController_ClientLevelWins(t *testing.T) {
+	logger, out := newLogger()
+	c := install(t, logger)
+	warn, trace := log.WarnLevel, log.TraceLevel
+	c.SetCaller(CallerClient, &warn)
+	c.SetClient(3, &trace)
+
+	logger.WithFields(log.Fields{"caller": CallerClient, "cid": 3}).Debug("client 3")
+	logger.WithFields(log.Fields{"caller": CallerClient, "cid": 4}).Info("client 4")
+
+	assert.Contains(t, out.String(), "client 3")
+	assert.NotContains(t, out.String(), "client 4")
+
+	c.SetClient(3, nil)
+	id := 3
+	assert.Equal(t, log.WarnLevel, c.Effective(CallerClient, &id))
+}
+
+func TestController_LibraryCallers(t *testing.T) {
+	logger, out := newLogger()
+	c := install(t, logger)
+	debug := log.DebugLevel
+	c.SetCaller(CallerServer, &debug)
+
+	// Entries of the libraries are told apart by the function that logged them
+	entry := log.NewEntry(logger)
+	entry.Level = log.DebugLevel
+	entry.Caller = &runtime.Frame{Function: "github.com/auraspeak/server.(*Server).Run"}
+	assert.True(t, c.Enabled(entry))
+	entry.Caller = &runtime.Frame{Function: "github.com/auraspeak/client.(*Client).Run"}
+	assert.False(t, c.Enabled(entry))
+
+	logger.Info("own entry")
+	assert.Contains(t, out.String(), "own entry")
+	assert.NotContains(t, out.String(), "func=")
+}
+
+func TestController_ForwardsStandardLogger(t *testing.T) {
+	std := log.StandardLogger()
+	var stdOut bytes.Buffer
+	out := std.Out
+	std.SetOutput(&stdOut)
+	defer std.SetOutput(out)
+	formatter, level := std.Formatter, std.GetLevel()
+	first, firstOut := newLogger()
+	second, secondOut := newLogger()
+	a, b := New(first), New(second)
+	a.Install()
+	b.Install()
+	a.SetGlobal(log.DebugLevel)
+
+	// The standard logger reports callers and lets pass the most verbose level
+	assert.True(t, std.ReportCaller)
+	assert.Equal(t, log.DebugLevel, std.GetLevel())
+	log.WithField("caller", CallerServer).Debug("library debug")
+	log.WithField("caller", CallerServer).Info("library info")
+
+	// Each logger writes the entries its own levels let pass, the standard logger nothing
+	assert.Contains(t, firstOut.String(), "library debug")
+	assert.Contains(t, firstOut.String(), "library info")
+	assert.NotContains(t, secondOut.String(), "library debug")
+	assert.Contains(t, secondOut.String(), "library info")
+	assert.Empty(t, stdOut.String())
+	assert.Equal(t, log.InfoLevel, second.GetLevel())
+
+	// Uninstalling out of order restores the standard logger once both are gone
+	a.Uninstall()
+	assert.Equal(t, log.InfoLevel, std.GetLevel())
+	b.Uninstall()
+	assert.Equal(t, formatter, std.Formatter)
+	assert.Equal(t, level, std.GetLevel())
+	assert.False(t, std.ReportCaller)
+	log.Info("after uninstall")
+	assert.Contains(t, stdOut.String(), "after uninstall")
+	assert.NotContains(t, firstOut.String(), "after uninstall")
+}
+
+func TestController_Uninstall(t *testing.T) {
+	logger, out := newLogger()
+	c := install(t, logger)
+	c.SetGlobal(log.ErrorLevel)
+	c.Uninstall()
+
+	logger.Error("after uninstall")
+
+	assert.Contains(t, out.String(), "after uninstall")
+	assert.Equal(t, log.ErrorLevel, logger.GetLevel())
+	assert.Equal(t, log.ErrorLevel, c.Levels().Global)
+}
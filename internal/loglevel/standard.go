@@ -0,0 +1,136 @@
+package loglevel
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	log "github.com/sirupsen/logrus"
+)
+
+// standard forwards the entries of the standard logger, which the AuraSpeak
+// libraries log to, to the loggers of the installed controllers. Each server
+// logs its own entries to its own logger, but the standard logger is shared
+// by all servers of the process, so every installed controller receives the
+// entries of the libraries of all of them.
+//
+// While a controller is installed the standard logger reports callers, so
+// the entries of the libraries can be told apart, and writes nothing itself:
+// the forwarded entries are written by the loggers of the controllers. Its
+// level lets pass the most verbose level of their loggers. The first
+// installed controller saves its settings, the last one restores them.
+var standard = &forwarder{}
+
+type forwarder struct {
+	mu      sync.Mutex
+	loggers []*log.Logger
+	// Settings of the standard logger before the first controller was installed
+	formatter    log.Formatter
+	level        log.Level
+	reportCaller bool
+}
+
+// NewLogger returns a logger that writes like the standard logger, with the
+// settings it had before controllers were installed
+func NewLogger() *log.Logger {
+	std := log.StandardLogger()
+	logger := log.New()
+	logger.SetOutput(std.Out)
+	standard.mu.Lock()
+	defer standard.mu.Unlock()
+	if len(standard.loggers) > 0 {
+		logger.SetFormatter(standard.formatter)
+		logger.SetLevel(standard.level)
+	} else {
+		logger.SetFormatter(std.Formatter)
+		logger.SetLevel(std.GetLevel())
+	}
+	return logger
+}
+
+func (f *forwarder) add(logger *log.Logger) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	std := log.StandardLogger()
+	if len(f.loggers) == 0 {
+		f.formatter = std.Formatter
+		f.level = std.GetLevel()
+		f.reportCaller = std.ReportCaller
+		std.SetFormatter(discardFormatter{})
+		std.SetReportCaller(true)
+		std.AddHook(f)
+	}
+	f.loggers = append(f.loggers, logger)
+	f.applyLocked()
+}
+
+func (f *forwarder) remove(logger *log.Logger) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := len(f.loggers)
+	f.loggers = slices.DeleteFunc(f.loggers, func(l *log.Logger) bool { return l == logger })
+	if len(f.loggers) == n {
+		return
+	}
+	if len(f.loggers) > 0 {
+		f.applyLocked()
+		return
+	}
+	std := log.StandardLogger()
+	logstore.RemoveHook(std, f)
+	std.SetFormatter(f.formatter)
+	std.SetReportCaller(f.reportCaller)
+	std.SetLevel(f.level)
+}
+
+// apply updates the level of the standard logger after the level of a
+// logger changed
+func (f *forwarder) apply() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.applyLocked()
+}
+
+func (f *forwarder) applyLocked() {
+	if len(f.loggers) == 0 {
+		return
+	}
+	level := log.PanicLevel
+	for _, logger := range f.loggers {
+		level = max(level, logger.GetLevel())
+	}
+	log.StandardLogger().SetLevel(level)
+}
+
+func (f *forwarder) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire logs the entry to the loggers of the controllers with its caller
+// field, the function that logged it is only known to the standard logger
+func (f *forwarder) Fire(entry *log.Entry) error {
+	f.mu.Lock()
+	loggers := slices.Clone(f.loggers)
+	f.mu.Unlock()
+	tagged := logstore.WithCaller(entry, false)
+	for _, logger := range loggers {
+		forward(logger, tagged)
+	}
+	return nil
+}
+
+// forward logs entry to logger. Panic entries don't panic a second time, the
+// standard logger panics after its hooks ran.
+func forward(logger *log.Logger, entry *log.Entry) {
+	if entry.Level == log.PanicLevel {
+		defer func() { recover() }()
+	}
+	logger.WithFields(entry.Data).WithTime(entry.Time).WithContext(entry.Context).Log(entry.Level, entry.Message)
+}
+
+// discardFormatter formats nothing, so logrus writes nothing
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*log.Entry) ([]byte, error) {
+	return nil, nil
+}
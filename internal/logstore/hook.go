@@ -0,0 +1,63 @@
+package logstore
+
+import (
+	"encoding/json"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Record is a log entry on its way to the store and the websocket hub
+type Record struct {
+	// Entry is stored, its ID is set by the store
+	Entry Entry `json:"entry"`
+	// JSON is the entry as sent to the websocket connections
+	JSON json.RawMessage `json:"json"`
+}
+
+// Hook is a logrus hook that publishes every entry as Record, e.g. to the
+// event bus the store subscribes to. Entries rejected by the optional
+// enabled func are skipped.
+type Hook struct {
+	publish   func(Record)
+	enabled   func(*log.Entry) bool
+	formatter log.Formatter
+}
+
+func NewHook(publish func(Record), enabled func(*log.Entry) bool) *Hook {
+	return &Hook{
+		publish:   publish,
+		enabled:   enabled,
+		formatter: &log.JSONFormatter{},
+	}
+}
+
+func (h *Hook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *Hook) Fire(entry *log.Entry) error {
+	if h.enabled != nil && !h.enabled(entry) {
+		return nil
+	}
+	b, err := h.formatter.Format(WithCaller(entry, false))
+	if err != nil {
+		return err
+	}
+	h.publish(Record{Entry: NewEntry(entry), JSON: b})
+	return nil
+}
+
+// RemoveHook removes hook from logger. logrus can only replace the hooks as
+// a whole, ReplaceHooks hands out the current ones under the logger's lock.
+func RemoveHook(logger *log.Logger, hook log.Hook) {
+	current := logger.ReplaceHooks(make(log.LevelHooks))
+	hooks := make(log.LevelHooks)
+	for level, levelHooks := range current {
+		for _, h := range levelHooks {
+			if h != hook {
+				hooks[level] = append(hooks[level], h)
+			}
+		}
+	}
+	logger.ReplaceHooks(hooks)
+}
@@ -0,0 +1,265 @@
+// Package logstore keeps the recent log entries in memory, so late or
+// reloading clients can query what they missed.
+package logstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultCapacity is the number of entries a store keeps by default
+const DefaultCapacity = 10000
+
+// clientIDFields are the log fields that name the client an entry belongs to
+var clientIDFields = []string{"cid", "clientId", "clientID", "client_id"}
+
+// libraryCallers are the callers of the entries the AuraSpeak libraries log
+// without caller field, by module
+var libraryCallers = []struct{ module, caller string }{
+	{"github.com/auraspeak/server", "server"},
+	{"github.com/auraspeak/client", "client"},
+}
+
+// Entry is a stored log entry
+type Entry struct {
+	ID       uint64         `json:"id"`
+	Time     time.Time      `json:"time"`
+	Level    log.Level      `json:"-"`
+	LevelStr string         `json:"level"`
+	Message  string         `json:"msg"`
+	Caller   string         `json:"caller,omitempty"`
+	ClientID *int           `json:"clientId,omitempty"`
+	Fields   map[string]any `json:"fields,omitempty"`
+}
+
+// Query selects entries. Zero values don't filter.
+type Query struct {
+	// MinLevel keeps entries at least this severe. Severity rises towards
+	// log.PanicLevel, so the zero value PanicLevel is not usable as filter;
+	// use HasLevel to enable it.
+	MinLevel log.Level
+	HasLevel bool
+	Caller   string
+	ClientID *int
+	Since    time.Time
+	Until    time.Time
+	// Text is searched case-insensitively in the message and field values
+	Text string
+	// Limit returns only the newest matching entries, 0 returns all
+	Limit int
+}
+
+// Store is a bounded, concurrency-safe list of log entries. The oldest
+// entries are dropped when it is full.
+type Store struct {
+	mu      sync.RWMutex
+	entries *util.Ring[Entry]
+	lastID  uint64
+}
+
+func New(capacity int) *Store {
+	if capacity < 1 {
+		capacity = DefaultCapacity
+	}
+	return &Store{entries: util.NewRing[Entry](capacity)}
+}
+
+// Add stores an entry built from a logrus entry and returns it
+func (s *Store) Add(entry *log.Entry) Entry {
+	return s.Append(NewEntry(entry))
+}
+
+// NewEntry builds an entry from a logrus entry, without ID
+func NewEntry(entry *log.Entry) Entry {
+	e := Entry{
+		Time:     entry.Time,
+		Level:    entry.Level,
+		LevelStr: entry.Level.String(),
+		Message:  entry.Message,
+	}
+	if len(entry.Data) > 0 {
+		e.Fields = make(map[string]any, len(entry.Data))
+		for k, v := range entry.Data {
+			e.Fields[k] = fieldValue(v)
+		}
+	}
+	e.Caller = Caller(entry)
+	if id, ok := ClientID(entry.Data); ok {
+		e.ClientID = &id
+	}
+	return e
+}
+
+// Append stores e with the next ID and returns it
+func (s *Store) Append(e Entry) Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	e.ID = s.lastID
+	s.entries.Push(e)
+	return e
+}
+
+// Query returns the matching entries, oldest first
+func (s *Store) Query(q Query) []Entry {
+	text := strings.ToLower(q.Text)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	matching := []Entry{}
+	for e := range s.entries.All() {
+		if q.match(e, text) {
+			matching = append(matching, e)
+		}
+	}
+	if q.Limit > 0 && len(matching) > q.Limit {
+		matching = matching[len(matching)-q.Limit:]
+	}
+	return matching
+}
+
+// Clear removes all entries and returns how many there were
+func (s *Store) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries.Clear()
+}
+
+// Len returns the number of stored entries
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.entries.Len()
+}
+
+func (q Query) match(e Entry, text string) bool {
+	if q.HasLevel && e.Level > q.MinLevel {
+		return false
+	}
+	if q.Caller != "" && e.Caller != q.Caller {
+		return false
+	}
+	if q.ClientID != nil && (e.ClientID == nil || *e.ClientID != *q.ClientID) {
+		return false
+	}
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Time.After(q.Until) {
+		return false
+	}
+	if text == "" || strings.Contains(strings.ToLower(e.Message), text) {
+		return true
+	}
+	for _, v := range e.Fields {
+		if strings.Contains(strings.ToLower(toString(v)), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// Caller returns the caller field of an entry. The AuraSpeak server and
+// client libraries don't set it, their entries are told apart by the
+// function that logged them; that needs the logger's ReportCaller.
+func Caller(entry *log.Entry) string {
+	if caller, ok := entry.Data["caller"]; ok {
+		return toString(caller)
+	}
+	if entry.Caller == nil {
+		return ""
+	}
+	for _, lib := range libraryCallers {
+		rest, ok := strings.CutPrefix(entry.Caller.Function, lib.module)
+		if ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/")) {
+			return lib.caller
+		}
+	}
+	return ""
+}
+
+// WithCaller returns a copy of entry that has the caller field of Caller.
+// The reported function is dropped unless keepFunc is set.
+func WithCaller(entry *log.Entry, keepFunc bool) *log.Entry {
+	e := *entry
+	if !keepFunc {
+		e.Caller = nil
+	}
+	if _, ok := entry.Data["caller"]; !ok {
+		if caller := Caller(entry); caller != "" {
+			e.Data = make(log.Fields, len(entry.Data)+1)
+			maps.Copy(e.Data, entry.Data)
+			e.Data["caller"] = caller
+		}
+	}
+	return &e
+}
+
+// ClientID returns the client a log entry belongs to, if its fields name one
+func ClientID(data log.Fields) (int, bool) {
+	for _, field := range clientIDFields {
+		if id, ok := toInt(data[field]); ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// fieldValue makes a log field value safe to encode as JSON later: strings,
+// booleans and integers stay as they are, errors become their message and
+// other values their JSON encoding at the time of logging, or fmt.Sprint if
+// they can't be encoded
+func fieldValue(v any) any {
+	switch v := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v
+	case error:
+		return v.Error()
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return json.RawMessage(b)
+	}
+	return fmt.Sprint(v)
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.RawMessage:
+		return string(v)
+	case error:
+		return v.Error()
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toInt(v any) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case uint16:
+		return int(v), true
+	case uint32:
+		return int(v), true
+	case string:
+		id, err := strconv.Atoi(v)
+		return id, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,195 @@
+package logstore
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"runtime"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func entry(level log.Level, msg string, at time.Time, data log.Fields) *log.Entry {
+	e := log.NewEntry(log.StandardLogger()).WithFields(data)
+	e.Level = level
+	e.Message = msg
+	e.Time = at
+	return e
+}
+
+func TestStore_AddDropsOldest(t *testing.T) {
+	s := New(2)
+	now := time.Now()
+	s.Add(entry(log.InfoLevel, "one", now, nil))
+	s.Add(entry(log.InfoLevel, "two", now, nil))
+	s.Add(entry(log.InfoLevel, "three", now, nil))
+
+	entries := s.Query(Query{})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "two", entries[0].Message)
+	assert.Equal(t, uint64(3), entries[1].ID)
+}
+
+func TestStore_AddExtractsCallerAndClient(t *testing.T) {
+	s := New(0)
+	e := s.Add(entry(log.ErrorLevel, "failed", time.Now(), log.Fields{
+		"caller": "UDP Client",
+		"cid":    7,
+		"error":  errors.New("boom"),
+	}))
+
+	assert.Equal(t, "UDP Client", e.Caller)
+	require.NotNil(t, e.ClientID)
+	assert.Equal(t, 7, *e.ClientID)
+	assert.Equal(t, "boom", e.Fields["error"])
+	assert.Equal(t, "error", e.LevelStr)
+}
+
+func TestStore_AddMakesFieldsJSONSafe(t *testing.T) {
+	s := New(0)
+	peer := map[string]int{"port": 9000}
+	e := s.Add(entry(log.InfoLevel, "fields", time.Now(), log.Fields{
+		"peer":  peer,
+		"ratio": math.NaN(),
+		"stop":  func() {},
+	}))
+	peer["port"] = 1
+
+	b, err := json.Marshal(s.Query(Query{}))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"peer":{"port":9000}`)
+	assert.Equal(t, "NaN", e.Fields["ratio"])
+	assert.Len(t, s.Query(Query{Text: "9000"}), 1)
+}
+
+func BenchmarkStore_AddFull(b *testing.B) {
+	s := New(DefaultCapacity)
+	e := entry(log.InfoLevel, "bench", time.Now(), log.Fields{"caller": "web"})
+	for range DefaultCapacity {
+		s.Add(e)
+	}
+	for b.Loop() {
+		s.Add(e)
+	}
+}
+
+func TestCaller(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   log.Fields
+		function string
+		caller   string
+	}{
+		{"field", log.Fields{"caller": "web"}, "github.com/auraspeak/server.(*Server).Run", "web"},
+		{"server", nil, "github.com/auraspeak/server.(*Server).Run", "server"},
+		{"server package", nil, "github.com/auraspeak/server/pkg/tracer.New", "server"},
+		{"client", nil, "github.com/auraspeak/client.(*Client).Send", "client"},
+		{"other module", nil, "github.com/auraspeak/serverless.Run", ""},
+		{"debug-ui", nil, "github.com/auraspeak/debug-ui/app.(*Server).Run", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := log.NewEntry(log.StandardLogger()).WithFields(tt.fields)
+			e.Caller = &runtime.Frame{Function: tt.function}
+			assert.Equal(t, tt.caller, Caller(e))
+		})
+	}
+}
+
+func TestWithCaller(t *testing.T) {
+	e := log.NewEntry(log.StandardLogger()).WithField("cid", 2)
+	e.Caller = &runtime.Frame{Function: "github.com/auraspeak/client.(*Client).Run"}
+
+	tagged := WithCaller(e, false)
+
+	assert.Equal(t, log.Fields{"cid": 2, "caller": "client"}, tagged.Data)
+	assert.Nil(t, tagged.Caller)
+	assert.Equal(t, log.Fields{"cid": 2}, e.Data)
+	assert.NotNil(t, WithCaller(e, true).Caller)
+}
+
+func TestStore_Query(t *testing.T) {
+	s := New(0)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.Add(entry(log.DebugLevel, "debug web", base, log.Fields{"caller": "web"}))
+	s.Add(entry(log.WarnLevel, "warn client", base.Add(time.Minute), log.Fields{"caller": "UDP Client", "clientId": "3"}))
+	s.Add(entry(log.ErrorLevel, "error client", base.Add(2*time.Minute), log.Fields{"caller": "UDP Client", "clientId": 4, "peer": "Port 9000"}))
+
+	three := 3
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all", Query{}, []string{"debug web", "warn client", "error client"}},
+		{"min level", Query{MinLevel: log.WarnLevel, HasLevel: true}, []string{"warn client", "error client"}},
+		{"caller", Query{Caller: "web"}, []string{"debug web"}},
+		{"client", Query{ClientID: &three}, []string{"warn client"}},
+		{"since", Query{Since: base.Add(time.Minute)}, []string{"warn client", "error client"}},
+		{"until", Query{Until: base.Add(time.Minute)}, []string{"debug web", "warn client"}},
+		{"text in message", Query{Text: "WARN"}, []string{"warn client"}},
+		{"text in field", Query{Text: "port 9000"}, []string{"error client"}},
+		{"limit keeps newest", Query{Limit: 2}, []string{"warn client", "error client"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, e := range s.Query(tt.query) {
+				got = append(got, e.Message)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStore_Clear(t *testing.T) {
+	s := New(0)
+	s.Add(entry(log.InfoLevel, "one", time.Now(), nil))
+
+	assert.Equal(t, 1, s.Clear())
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestHook_Publishes(t *testing.T) {
+	s := New(0)
+	var published []Record
+	hook := NewHook(func(r Record) {
+		published = append(published, r)
+		s.Append(r.Entry)
+	}, func(e *log.Entry) bool { return e.Message != "skip" })
+	logger := log.New()
+	logger.AddHook(hook)
+	logger.Out = io.Discard
+
+	logger.WithField("caller", "web").Info("hello")
+	logger.Info("skip")
+
+	require.Len(t, published, 1)
+	assert.Contains(t, string(published[0].JSON), `"msg":"hello"`)
+	assert.Equal(t, "web", published[0].Entry.Caller)
+	stored := s.Query(Query{})
+	require.Len(t, stored, 1)
+	assert.Equal(t, uint64(1), stored[0].ID)
+	assert.Equal(t, "hello", stored[0].Message)
+}
+
+func TestRemoveHook(t *testing.T) {
+	logger := log.New()
+	hook := NewHook(func(Record) {}, nil)
+	other := NewHook(func(Record) {}, nil)
+	logger.AddHook(hook)
+	logger.AddHook(other)
+
+	RemoveHook(logger, hook)
+
+	for _, hooks := range logger.Hooks {
+		assert.NotContains(t, hooks, hook)
+		assert.Contains(t, hooks, other)
+	}
+}
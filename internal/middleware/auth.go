@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Role is what an authenticated request may do
+type Role string
+
+const (
+	// RoleAdmin may use every endpoint
+	RoleAdmin Role = "admin"
+	// RoleViewer may only read: GET requests and receiving WebSocket messages
+	RoleViewer Role = "viewer"
+)
+
+type roleKey struct{}
+
+// Auth checks bearer tokens. Without any token authentication is off and
+// every request is an admin request.
+type Auth struct {
+	AdminTokens  []string
+	ViewerTokens []string
+	// Logger of the failed attempts, nil for the standard logger
+	Logger *log.Logger
+}
+
+// Enabled reports whether any token is configured
+func (a Auth) Enabled() bool {
+	return len(a.AdminTokens) > 0 || len(a.ViewerTokens) > 0
+}
+
+// RoleOf returns the role a token grants
+func (a Auth) RoleOf(token string) (Role, bool) {
+	if token == "" {
+		return "", false
+	}
+	// Check all tokens, so the time doesn't tell which list matched
+	role, found := Role(""), false
+	for _, t := range a.ViewerTokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			role, found = RoleViewer, true
+		}
+	}
+	for _, t := range a.AdminTokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			role, found = RoleAdmin, true
+		}
+	}
+	return role, found
+}
+
+// RoleFromContext returns the role of a request, admin if auth is off
+func RoleFromContext(ctx context.Context) Role {
+	if role, ok := ctx.Value(roleKey{}).(Role); ok {
+		return role
+	}
+	return RoleAdmin
+}
+
+// CanWrite reports whether the request may change state
+func CanWrite(r *http.Request) bool {
+	return r == nil || RoleFromContext(r.Context()) == RoleAdmin
+}
+
+// requestToken reads the bearer token. Browsers can't set headers for
+// WebSockets and EventSources, so the token query parameter works too.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return r.URL.Query().Get("token")
+}
+
+func readOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// Wrap authenticates all requests to handler. Preflight requests pass, so
+// CORS can answer them; viewers get 403 for anything but reading.
+func (a Auth) Wrap(handler http.Handler) http.Handler {
+	if !a.Enabled() {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		role, ok := a.RoleOf(requestToken(r))
+		if !ok {
+			logAuthFailure(a.Logger, r, "missing or invalid token")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="debug-ui"`)
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "Unauthorized", "a valid bearer token is required")
+			return
+		}
+		if role == RoleViewer && !readOnlyMethod(r.Method) {
+			logAuthFailure(a.Logger, r, "viewer can't change state")
+			writeError(w, http.StatusForbidden, CodeForbidden, "Forbidden", "viewers have read-only access")
+			return
+		}
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleKey{}, role)))
+	})
+}
+
+// logAuthFailure logs a rejected request to logger, nil for the standard logger
+func logAuthFailure(logger *log.Logger, r *http.Request, reason string) {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	if logger == nil {
+		logger = log.StandardLogger()
+	}
+	Log(logger, r).WithFields(log.Fields{
+		"remote": remote,
+		"method": r.Method,
+		"path":   r.URL.Path,
+	}).Warnf("Authentication failed: %s", reason)
+}
@@ -1,31 +1,66 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+	"net/url"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+)
 
 func CorsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		// Call the next handler
-		next(w, r)
+		Cors{}.Wrap(next).ServeHTTP(w, r)
 	}
 }
 
 func CorsWrapper(handler http.Handler) http.Handler {
+	return Cors{}.Wrap(handler)
+}
+
+// Cors answers preflight requests and sets the CORS headers. Without
+// allowed origins every origin is allowed. Otherwise requests from other
+// origins, the UI served by this server aside, are rejected.
+type Cors struct {
+	AllowedOrigins []string
+	// Logger of the rejected requests, nil for the standard logger
+	Logger *log.Logger
+}
+
+func (c Cors) allowAll() bool {
+	return len(c.AllowedOrigins) == 0 || slices.Contains(c.AllowedOrigins, "*")
+}
+
+// sameOrigin reports whether origin is the host the request was sent to
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func (c Cors) Wrap(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		allowed := true
 		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		switch {
+		case c.allowAll():
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && slices.Contains(c.AllowedOrigins, origin):
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+		default:
+			allowed = origin == "" || sameOrigin(r, origin)
+			w.Header().Add("Vary", "Origin")
+		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "Deprecation, Link, X-Request-ID")
+
+		if !allowed {
+			logAuthFailure(c.Logger, r, "origin "+origin+" is not allowed")
+			writeError(w, http.StatusForbidden, CodeOriginNotAllowed, "Forbidden", "origin is not allowed")
+			return
+		}
 
 		// Handle preflight requests
 		if r.Method == "OPTIONS" {
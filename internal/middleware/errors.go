@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Error codes of the middleware, see api.ErrorCode
+const (
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeOriginNotAllowed = "origin_not_allowed"
+)
+
+// writeError writes the same JSON body as api.ApiError, which can't be used
+// here because the api package depends on this one
+func writeError(w http.ResponseWriter, code int, errorCode, message, details string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	b, _ := json.Marshal(struct {
+		Code      int    `json:"code"`
+		ErrorCode string `json:"errorCode"`
+		Message   string `json:"message,omitempty"`
+		Details   string `json:"details,omitempty"`
+		RequestID string `json:"requestId,omitempty"`
+	}{code, errorCode, message, details, w.Header().Get(RequestIDHeader)})
+	w.Write(b)
+	w.Write([]byte("\n"))
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern limits IDs taken over from the caller, so they are safe in logs
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+type requestIDKey struct{}
+
+// WithRequestID gives every request an ID, taken from the X-Request-ID
+// header if the caller sent a valid one. The ID is set on the response
+// before the handler runs, so error bodies can repeat it.
+func WithRequestID(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestID returns the ID of the request ctx belongs to, empty outside of WithRequestID
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Log returns an entry of logger for code handling r, with the caller web
+// and the context of r, see RequestIDHook
+func Log(logger *log.Logger, r *http.Request) *log.Entry {
+	return logger.WithContext(r.Context()).WithField("caller", "web")
+}
+
+// RequestIDHook adds the request ID of the entry's context as requestId
+// field, so every entry logged with the context of a request carries it.
+// It has to be added before hooks that read the fields.
+type RequestIDHook struct{}
+
+func (RequestIDHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (RequestIDHook) Fire(entry *log.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	if id := RequestID(entry.Context); id != "" {
+		entry.Data["requestId"] = id
+	}
+	return nil
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
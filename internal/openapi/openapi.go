@@ -0,0 +1,249 @@
+// Package openapi builds an OpenAPI 3 document from a list of routes and
+// the Go types they read and write.
+package openapi
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const Version = "3.0.3"
+
+// Document is an OpenAPI document, reduced to the parts debug-ui uses
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to operations
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Param is a query parameter of a route
+type Param struct {
+	Name        string
+	Description string
+	// Example value giving the type, a string if nil
+	Type     any
+	Required bool
+	// Repeated parameters can be given several times
+	Repeated bool
+}
+
+// Route describes an endpoint
+type Route struct {
+	Method  string
+	Path    string
+	Summary string
+	Tag     string
+	Query   []Param
+	// Request is the type of the JSON body, nil without body
+	Request any
+	// OptionalBody marks the request body as optional
+	OptionalBody bool
+	// Response is the type of the success response, nil for no content
+	Response any
+	// ContentType of the response, application/json if empty
+	ContentType string
+	// Deprecated routes get an operation ID with the suffix "Deprecated"
+	Deprecated bool
+}
+
+// Builder collects routes and the schemas of their types
+type Builder struct {
+	doc     Document
+	schemas *Schemas
+	// errorSchema is the body of every error response
+	errorSchema *Schema
+	// responseFields are in every JSON object of a success response
+	responseFields map[string]*Schema
+}
+
+// New returns a builder; errorType is the body of error responses
+func New(info Info, errorType any) *Builder {
+	schemas := NewSchemas()
+	b := &Builder{
+		doc: Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]PathItem{},
+			Components: Components{
+				Schemas: schemas.Components,
+				SecuritySchemes: map[string]SecurityScheme{
+					"bearer": {Type: "http", Scheme: "bearer"},
+				},
+			},
+		},
+		schemas: schemas,
+	}
+	b.errorSchema = schemas.For(errorType)
+	return b
+}
+
+// AddResponseField documents a field every JSON object of a success
+// response has besides the fields of its type. Routes added before don't
+// get it.
+func (b *Builder) AddResponseField(name string, schema *Schema) {
+	if b.responseFields == nil {
+		b.responseFields = map[string]*Schema{}
+	}
+	b.responseFields[name] = schema
+}
+
+// Add adds a route
+func (b *Builder) Add(route Route) {
+	op := &Operation{
+		Summary:     route.Summary,
+		OperationID: operationID(route.Method, route.Path),
+		Responses:   map[string]Response{},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Deprecated:  route.Deprecated,
+	}
+	if route.Deprecated {
+		op.OperationID += "Deprecated"
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	for _, p := range route.Query {
+		schema := b.schemas.For(p.Type)
+		if p.Type == nil {
+			schema = &Schema{Type: "string"}
+		}
+		if p.Repeated {
+			schema = &Schema{Type: "array", Items: schema}
+		}
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        p.Name,
+			In:          "query",
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      schema,
+		})
+	}
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: !route.OptionalBody,
+			Content:  map[string]MediaType{"application/json": {Schema: b.schemas.For(route.Request)}},
+		}
+	}
+
+	success := Response{Description: "Success"}
+	contentType := route.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	switch {
+	case route.Response != nil:
+		schema := b.schemas.For(route.Response)
+		if len(b.responseFields) > 0 && contentType == "application/json" && (schema.Ref != "" || schema.Type == "object") {
+			schema = &Schema{AllOf: []*Schema{schema, {Type: "object", Properties: b.responseFields}}}
+		}
+		success.Content = map[string]MediaType{contentType: {Schema: schema}}
+	case route.ContentType != "":
+		success.Content = map[string]MediaType{contentType: {Schema: &Schema{Type: "string"}}}
+	}
+	op.Responses["200"] = success
+	op.Responses["default"] = Response{
+		Description: "Error",
+		Content:     map[string]MediaType{"application/json": {Schema: b.errorSchema}},
+	}
+
+	item, ok := b.doc.Paths[route.Path]
+	if !ok {
+		item = PathItem{}
+		b.doc.Paths[route.Path] = item
+	}
+	item[strings.ToLower(route.Method)] = op
+}
+
+// Document returns the built document
+func (b *Builder) Document() *Document {
+	return &b.doc
+}
+
+// Has reports whether the document contains an operation
+func (d *Document) Has(method, path string) bool {
+	_, ok := d.Paths[path][strings.ToLower(method)]
+	return ok
+}
+
+// Operations returns "METHOD path" of every operation, sorted
+func (d *Document) Operations() []string {
+	ops := []string{}
+	for path, item := range d.Paths {
+		for method := range item {
+			ops = append(ops, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// versionPrefix is the version in paths like /api/v1/...
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+/`)
+
+// operationID turns "GET /api/v1/client/get/all" into "getClientGetAll"
+func operationID(method, path string) string {
+	path = strings.TrimPrefix(path, "/api")
+	path = versionPrefix.ReplaceAllString(path, "/")
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	for part := range strings.FieldsFuncSeq(path, func(r rune) bool {
+		return r == '/' || r == '.' || r == '-' || r == '{' || r == '}'
+	}) {
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type node struct {
+	Name     string            `json:"name"`
+	Note     string            `json:"note,omitempty"`
+	Created  time.Time         `json:"created,omitzero"`
+	Parent   *node             `json:"parent"`
+	Children []node            `json:"children"`
+	Labels   map[string]string `json:"labels"`
+	Data     []byte            `json:"data"`
+	Hidden   string            `json:"-"`
+	embedded
+}
+
+type embedded struct {
+	Level int `json:"level"`
+}
+
+func TestSchemas_Struct(t *testing.T) {
+	schemas := NewSchemas()
+
+	ref := schemas.For(node{})
+
+	assert.Equal(t, "#/components/schemas/node", ref.Ref)
+	schema := schemas.Components["node"]
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"name", "children", "labels", "data", "level"}, schema.Required)
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["created"])
+	assert.Equal(t, ref.Ref, schema.Properties["parent"].Ref)
+	assert.Equal(t, ref.Ref, schema.Properties["children"].Items.Ref)
+	assert.Equal(t, &Schema{Type: "string"}, schema.Properties["labels"].AdditionalProperties)
+	assert.Equal(t, "byte", schema.Properties["data"].Format)
+	assert.NotContains(t, schema.Properties, "Hidden")
+	assert.Contains(t, schema.Properties, "level")
+}
+
+func TestBuilder_Add(t *testing.T) {
+	b := New(Info{Title: "test", Version: "1"}, struct {
+		Message string `json:"message"`
+	}{})
+	b.Add(Route{
+		Method:   "GET",
+		Path:     "/api/client/get/all",
+		Query:    []Param{{Name: "tag", Repeated: true}, {Name: "page", Type: 0}},
+		Response: node{},
+	})
+
+	doc := b.Document()
+	assert.True(t, doc.Has("GET", "/api/client/get/all"))
+	assert.False(t, doc.Has("POST", "/api/client/get/all"))
+	op := doc.Paths["/api/client/get/all"]["get"]
+	assert.Equal(t, "getClientGetAll", op.OperationID)
+	assert.Equal(t, "array", op.Parameters[0].Schema.Type)
+	assert.Equal(t, "integer", op.Parameters[1].Schema.Type)
+	assert.Contains(t, op.Responses, "default")
+	assert.Equal(t, []string{"GET /api/client/get/all"}, doc.Operations())
+}
@@ -0,0 +1,138 @@
+package payload
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned if no template with the given name exists
+var ErrNotFound = errors.New("template not found")
+
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func validName(name string) bool {
+	return nameRe.MatchString(name)
+}
+
+// Store keeps templates as JSON files in a directory and counts the sequence
+// numbers per template and client
+type Store struct {
+	dir  string
+	mu   sync.Mutex
+	seqs map[string]uint64
+}
+
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:  dir,
+		mu:   sync.Mutex{},
+		seqs: make(map[string]uint64),
+	}
+}
+
+// List returns all templates sorted by name
+func (s *Store) List() ([]Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Template{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	templates := []Template{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || !entry.Type().IsRegular() || !validName(name) {
+			continue
+		}
+		t, err := s.read(name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// Get loads one template
+func (s *Store) Get(name string) (Template, error) {
+	if !validName(name) {
+		return Template{}, ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(name)
+}
+
+// Save validates and writes a template, an existing one is replaced
+func (s *Store) Save(t Template) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("create template dir: %w", err)
+	}
+	return os.WriteFile(s.path(t.Name), b, 0o644)
+}
+
+// Delete removes a template
+func (s *Store) Delete(name string) error {
+	if !validName(name) {
+		return ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// NextSeq returns the next sequence number of a template for a client,
+// starting at 0
+func (s *Store) NextSeq(name string, clientID int) uint64 {
+	key := fmt.Sprintf("%s/%d", name, clientID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seq := s.seqs[key]
+	s.seqs[key] = seq + 1
+	return seq
+}
+
+func (s *Store) read(name string) (Template, error) {
+	b, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return Template{}, ErrNotFound
+	}
+	if err != nil {
+		return Template{}, err
+	}
+	var t Template
+	if err := json.Unmarshal(b, &t); err != nil {
+		return Template{}, fmt.Errorf("template %s: %w", name, err)
+	}
+	t.Name = name
+	return t, nil
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
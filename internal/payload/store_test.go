@@ -0,0 +1,52 @@
+package payload
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SaveGetListDelete(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "templates"))
+
+	list, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, store.Save(Template{Name: "b", Format: "text", Body: "two"}))
+	require.NoError(t, store.Save(Template{Name: "a", Format: "hex", Body: "01"}))
+
+	tmpl, err := store.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "01", tmpl.Body)
+
+	list, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "a", list[0].Name)
+	assert.Equal(t, "b", list[1].Name)
+
+	require.NoError(t, store.Delete("a"))
+	_, err = store.Get("a")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Delete("a"), ErrNotFound)
+}
+
+func TestStore_SaveInvalid(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	err := store.Save(Template{Name: "x", Format: "hex", Body: "not hex"})
+
+	assert.Error(t, err)
+}
+
+func TestStore_NextSeq(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	assert.Equal(t, uint64(0), store.NextSeq("a", 1))
+	assert.Equal(t, uint64(1), store.NextSeq("a", 1))
+	assert.Equal(t, uint64(0), store.NextSeq("a", 2))
+	assert.Equal(t, uint64(0), store.NextSeq("b", 1))
+}
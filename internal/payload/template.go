@@ -0,0 +1,241 @@
+package payload
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRandomBytes is the largest N of {{random:N}}, the largest UDP payload
+const MaxRandomBytes = 65507
+
+// Template is a named payload with {{placeholders}}. Literal parts are read
+// according to Format ("hex" or "text").
+//
+// Built-in placeholders:
+//
+//	{{seq}}        sequence counter (text: decimal, hex: uint32 big endian)
+//	{{clientId}}   ID of the sending client (text: decimal, hex: uint32 big endian)
+//	{{clientName}} name of the sending client (UTF-8)
+//	{{timestamp}}  unix milliseconds (text: decimal, hex: uint64 big endian)
+//	{{random:N}}   N random bytes, at most MaxRandomBytes (text: 2N hex characters)
+//	{{crc32}}      CRC32 (IEEE) of all preceding bytes (text: 8 hex characters, hex: 4 bytes)
+//
+// Overrides of seq, clientId, timestamp and crc32 are decimal numbers,
+// overrides of clientName, random and custom variables are the value itself
+// (hex: hex bytes). Any other placeholder is a custom variable that has to
+// be passed as override.
+type Template struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Format      string `json:"format"` // "hex" or "text"
+	Body        string `json:"body"`
+}
+
+// Vars are the values placeholders are filled with. Overrides replace built-in
+// variables by name and provide custom variables.
+type Vars struct {
+	Seq        uint64
+	ClientID   int
+	ClientName string
+	Time       time.Time
+	Overrides  map[string]string
+	// Rand is the source for {{random:N}}, crypto/rand if nil
+	Rand io.Reader
+}
+
+type part struct {
+	literal     []byte
+	placeholder string
+	arg         string
+}
+
+// Validate checks the format and that the body can be parsed
+func (t *Template) Validate() error {
+	if !validName(t.Name) {
+		return fmt.Errorf("invalid template name %q", t.Name)
+	}
+	_, err := t.parse()
+	return err
+}
+
+// Render fills the placeholders and returns the payload bytes
+func (t *Template) Render(v Vars) ([]byte, error) {
+	parts, err := t.parse()
+	if err != nil {
+		return nil, err
+	}
+	if v.Rand == nil {
+		v.Rand = rand.Reader
+	}
+	out := []byte{}
+	for _, p := range parts {
+		if p.placeholder == "" {
+			out = append(out, p.literal...)
+			continue
+		}
+		b, err := t.renderPlaceholder(p, v, out)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+	}
+	return out, nil
+}
+
+func (t *Template) renderPlaceholder(p part, v Vars, preceding []byte) ([]byte, error) {
+	override, hasOverride := v.Overrides[p.placeholder]
+	text := t.Format == "text"
+
+	switch p.placeholder {
+	case "seq", "clientId", "timestamp":
+		var n uint64
+		switch p.placeholder {
+		case "seq":
+			n = v.Seq
+		case "clientId":
+			n = uint64(v.ClientID)
+		case "timestamp":
+			n = uint64(v.Time.UnixMilli())
+		}
+		if hasOverride {
+			parsed, err := strconv.ParseUint(override, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: %w", p.placeholder, err)
+			}
+			n = parsed
+		}
+		if text {
+			return []byte(strconv.FormatUint(n, 10)), nil
+		}
+		if p.placeholder == "timestamp" {
+			return binary.BigEndian.AppendUint64(nil, n), nil
+		}
+		if n > math.MaxUint32 {
+			return nil, fmt.Errorf("variable %s: %d does not fit in 4 bytes", p.placeholder, n)
+		}
+		return binary.BigEndian.AppendUint32(nil, uint32(n)), nil
+	case "clientName":
+		if hasOverride {
+			return []byte(override), nil
+		}
+		return []byte(v.ClientName), nil
+	case "random":
+		if hasOverride {
+			return overrideBytes(override, text)
+		}
+		n, err := randomCount(p.arg)
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(v.Rand, b); err != nil {
+			return nil, err
+		}
+		if text {
+			return []byte(hex.EncodeToString(b)), nil
+		}
+		return b, nil
+	case "crc32":
+		sum := crc32.ChecksumIEEE(preceding)
+		if hasOverride {
+			parsed, err := strconv.ParseUint(override, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: %w", p.placeholder, err)
+			}
+			sum = uint32(parsed)
+		}
+		if text {
+			return fmt.Appendf(nil, "%08x", sum), nil
+		}
+		return binary.BigEndian.AppendUint32(nil, sum), nil
+	}
+
+	// Custom variable
+	if !hasOverride {
+		return nil, fmt.Errorf("variable %s is not set", p.placeholder)
+	}
+	return overrideBytes(override, text)
+}
+
+// overrideBytes returns the bytes of an override given as value, which is
+// hex in hex templates
+func overrideBytes(override string, text bool) ([]byte, error) {
+	if text {
+		return []byte(override), nil
+	}
+	return decodeHex(override)
+}
+
+func (t *Template) parse() ([]part, error) {
+	if t.Format != "hex" && t.Format != "text" {
+		return nil, errors.New("format must be 'hex' or 'text'")
+	}
+	parts := []part{}
+	rest := t.Body
+	for rest != "" {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			start = len(rest)
+		}
+		if start > 0 {
+			literal, err := t.literal(rest[:start])
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part{literal: literal})
+		}
+		if start == len(rest) {
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, errors.New("unclosed placeholder")
+		}
+		name, arg, _ := strings.Cut(strings.TrimSpace(rest[start+2:start+end]), ":")
+		if name == "" {
+			return nil, errors.New("empty placeholder")
+		}
+		if name == "random" {
+			if _, err := randomCount(arg); err != nil {
+				return nil, err
+			}
+		}
+		parts = append(parts, part{placeholder: name, arg: arg})
+		rest = rest[start+end+2:]
+	}
+	return parts, nil
+}
+
+// randomCount parses the byte count of {{random:N}}
+func randomCount(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("random needs a byte count, got %q", arg)
+	}
+	if n > MaxRandomBytes {
+		return 0, fmt.Errorf("random byte count %d exceeds %d", n, MaxRandomBytes)
+	}
+	return n, nil
+}
+
+func (t *Template) literal(s string) ([]byte, error) {
+	if t.Format == "text" {
+		return []byte(s), nil
+	}
+	return decodeHex(s)
+}
+
+// decodeHex decodes hex ignoring whitespace, like the send form does
+func decodeHex(s string) ([]byte, error) {
+	s = strings.Join(strings.Fields(s), "")
+	return hex.DecodeString(s)
+}
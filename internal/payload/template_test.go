@@ -0,0 +1,137 @@
+package payload
+
+import (
+	"bytes"
+	"hash/crc32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate_Render_Text(t *testing.T) {
+	tmpl := Template{
+		Name:   "hello",
+		Format: "text",
+		Body:   "hi {{clientName}} #{{seq}} id={{clientId}} t={{timestamp}}",
+	}
+
+	b, err := tmpl.Render(Vars{
+		Seq:        3,
+		ClientID:   7,
+		ClientName: "Bobo",
+		Time:       time.UnixMilli(1234),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "hi Bobo #3 id=7 t=1234", string(b))
+}
+
+func TestTemplate_Render_Hex(t *testing.T) {
+	tmpl := Template{
+		Name:   "bin",
+		Format: "hex",
+		Body:   "ca fe {{seq}} {{clientId}}",
+	}
+
+	b, err := tmpl.Render(Vars{Seq: 1, ClientID: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xca, 0xfe, 0, 0, 0, 1, 0, 0, 0, 2}, b)
+}
+
+func TestTemplate_Render_RandomAndCRC(t *testing.T) {
+	tmpl := Template{
+		Name:   "crc",
+		Format: "hex",
+		Body:   "01 {{random:3}}{{crc32}}",
+	}
+
+	b, err := tmpl.Render(Vars{Rand: bytes.NewReader([]byte{0xaa, 0xbb, 0xcc})})
+
+	require.NoError(t, err)
+	require.Len(t, b, 8)
+	assert.Equal(t, []byte{0x01, 0xaa, 0xbb, 0xcc}, b[:4])
+	sum := crc32.ChecksumIEEE(b[:4])
+	assert.Equal(t, []byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)}, b[4:])
+}
+
+func TestTemplate_Render_Overrides(t *testing.T) {
+	tmpl := Template{
+		Name:   "vars",
+		Format: "text",
+		Body:   "{{seq}}-{{clientName}}-{{room}}",
+	}
+
+	b, err := tmpl.Render(Vars{
+		Seq:        1,
+		ClientName: "Bobo",
+		Overrides:  map[string]string{"seq": "42", "clientName": "Alice", "room": "lobby"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "42-Alice-lobby", string(b))
+}
+
+func TestTemplate_Render_RandomAndCRCOverrides(t *testing.T) {
+	tmpl := Template{Name: "crc", Format: "hex", Body: "01 {{random:3}}{{crc32}}"}
+
+	b, err := tmpl.Render(Vars{Overrides: map[string]string{"random": "aa bb", "crc32": "258"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0xaa, 0xbb, 0, 0, 0x01, 0x02}, b)
+
+	_, err = tmpl.Render(Vars{Overrides: map[string]string{"crc32": "4294967296"}})
+	assert.Error(t, err)
+}
+
+func TestTemplate_Render_MissingVariable(t *testing.T) {
+	tmpl := Template{Name: "vars", Format: "text", Body: "{{room}}"}
+
+	_, err := tmpl.Render(Vars{})
+
+	assert.Error(t, err)
+}
+
+func TestTemplate_Render_HexOverflow(t *testing.T) {
+	tmpl := Template{Name: "seq", Format: "hex", Body: "{{seq}}"}
+
+	_, err := tmpl.Render(Vars{Overrides: map[string]string{"seq": "4294967296"}})
+	assert.Error(t, err)
+
+	_, err = tmpl.Render(Vars{Seq: 1 << 32})
+	assert.Error(t, err)
+
+	b, err := tmpl.Render(Vars{Seq: 1<<32 - 1})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0xff, 0xff, 0xff}, b)
+}
+
+func TestTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		tmpl  Template
+		valid bool
+	}{
+		{"valid", Template{Name: "ok", Format: "text", Body: "{{seq}}"}, true},
+		{"bad format", Template{Name: "ok", Format: "json", Body: ""}, false},
+		{"bad name", Template{Name: "../etc", Format: "text", Body: ""}, false},
+		{"unclosed", Template{Name: "ok", Format: "text", Body: "{{seq"}, false},
+		{"bad hex", Template{Name: "ok", Format: "hex", Body: "zz"}, false},
+		{"random at limit", Template{Name: "ok", Format: "hex", Body: "{{random:65507}}"}, true},
+		{"random too large", Template{Name: "ok", Format: "hex", Body: "{{random:99999999999}}"}, false},
+		{"random without count", Template{Name: "ok", Format: "hex", Body: "{{random}}"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tmpl.Validate()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+)
+
+var (
+	ErrClientNameTaken = errors.New("client name is already taken")
+	ErrClientIDTaken   = errors.New("client ID is already taken")
+)
+
+// clientEntry is a registered client. Its mu guards client, so updates of
+// one client don't wait for the others.
+type clientEntry struct {
+	// id and name are indexed and never change
+	id   int
+	name string
+
+	mu     sync.Mutex
+	client api.UDPClient
+	// scope owns the goroutines of the client, nil for clients without any
+	scope *Scope
+	// removed is set once the entry left the indexes
+	removed bool
+}
+
+// ClientRegistry holds the debug clients, indexed by ID and by name. The
+// indexes have their own lock, which is only held to find entries; every
+// client is locked on its own while it is read or updated. Readers get
+// copies, so nothing is locked while they use them.
+//
+// The copies share Datagrams and Tags with the registry. Both are only
+// appended to or replaced by Update, never changed in place.
+type ClientRegistry struct {
+	mu     sync.RWMutex
+	byID   map[int]*clientEntry
+	byName map[string]*clientEntry
+}
+
+func NewClientRegistry() *ClientRegistry {
+	return &ClientRegistry{
+		byID:   make(map[int]*clientEntry),
+		byName: make(map[string]*clientEntry),
+	}
+}
+
+// Add registers uc with its scope, which may be nil. Name and ID must not be in use.
+func (r *ClientRegistry) Add(uc api.UDPClient, scope *Scope) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byName[uc.Name]; ok {
+		return ErrClientNameTaken
+	}
+	if _, ok := r.byID[uc.ID]; ok {
+		return ErrClientIDTaken
+	}
+	entry := &clientEntry{id: uc.ID, name: uc.Name, client: uc, scope: scope}
+	r.byID[uc.ID] = entry
+	r.byName[uc.Name] = entry
+	return nil
+}
+
+// HasName reports whether a client with the name is registered
+func (r *ClientRegistry) HasName(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.byName[name]
+	return ok
+}
+
+// Len returns the number of registered clients
+func (r *ClientRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.byID)
+}
+
+func (r *ClientRegistry) entry(id int) *clientEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.byID[id]
+}
+
+func (r *ClientRegistry) entryByName(name string) *clientEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.byName[name]
+}
+
+// read returns a copy of the client, false if it was removed meanwhile
+func (e *clientEntry) read() (api.UDPClient, bool) {
+	if e == nil {
+		return api.UDPClient{}, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.client, !e.removed
+}
+
+// update applies f and returns a copy of the result. ID and name are
+// indexed, f can't change them.
+func (e *clientEntry) update(f func(uc *api.UDPClient)) (api.UDPClient, bool) {
+	if e == nil {
+		return api.UDPClient{}, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.removed {
+		return api.UDPClient{}, false
+	}
+	f(&e.client)
+	e.client.ID, e.client.Name = e.id, e.name
+	return e.client, true
+}
+
+// Get returns a copy of the client with the ID
+func (r *ClientRegistry) Get(id int) (api.UDPClient, bool) {
+	return r.entry(id).read()
+}
+
+// GetByName returns a copy of the client with the name
+func (r *ClientRegistry) GetByName(name string) (api.UDPClient, bool) {
+	return r.entryByName(name).read()
+}
+
+// Scope returns the scope of the client with the ID, nil if it has none
+func (r *ClientRegistry) Scope(id int) *Scope {
+	entry := r.entry(id)
+	if entry == nil {
+		return nil
+	}
+	return entry.scope
+}
+
+// Update changes the client with the ID under its lock and returns a copy
+// of the result. f must not block, other updates of the client wait for it.
+func (r *ClientRegistry) Update(id int, f func(uc *api.UDPClient)) (api.UDPClient, bool) {
+	return r.entry(id).update(f)
+}
+
+// UpdateByName is Update for the client with the name
+func (r *ClientRegistry) UpdateByName(name string, f func(uc *api.UDPClient)) (api.UDPClient, bool) {
+	return r.entryByName(name).update(f)
+}
+
+// Remove unregisters the client with the ID and returns its last state and scope
+func (r *ClientRegistry) Remove(id int) (api.UDPClient, *Scope, bool) {
+	r.mu.Lock()
+	entry, ok := r.byID[id]
+	if ok {
+		delete(r.byID, id)
+		delete(r.byName, entry.name)
+	}
+	r.mu.Unlock()
+	if !ok {
+		return api.UDPClient{}, nil, false
+	}
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	entry.removed = true
+	return entry.client, entry.scope, true
+}
+
+// Snapshot returns copies of all clients ordered by ID. Each copy is
+// consistent on its own; clients updated while the snapshot is taken may
+// show the state before or after the update.
+func (r *ClientRegistry) Snapshot() []api.UDPClient {
+	return r.Filter(nil)
+}
+
+// Filter is Snapshot limited to the clients match accepts, all if match
+// is nil. match runs on the copies without any lock held.
+func (r *ClientRegistry) Filter(match func(uc api.UDPClient) bool) []api.UDPClient {
+	r.mu.RLock()
+	entries := make([]*clientEntry, 0, len(r.byID))
+	for _, entry := range r.byID {
+		entries = append(entries, entry)
+	}
+	r.mu.RUnlock()
+
+	clients := make([]api.UDPClient, 0, len(entries))
+	for _, entry := range entries {
+		uc, ok := entry.read()
+		if ok && (match == nil || match(uc)) {
+			clients = append(clients, uc)
+		}
+	}
+	slices.SortFunc(clients, func(a, b api.UDPClient) int { return a.ID - b.ID })
+	return clients
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// benchmarkClients is the number of clients the benchmarks run with
+const benchmarkClients = 1000
+
+func newTestRegistry(t testing.TB, n int) *ClientRegistry {
+	t.Helper()
+	registry := NewClientRegistry()
+	for id := range n {
+		require.NoError(t, registry.Add(api.UDPClient{ID: id, Name: fmt.Sprintf("client-%d", id)}, nil))
+	}
+	return registry
+}
+
+func TestClientRegistry_Indexes(t *testing.T) {
+	registry := NewClientRegistry()
+	scope := NewScope(context.Background())
+	require.NoError(t, registry.Add(api.UDPClient{ID: 1, Name: "alice"}, scope))
+	require.NoError(t, registry.Add(api.UDPClient{ID: 2, Name: "bob"}, nil))
+
+	assert.ErrorIs(t, registry.Add(api.UDPClient{ID: 3, Name: "alice"}, nil), ErrClientNameTaken)
+	assert.ErrorIs(t, registry.Add(api.UDPClient{ID: 1, Name: "carol"}, nil), ErrClientIDTaken)
+	assert.Equal(t, 2, registry.Len())
+	assert.True(t, registry.HasName("alice"))
+	assert.False(t, registry.HasName("carol"))
+
+	uc, ok := registry.Get(1)
+	require.True(t, ok)
+	assert.Equal(t, "alice", uc.Name)
+	uc, ok = registry.GetByName("bob")
+	require.True(t, ok)
+	assert.Equal(t, 2, uc.ID)
+	assert.Same(t, scope, registry.Scope(1))
+	assert.Nil(t, registry.Scope(2))
+	_, ok = registry.Get(3)
+	assert.False(t, ok)
+
+	removed, removedScope, ok := registry.Remove(1)
+	require.True(t, ok)
+	assert.Equal(t, "alice", removed.Name)
+	assert.Same(t, scope, removedScope)
+	_, ok = registry.GetByName("alice")
+	assert.False(t, ok)
+	_, _, ok = registry.Remove(1)
+	assert.False(t, ok)
+	// The name is free again
+	assert.NoError(t, registry.Add(api.UDPClient{ID: 3, Name: "alice"}, nil))
+}
+
+func TestClientRegistry_Update(t *testing.T) {
+	registry := newTestRegistry(t, 2)
+
+	uc, ok := registry.Update(1, func(uc *api.UDPClient) {
+		uc.Running = true
+		// Indexed fields stay
+		uc.ID, uc.Name = 7, "renamed"
+	})
+	require.True(t, ok)
+	assert.True(t, uc.Running)
+	assert.Equal(t, 1, uc.ID)
+	assert.Equal(t, "client-1", uc.Name)
+
+	uc, ok = registry.UpdateByName("client-0", func(uc *api.UDPClient) { uc.Group = "red" })
+	require.True(t, ok)
+	assert.Equal(t, "red", uc.Group)
+
+	registry.Remove(1)
+	_, ok = registry.Update(1, func(uc *api.UDPClient) { t.Error("updated a removed client") })
+	assert.False(t, ok)
+}
+
+func TestClientRegistry_SnapshotIsCopy(t *testing.T) {
+	registry := newTestRegistry(t, 3)
+	registry.Update(2, func(uc *api.UDPClient) { uc.Group = "red" })
+
+	snapshot := registry.Snapshot()
+	require.Len(t, snapshot, 3)
+	assert.Equal(t, []int{0, 1, 2}, []int{snapshot[0].ID, snapshot[1].ID, snapshot[2].ID})
+
+	snapshot[0].Running = true
+	uc, _ := registry.Get(0)
+	assert.False(t, uc.Running)
+
+	red := registry.Filter(func(uc api.UDPClient) bool { return uc.Group == "red" })
+	require.Len(t, red, 1)
+	assert.Equal(t, 2, red[0].ID)
+}
+
+// TestClientRegistry_Concurrent updates, lists and removes clients at the
+// same time; run it with -race
+func TestClientRegistry_Concurrent(t *testing.T) {
+	registry := newTestRegistry(t, 100)
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Go(func() {
+			for i := range 200 {
+				registry.Update((worker*200+i)%100, func(uc *api.UDPClient) {
+					uc.LastSeq++
+					uc.Datagrams = append(uc.Datagrams, api.Datagram{Seq: uc.LastSeq})
+				})
+			}
+		})
+	}
+	wg.Go(func() {
+		for range 50 {
+			for _, uc := range registry.Snapshot() {
+				// Copies are consistent on their own
+				assert.Len(t, uc.Datagrams, uc.LastSeq)
+			}
+		}
+	})
+	wg.Go(func() {
+		for id := 90; id < 100; id++ {
+			registry.Remove(id)
+		}
+	})
+	wg.Wait()
+
+	assert.Equal(t, 90, registry.Len())
+	total := 0
+	for _, uc := range registry.Snapshot() {
+		total += uc.LastSeq
+	}
+	// Updates of the removed clients may be lost, none of the others
+	assert.GreaterOrEqual(t, total, 8*200*90/100)
+}
+
+// BenchmarkClientRegistry_Update records datagrams on 1,000 clients from
+// parallel goroutines, like the packet handlers of the clients
+func BenchmarkClientRegistry_Update(b *testing.B) {
+	registry := newTestRegistry(b, benchmarkClients)
+	var next atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			id := int(next.Add(1) % benchmarkClients)
+			registry.Update(id, func(uc *api.UDPClient) {
+				uc.LastSeq++
+			})
+		}
+	})
+}
+
+// BenchmarkClientRegistry_Get looks up 1,000 clients by ID in parallel
+func BenchmarkClientRegistry_Get(b *testing.B) {
+	registry := newTestRegistry(b, benchmarkClients)
+	var next atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			registry.Get(int(next.Add(1) % benchmarkClients))
+		}
+	})
+}
+
+// BenchmarkClientRegistry_Snapshot lists 1,000 clients
+func BenchmarkClientRegistry_Snapshot(b *testing.B) {
+	registry := newTestRegistry(b, benchmarkClients)
+	for b.Loop() {
+		registry.Snapshot()
+	}
+}
+
+// BenchmarkClientRegistry_UpdateWhileListing updates 1,000 clients in
+// parallel while another goroutine keeps listing them
+func BenchmarkClientRegistry_UpdateWhileListing(b *testing.B) {
+	registry := newTestRegistry(b, benchmarkClients)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for ctx.Err() == nil {
+			registry.Snapshot()
+		}
+	})
+	var next atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			registry.Update(int(next.Add(1)%benchmarkClients), func(uc *api.UDPClient) {
+				uc.LastSeq++
+			})
+		}
+	})
+	b.StopTimer()
+	cancel()
+	wg.Wait()
+}
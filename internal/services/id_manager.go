@@ -28,3 +28,10 @@ func (i *ids) getNextID() int {
 func GetNextID() int {
 	return idCounter.getNextID()
 }
+
+// ResetIDs makes GetNextID continue at next
+func ResetIDs(next int) {
+	idCounter.mu.Lock()
+	idCounter.nextID = next
+	idCounter.mu.Unlock()
+}
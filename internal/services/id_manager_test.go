@@ -65,3 +65,12 @@ func TestGetNextID_Concurrent(t *testing.T) {
 		assert.True(t, ids[i], "ID %d should exist", i)
 	}
 }
+
+func TestResetIDs(t *testing.T) {
+	ResetIDs(7)
+	assert.Equal(t, 7, GetNextID())
+	assert.Equal(t, 8, GetNextID())
+
+	ResetIDs(0)
+	assert.Equal(t, 0, GetNextID())
+}
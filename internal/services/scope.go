@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// Scope owns a group of goroutines, like those of a debug client. They
+// share its context; Cancel ends it and Wait waits for them to return.
+type Scope struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// mu orders Go and Do against Cancel, so no goroutine is added while
+	// Wait waits
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewScope returns a scope whose context ends with parent
+func NewScope(parent context.Context) *Scope {
+	ctx, cancel := context.WithCancel(parent)
+	return &Scope{ctx: ctx, cancel: cancel}
+}
+
+// Context ends when the scope is cancelled
+func (s *Scope) Context() context.Context {
+	return s.ctx
+}
+
+// Go runs f in a goroutine of the scope. It returns false without running
+// f if the scope is cancelled.
+func (s *Scope) Go(f func()) bool {
+	if !s.add() {
+		return false
+	}
+	go func() {
+		defer s.wg.Done()
+		f()
+	}()
+	return true
+}
+
+// Do runs f in the calling goroutine as part of the scope, e.g. a callback
+// of the client. It returns false without running f if the scope is
+// cancelled.
+func (s *Scope) Do(f func()) bool {
+	if !s.add() {
+		return false
+	}
+	defer s.wg.Done()
+	f()
+	return true
+}
+
+func (s *Scope) add() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return false
+	}
+	s.wg.Add(1)
+	return true
+}
+
+// Cancel ends the context and refuses new goroutines
+func (s *Scope) Cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	s.cancel()
+}
+
+// Wait waits until the goroutines of the scope have returned. Call it after
+// Cancel. It returns ctx.Err() if ctx ends first.
+func (s *Scope) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop cancels the scope and waits for its goroutines
+func (s *Scope) Stop(ctx context.Context) error {
+	s.Cancel()
+	return s.Wait(ctx)
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScope_StopWaitsForGoroutines(t *testing.T) {
+	scope := NewScope(context.Background())
+	returned := make(chan struct{})
+	require.True(t, scope.Go(func() {
+		<-scope.Context().Done()
+		close(returned)
+	}))
+
+	require.NoError(t, scope.Stop(context.Background()))
+
+	select {
+	case <-returned:
+	default:
+		t.Fatal("Stop returned before the goroutine")
+	}
+	assert.False(t, scope.Go(func() { t.Error("ran in a stopped scope") }))
+	assert.False(t, scope.Do(func() { t.Error("ran in a stopped scope") }))
+}
+
+func TestScope_WaitTimeout(t *testing.T) {
+	scope := NewScope(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	scope.Go(func() { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, scope.Stop(ctx), context.DeadlineExceeded)
+}
+
+func TestScope_ParentCancels(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	scope := NewScope(parent)
+
+	cancel()
+
+	assert.Error(t, scope.Context().Err())
+}
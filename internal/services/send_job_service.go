@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+)
+
+var (
+	ErrSendJobNotFound = errors.New("send job not found")
+	ErrSendJobFinished = errors.New("send job already finished")
+	// ErrScopeCancelled: the scope of StartIn, e.g. the client, is stopped
+	ErrScopeCancelled = errors.New("the client of the send job is stopped")
+)
+
+// progressInterval limits how often progress of a running job is reported,
+// state changes are always reported
+const progressInterval = 250 * time.Millisecond
+
+type sendJob struct {
+	api.SendJob
+	cancel   context.CancelFunc
+	resumeCh chan struct{}
+}
+
+// SendJobService runs periodic send jobs. The actual sending is done by the
+// send function, so jobs go through the same path as the send endpoint.
+type SendJobService struct {
+	mu       sync.Mutex
+	ctx      context.Context
+	jobs     map[int]*sendJob
+	nextID   int
+	send     func(req api.SendDatagramRequest) error
+	onUpdate func(job api.SendJob)
+	wg       sync.WaitGroup
+}
+
+func NewSendJobService(ctx context.Context, send func(req api.SendDatagramRequest) error, onUpdate func(job api.SendJob)) *SendJobService {
+	return &SendJobService{
+		mu:       sync.Mutex{},
+		ctx:      ctx,
+		jobs:     make(map[int]*sendJob),
+		nextID:   1,
+		send:     send,
+		onUpdate: onUpdate,
+	}
+}
+
+// Start validates req and starts a new job sending req.Request every
+// req.IntervalMs. Without Count and DurationMs the job runs until cancelled.
+func (s *SendJobService) Start(req api.StartSendJobRequest) (api.SendJob, error) {
+	return s.StartIn(nil, req)
+}
+
+// StartIn starts a job like Start, running it in scope, e.g. the scope of
+// its client. The job ends when the scope is cancelled.
+func (s *SendJobService) StartIn(scope *Scope, req api.StartSendJobRequest) (api.SendJob, error) {
+	if req.IntervalMs < 1 {
+		return api.SendJob{}, errors.New("intervalMs must be at least 1")
+	}
+	if req.JitterMs < 0 || req.Count < 0 || req.DurationMs < 0 {
+		return api.SendJob{}, errors.New("jitterMs, count and durationMs must not be negative")
+	}
+	req.Request.Id = req.ClientID
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.mu.Lock()
+	job := &sendJob{
+		SendJob: api.SendJob{
+			ID:         s.nextID,
+			ClientID:   req.ClientID,
+			IntervalMs: req.IntervalMs,
+			JitterMs:   req.JitterMs,
+			Count:      req.Count,
+			DurationMs: req.DurationMs,
+			Request:    req.Request,
+			State:      api.SendJobRunning,
+			StartedAt:  time.Now(),
+		},
+		cancel:   cancel,
+		resumeCh: make(chan struct{}, 1),
+	}
+	s.nextID++
+	s.jobs[job.ID] = job
+	snapshot := job.SendJob
+	s.mu.Unlock()
+
+	run := func() {
+		s.run(ctx, job)
+	}
+	if scope == nil {
+		s.wg.Go(run)
+	} else {
+		stop := context.AfterFunc(scope.Context(), cancel)
+		s.wg.Add(1)
+		started := scope.Go(func() {
+			defer s.wg.Done()
+			defer stop()
+			run()
+		})
+		if !started {
+			s.wg.Done()
+			stop()
+			cancel()
+			s.mu.Lock()
+			delete(s.jobs, job.ID)
+			s.mu.Unlock()
+			return api.SendJob{}, ErrScopeCancelled
+		}
+	}
+	s.notify(snapshot)
+	return snapshot, nil
+}
+
+// List returns all jobs, or only those of one client if clientID is not nil
+func (s *SendJobService) List(clientID *int) []api.SendJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := []api.SendJob{}
+	for id := 1; id < s.nextID; id++ {
+		job, ok := s.jobs[id]
+		if !ok || (clientID != nil && job.ClientID != *clientID) {
+			continue
+		}
+		jobs = append(jobs, job.SendJob)
+	}
+	return jobs
+}
+
+// Pause stops sending until the job is resumed
+func (s *SendJobService) Pause(id int) (api.SendJob, error) {
+	return s.transition(id, api.SendJobRunning, api.SendJobPaused)
+}
+
+// Resume continues a paused job
+func (s *SendJobService) Resume(id int) (api.SendJob, error) {
+	job, err := s.transition(id, api.SendJobPaused, api.SendJobRunning)
+	if err != nil {
+		return job, err
+	}
+	s.mu.Lock()
+	resumeCh := s.jobs[id].resumeCh
+	s.mu.Unlock()
+	select {
+	case resumeCh <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Cancel stops a job for good
+func (s *SendJobService) Cancel(id int) (api.SendJob, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return api.SendJob{}, ErrSendJobNotFound
+	}
+	if job.finished() {
+		snapshot := job.SendJob
+		s.mu.Unlock()
+		return snapshot, ErrSendJobFinished
+	}
+	job.finish(api.SendJobCancelled, "")
+	snapshot := job.SendJob
+	s.mu.Unlock()
+
+	job.cancel()
+	s.notify(snapshot)
+	return snapshot, nil
+}
+
+// CancelClient cancels all unfinished jobs of a client and returns how many
+// were cancelled
+func (s *SendJobService) CancelClient(clientID int) int {
+	cancelled := 0
+	for _, job := range s.List(&clientID) {
+		if _, err := s.Cancel(job.ID); err == nil {
+			cancelled++
+		}
+	}
+	return cancelled
+}
+
+// RemoveFinished drops finished jobs from the list and returns how many were removed
+func (s *SendJobService) RemoveFinished() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for id, job := range s.jobs {
+		if job.finished() {
+			delete(s.jobs, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Wait blocks until all job goroutines have returned
+func (s *SendJobService) Wait() {
+	s.wg.Wait()
+}
+
+func (s *SendJobService) transition(id int, from api.SendJobState, to api.SendJobState) (api.SendJob, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return api.SendJob{}, ErrSendJobNotFound
+	}
+	if job.finished() {
+		snapshot := job.SendJob
+		s.mu.Unlock()
+		return snapshot, ErrSendJobFinished
+	}
+	if job.State == from {
+		job.State = to
+	}
+	snapshot := job.SendJob
+	s.mu.Unlock()
+	s.notify(snapshot)
+	return snapshot, nil
+}
+
+func (s *SendJobService) run(ctx context.Context, job *sendJob) {
+	interval := time.Duration(job.IntervalMs) * time.Millisecond
+	jitter := time.Duration(job.JitterMs) * time.Millisecond
+	var deadline time.Time
+	if job.DurationMs > 0 {
+		deadline = job.StartedAt.Add(time.Duration(job.DurationMs) * time.Millisecond)
+	}
+	lastProgress := time.Time{}
+	wait := time.Duration(0)
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.end(job, api.SendJobCancelled, "")
+			return
+		case <-time.After(wait):
+		}
+		wait = interval
+		if jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(2*jitter)+1)) - jitter
+		}
+
+		s.mu.Lock()
+		paused := job.State == api.SendJobPaused
+		resumeCh := job.resumeCh
+		s.mu.Unlock()
+		if paused {
+			select {
+			case <-ctx.Done():
+				s.end(job, api.SendJobCancelled, "")
+				return
+			case <-resumeCh:
+				wait = 0
+				continue
+			}
+		}
+
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			s.end(job, api.SendJobDone, "")
+			return
+		}
+
+		if err := s.send(job.Request); err != nil {
+			s.end(job, api.SendJobFailed, err.Error())
+			return
+		}
+
+		s.mu.Lock()
+		job.Sent++
+		done := job.Count > 0 && job.Sent >= job.Count
+		snapshot := job.SendJob
+		s.mu.Unlock()
+
+		if done {
+			s.end(job, api.SendJobDone, "")
+			return
+		}
+		if time.Since(lastProgress) >= progressInterval {
+			lastProgress = time.Now()
+			s.notify(snapshot)
+		}
+	}
+}
+
+// end finishes the job unless it was finished already, e.g. by Cancel
+func (s *SendJobService) end(job *sendJob, state api.SendJobState, lastError string) {
+	s.mu.Lock()
+	if job.finished() {
+		s.mu.Unlock()
+		return
+	}
+	job.finish(state, lastError)
+	snapshot := job.SendJob
+	s.mu.Unlock()
+	job.cancel()
+	s.notify(snapshot)
+}
+
+func (s *SendJobService) notify(job api.SendJob) {
+	if s.onUpdate != nil {
+		s.onUpdate(job)
+	}
+}
+
+func (j *sendJob) finished() bool {
+	return j.State == api.SendJobDone || j.State == api.SendJobCancelled || j.State == api.SendJobFailed
+}
+
+func (j *sendJob) finish(state api.SendJobState, lastError string) {
+	j.State = state
+	j.LastError = lastError
+	j.FinishedAt = time.Now()
+}
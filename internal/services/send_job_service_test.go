@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitForState(t *testing.T, service *SendJobService, id int, state api.SendJobState) api.SendJob {
+	t.Helper()
+	var job api.SendJob
+	require.Eventually(t, func() bool {
+		for _, j := range service.List(nil) {
+			if j.ID == id {
+				job = j
+				return j.State == state
+			}
+		}
+		return false
+	}, 2*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestSendJobService_Count(t *testing.T) {
+	var sent atomic.Int32
+	service := NewSendJobService(context.Background(), func(req api.SendDatagramRequest) error {
+		assert.Equal(t, 3, req.Id)
+		sent.Add(1)
+		return nil
+	}, nil)
+
+	job, err := service.Start(api.StartSendJobRequest{ClientID: 3, IntervalMs: 1, Count: 5})
+	require.NoError(t, err)
+
+	job = waitForState(t, service, job.ID, api.SendJobDone)
+	assert.Equal(t, 5, job.Sent)
+	assert.Equal(t, int32(5), sent.Load())
+	service.Wait()
+}
+
+func TestSendJobService_Duration(t *testing.T) {
+	service := NewSendJobService(context.Background(), func(req api.SendDatagramRequest) error { return nil }, nil)
+
+	job, err := service.Start(api.StartSendJobRequest{ClientID: 1, IntervalMs: 5, JitterMs: 2, DurationMs: 50})
+	require.NoError(t, err)
+
+	job = waitForState(t, service, job.ID, api.SendJobDone)
+	assert.Greater(t, job.Sent, 0)
+}
+
+func TestSendJobService_SendErrorFailsJob(t *testing.T) {
+	service := NewSendJobService(context.Background(), func(req api.SendDatagramRequest) error {
+		return errors.New("client is not running")
+	}, nil)
+
+	job, err := service.Start(api.StartSendJobRequest{ClientID: 1, IntervalMs: 1})
+	require.NoError(t, err)
+
+	job = waitForState(t, service, job.ID, api.SendJobFailed)
+	assert.Equal(t, "client is not running", job.LastError)
+}
+
+func TestSendJobService_PauseResumeCancel(t *testing.T) {
+	var sent atomic.Int32
+	service := NewSendJobService(context.Background(), func(req api.SendDatagramRequest) error {
+		sent.Add(1)
+		return nil
+	}, nil)
+
+	job, err := service.Start(api.StartSendJobRequest{ClientID: 1, IntervalMs: 1})
+	require.NoError(t, err)
+
+	_, err = service.Pause(job.ID)
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	paused := sent.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.LessOrEqual(t, sent.Load(), paused+1, "paused job should not keep sending")
+
+	_, err = service.Resume(job.ID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return sent.Load() > paused+1 }, time.Second, 5*time.Millisecond)
+
+	job, err = service.Cancel(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, api.SendJobCancelled, job.State)
+	service.Wait()
+
+	_, err = service.Cancel(job.ID)
+	assert.ErrorIs(t, err, ErrSendJobFinished)
+}
+
+func TestSendJobService_CancelClient(t *testing.T) {
+	service := NewSendJobService(context.Background(), func(req api.SendDatagramRequest) error { return nil }, nil)
+
+	_, err := service.Start(api.StartSendJobRequest{ClientID: 1, IntervalMs: 10})
+	require.NoError(t, err)
+	_, err = service.Start(api.StartSendJobRequest{ClientID: 1, IntervalMs: 10})
+	require.NoError(t, err)
+	other, err := service.Start(api.StartSendJobRequest{ClientID: 2, IntervalMs: 10})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, service.CancelClient(1))
+
+	clientID := 2
+	jobs := service.List(&clientID)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, other.ID, jobs[0].ID)
+	assert.Equal(t, api.SendJobRunning, jobs[0].State)
+	service.CancelClient(2)
+	service.Wait()
+}
+
+func TestSendJobService_StartIn(t *testing.T) {
+	service := NewSendJobService(context.Background(), func(req api.SendDatagramRequest) error { return nil }, nil)
+	scope := NewScope(context.Background())
+
+	job, err := service.StartIn(scope, api.StartSendJobRequest{ClientID: 1, IntervalMs: 10})
+	require.NoError(t, err)
+
+	// The job ends with its scope
+	require.NoError(t, scope.Stop(context.Background()))
+	assert.Equal(t, api.SendJobCancelled, waitForState(t, service, job.ID, api.SendJobCancelled).State)
+
+	_, err = service.StartIn(scope, api.StartSendJobRequest{ClientID: 1, IntervalMs: 10})
+	assert.ErrorIs(t, err, ErrScopeCancelled)
+	assert.Len(t, service.List(nil), 1)
+	service.Wait()
+}
+
+func TestSendJobService_RemoveFinished(t *testing.T) {
+	service := NewSendJobService(context.Background(), func(req api.SendDatagramRequest) error { return nil }, nil)
+
+	done, err := service.Start(api.StartSendJobRequest{ClientID: 1, IntervalMs: 1, Count: 1})
+	require.NoError(t, err)
+	running, err := service.Start(api.StartSendJobRequest{ClientID: 1, IntervalMs: 10})
+	require.NoError(t, err)
+	waitForState(t, service, done.ID, api.SendJobDone)
+
+	assert.Equal(t, 1, service.RemoveFinished())
+
+	jobs := service.List(nil)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, running.ID, jobs[0].ID)
+	service.CancelClient(1)
+	service.Wait()
+}
+
+func TestSendJobService_ContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	updates := []api.SendJobState{}
+	service := NewSendJobService(ctx, func(req api.SendDatagramRequest) error { return nil }, func(job api.SendJob) {
+		mu.Lock()
+		updates = append(updates, job.State)
+		mu.Unlock()
+	})
+
+	job, err := service.Start(api.StartSendJobRequest{ClientID: 1, IntervalMs: 10})
+	require.NoError(t, err)
+	cancel()
+
+	waitForState(t, service, job.ID, api.SendJobCancelled)
+	service.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, api.SendJobRunning, updates[0])
+	assert.Equal(t, api.SendJobCancelled, updates[len(updates)-1])
+}
+
+func TestSendJobService_Validation(t *testing.T) {
+	service := NewSendJobService(context.Background(), func(req api.SendDatagramRequest) error { return nil }, nil)
+
+	_, err := service.Start(api.StartSendJobRequest{ClientID: 1, IntervalMs: 0})
+	assert.Error(t, err)
+	_, err = service.Start(api.StartSendJobRequest{ClientID: 1, IntervalMs: 1, Count: -1})
+	assert.Error(t, err)
+	_, err = service.Pause(42)
+	assert.ErrorIs(t, err, ErrSendJobNotFound)
+}
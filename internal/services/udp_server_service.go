@@ -5,7 +5,7 @@ import (
 	"errors"
 	"sync"
 
-	"github.com/auraspeak/debug-ui/internal/ws"
+	"github.com/auraspeak/debug-ui/internal/communication"
 	"github.com/auraspeak/protocol"
 	"github.com/auraspeak/server"
 	"github.com/auraspeak/server/pkg/debugui"
@@ -17,16 +17,18 @@ type UDPServerService struct {
 	mu     sync.Mutex
 	ctx    context.Context
 	cfg    *debugui.Config
-	wsHub  *ws.WebSocketHub
+	bus    *communication.Bus
+	logger *log.Logger
 }
 
-func NewUDPServerService(ctx context.Context, cfg *debugui.Config, wsHub *ws.WebSocketHub) *UDPServerService {
+func NewUDPServerService(ctx context.Context, cfg *debugui.Config, bus *communication.Bus, logger *log.Logger) *UDPServerService {
 	return &UDPServerService{
 		server: nil,
 		mu:     sync.Mutex{},
 		ctx:    ctx,
 		cfg:    cfg,
-		wsHub:  wsHub,
+		bus:    bus,
+		logger: logger,
 	}
 }
 
@@ -44,7 +46,7 @@ func (s *UDPServerService) GetServer() *server.Server {
 
 // handleAll handles all incoming packets from UDP server
 func (s *UDPServerService) HandleAll(clientAddr string, packet []byte) error {
-	log.WithField("caller", "web").Infof("Received packet: %s", string(packet))
+	s.logger.WithField("caller", "web").Infof("Received packet: %s", string(packet))
 	s.mu.Lock()
 	if s.server != nil {
 		s.server.Broadcast(&protocol.Packet{
@@ -58,10 +60,10 @@ func (s *UDPServerService) HandleAll(clientAddr string, packet []byte) error {
 		})
 	}
 	s.mu.Unlock()
-	s.mu.Lock()
-	if s.wsHub != nil {
-		s.wsHub.Broadcast(packet)
+	if s.bus != nil {
+		communication.ServerPackets.Publish(s.bus, communication.InternalMessage{
+			Caller: communication.CallerUDPServer,
+		}, packet)
 	}
-	s.mu.Unlock()
 	return nil
 }
@@ -4,8 +4,9 @@ import (
 	"context"
 	"testing"
 
-	"github.com/auraspeak/debug-ui/internal/ws"
+	"github.com/auraspeak/debug-ui/internal/communication"
 	"github.com/auraspeak/server/pkg/debugui"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -13,23 +14,23 @@ import (
 func TestNewUDPServerService(t *testing.T) {
 	ctx := context.Background()
 	cfg := &debugui.Config{}
-	hub := ws.NewHub(ctx)
+	bus := communication.NewBus()
 
-	service := NewUDPServerService(ctx, cfg, hub)
+	service := NewUDPServerService(ctx, cfg, bus, log.StandardLogger())
 
 	require.NotNil(t, service)
 	assert.Nil(t, service.server, "Server should be nil initially")
 	assert.Equal(t, ctx, service.ctx)
 	assert.Equal(t, cfg, service.cfg)
-	assert.Equal(t, hub, service.wsHub)
+	assert.Equal(t, bus, service.bus)
 }
 
 func TestUDPServerService_Start(t *testing.T) {
 	ctx := context.Background()
 	cfg := &debugui.Config{}
-	hub := ws.NewHub(ctx)
+	bus := communication.NewBus()
 
-	service := NewUDPServerService(ctx, cfg, hub)
+	service := NewUDPServerService(ctx, cfg, bus, log.StandardLogger())
 
 	// Start might fail if DTLS config is not set up
 	// This is expected in test environment without proper certs
@@ -46,9 +47,9 @@ func TestUDPServerService_Start(t *testing.T) {
 func TestUDPServerService_GetServer(t *testing.T) {
 	ctx := context.Background()
 	cfg := &debugui.Config{}
-	hub := ws.NewHub(ctx)
+	bus := communication.NewBus()
 
-	service := NewUDPServerService(ctx, cfg, hub)
+	service := NewUDPServerService(ctx, cfg, bus, log.StandardLogger())
 
 	// Initially server should be nil
 	assert.Nil(t, service.GetServer())
@@ -69,9 +70,9 @@ func TestUDPServerService_GetServer(t *testing.T) {
 func TestUDPServerService_HandleAll(t *testing.T) {
 	ctx := context.Background()
 	cfg := &debugui.Config{}
-	hub := ws.NewHub(ctx)
+	bus := communication.NewBus()
 
-	service := NewUDPServerService(ctx, cfg, hub)
+	service := NewUDPServerService(ctx, cfg, bus, log.StandardLogger())
 
 	// HandleAll should not panic even if server is nil
 	assert.NotPanics(t, func() {
@@ -91,3 +92,20 @@ func TestUDPServerService_HandleAll(t *testing.T) {
 	err = service.HandleAll("127.0.0.1:12345", []byte("test packet"))
 	assert.NoError(t, err)
 }
+
+func TestUDPServerService_HandleAll_Publishes(t *testing.T) {
+	bus := communication.NewBus()
+	var published []communication.InternalMessage
+	var payloads [][]byte
+	communication.ServerPackets.Subscribe(bus, func(msg communication.InternalMessage, payload []byte) {
+		published = append(published, msg)
+		payloads = append(payloads, payload)
+	})
+	service := NewUDPServerService(context.Background(), &debugui.Config{}, bus, log.StandardLogger())
+
+	require.NoError(t, service.HandleAll("127.0.0.1:5000", []byte("hello")))
+
+	require.Len(t, published, 1)
+	assert.Equal(t, communication.CallerUDPServer, published[0].Caller)
+	assert.Equal(t, [][]byte{[]byte("hello")}, payloads)
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	mrand "math/rand"
+	"strconv"
+	"sync"
+)
+
+// nameAttempts is how often NameGenerator draws a random name before it
+// falls back to numbering a taken one
+const nameAttempts = 100
+
+// NameGenerator creates client names. With a seed it produces the same
+// sequence of names on every run.
+type NameGenerator struct {
+	mu        sync.Mutex
+	r         *mrand.Rand
+	fullNames bool
+}
+
+// NewNameGenerator returns a generator for "First" or, with fullNames,
+// "First Last" names. A seed of 0 picks a random seed.
+func NewNameGenerator(seed int64, fullNames bool) *NameGenerator {
+	g := &NameGenerator{}
+	g.Reset(seed, fullNames)
+	return g
+}
+
+// Reset restarts the generator with a new seed and name style
+func (g *NameGenerator) Reset(seed int64, fullNames bool) {
+	if seed == 0 {
+		seed = cryptoSeed()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.r = mrand.New(mrand.NewSource(seed))
+	g.fullNames = fullNames
+}
+
+// Next returns a name for which taken reports false
+func (g *NameGenerator) Next(taken func(string) bool) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var name string
+	for range nameAttempts {
+		name = g.name()
+		if !taken(name) {
+			return name
+		}
+	}
+	// Practically unreachable with 5 syllables, but never hand out a taken name
+	for n := 2; ; n++ {
+		numbered := name + " " + strconv.Itoa(n)
+		if !taken(numbered) {
+			return numbered
+		}
+	}
+}
+
+func (g *NameGenerator) name() string {
+	first := genFirstName(g.r, 5)
+	if !g.fullNames {
+		return first
+	}
+	return first + " " + genLastName(g.r)
+}
+
+// genLastName combines two of the lastParts, e.g. "Stonebrook"
+func genLastName(r *mrand.Rand) string {
+	first := lastParts[r.Intn(len(lastParts))]
+	second := lastParts[r.Intn(len(lastParts))]
+	for second == first {
+		second = lastParts[r.Intn(len(lastParts))]
+	}
+	return capFirst(first + second)
+}
+
+// GetFullName returns a random "First Last" name
+func GetFullName() string {
+	r := mrand.New(mrand.NewSource(cryptoSeed()))
+
+	return genFirstName(r, 5) + " " + genLastName(r)
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameGenerator_Seeded(t *testing.T) {
+	never := func(string) bool { return false }
+	a := NewNameGenerator(42, false)
+	b := NewNameGenerator(42, false)
+
+	for range 20 {
+		assert.Equal(t, a.Next(never), b.Next(never))
+	}
+}
+
+func TestNameGenerator_Reset(t *testing.T) {
+	never := func(string) bool { return false }
+	g := NewNameGenerator(7, false)
+	first := []string{g.Next(never), g.Next(never)}
+
+	g.Reset(7, false)
+
+	assert.Equal(t, first, []string{g.Next(never), g.Next(never)})
+}
+
+func TestNameGenerator_Unique(t *testing.T) {
+	g := NewNameGenerator(1, false)
+	used := map[string]bool{}
+	taken := func(name string) bool { return used[name] }
+
+	for range 500 {
+		name := g.Next(taken)
+		assert.False(t, used[name], "name %q handed out twice", name)
+		used[name] = true
+	}
+}
+
+func TestNameGenerator_AllTaken(t *testing.T) {
+	g := NewNameGenerator(1, false)
+	// Only numbered names are free
+	taken := func(name string) bool { return !regexp.MustCompile(` 3$`).MatchString(name) }
+
+	assert.Regexp(t, `^[A-Z][a-z]+ 3$`, g.Next(taken))
+}
+
+func TestNameGenerator_FullNames(t *testing.T) {
+	g := NewNameGenerator(3, true)
+
+	name := g.Next(func(string) bool { return false })
+
+	assert.Regexp(t, `^[A-Z][a-z]+ [A-Z][a-z]+$`, name)
+}
+
+func TestGetFullName(t *testing.T) {
+	assert.Regexp(t, `^[A-Z][a-z]+ [A-Z][a-z]+$`, GetFullName())
+}
@@ -0,0 +1,63 @@
+package util
+
+import "iter"
+
+// Ring is a bounded list that overwrites its oldest item when full, so
+// adding is constant time. It is not safe for concurrent use.
+type Ring[T any] struct {
+	items    []T
+	capacity int
+	// head is the index of the oldest item once the ring is full
+	head int
+}
+
+func NewRing[T any](capacity int) *Ring[T] {
+	return &Ring[T]{capacity: max(capacity, 1)}
+}
+
+// Push adds v, dropping the oldest item if the ring is full
+func (r *Ring[T]) Push(v T) {
+	if len(r.items) < r.capacity {
+		r.items = append(r.items, v)
+		return
+	}
+	r.items[r.head] = v
+	r.head = (r.head + 1) % r.capacity
+}
+
+// Len returns the number of items
+func (r *Ring[T]) Len() int {
+	return len(r.items)
+}
+
+// All yields the items oldest first
+func (r *Ring[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := range len(r.items) {
+			if !yield(r.items[(r.head+i)%len(r.items)]) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteFunc removes the items del returns true for and returns how many
+// were removed
+func (r *Ring[T]) DeleteFunc(del func(T) bool) int {
+	kept := make([]T, 0, len(r.items))
+	for v := range r.All() {
+		if !del(v) {
+			kept = append(kept, v)
+		}
+	}
+	removed := len(r.items) - len(kept)
+	r.items, r.head = kept, 0
+	return removed
+}
+
+// Clear removes all items and returns how many there were
+func (r *Ring[T]) Clear() int {
+	n := len(r.items)
+	r.items, r.head = nil, 0
+	return n
+}
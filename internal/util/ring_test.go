@@ -0,0 +1,26 @@
+package util
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing[int](3)
+	for i := range 5 {
+		r.Push(i)
+	}
+
+	assert.Equal(t, 3, r.Len())
+	assert.Equal(t, []int{2, 3, 4}, slices.Collect(r.All()))
+
+	assert.Equal(t, 1, r.DeleteFunc(func(v int) bool { return v == 3 }))
+	r.Push(5)
+	r.Push(6)
+	assert.Equal(t, []int{4, 5, 6}, slices.Collect(r.All()))
+
+	assert.Equal(t, 3, r.Clear())
+	assert.Empty(t, slices.Collect(r.All()))
+}
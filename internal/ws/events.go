@@ -0,0 +1,148 @@
+package ws
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/auraspeak/debug-ui/internal/util"
+)
+
+// EventKind groups the hub's broadcasts into the streams served over SSE
+type EventKind string
+
+const (
+	EventLog    EventKind = "log"
+	EventPacket EventKind = "packet"
+	EventState  EventKind = "state"
+)
+
+// eventKinds are the kinds events are recorded for
+var eventKinds = []EventKind{EventLog, EventPacket, EventState}
+
+// eventBacklog is the number of events kept per kind for Last-Event-ID
+// resumption, so heavy logging doesn't evict packet events
+const eventBacklog = 1024
+
+// subscriberBuffer is the number of matching events a subscriber may lag behind before it is dropped
+const subscriberBuffer = 256
+
+// newEventBacklogs returns an empty backlog for each kind
+func newEventBacklogs() map[EventKind]*util.Ring[Event] {
+	backlogs := make(map[EventKind]*util.Ring[Event], len(eventKinds))
+	for _, kind := range eventKinds {
+		backlogs[kind] = util.NewRing[Event](eventBacklog)
+	}
+	return backlogs
+}
+
+// Event is a broadcast recorded by the hub. Data holds exactly the bytes sent
+// to the WebSocket connections.
+type Event struct {
+	ID   uint64
+	Kind EventKind
+	// Content is the short string command of the event, e.g. "pkt,1,0,1" or "usu3"
+	Content string
+	Data    []byte
+}
+
+// kindOf classifies a short string command. Packets and resets are recorded
+// from their structured message, so their strings aren't recorded a second time.
+func kindOf(content string) EventKind {
+	switch {
+	case strings.HasPrefix(content, "pkt,"), content == "rst":
+		return ""
+	case strings.HasPrefix(content, "dgm,"):
+		return EventPacket
+	case strings.HasPrefix(content, "{"):
+		// JSON without message type, not one of our commands
+		return ""
+	default:
+		return EventState
+	}
+}
+
+func messageKind(t WebsocketMessageType) EventKind {
+	switch t {
+	case TypeLog:
+		return EventLog
+	case TypePacket:
+		return EventPacket
+	default:
+		return EventState
+	}
+}
+
+// record stores the event and hands it to the subscribers that match it.
+// Like Broadcast it must not log, the log hook records through it.
+func (wh *WebSocketHub) record(kind EventKind, content string, data []byte) {
+	if kind == "" {
+		return
+	}
+	wh.eventMu.Lock()
+	defer wh.eventMu.Unlock()
+	wh.lastEventID++
+	e := Event{ID: wh.lastEventID, Kind: kind, Content: content, Data: data}
+	wh.events[kind].Push(e)
+	for ch, match := range wh.subscribers {
+		if match != nil && !match(e) {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+			// Too slow, the subscriber resumes with Last-Event-ID after reconnecting
+			delete(wh.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// ClearEvents removes the recorded events of one kind and returns how many
+// were removed. Event IDs keep counting, so resumption stays consistent.
+func (wh *WebSocketHub) ClearEvents(kind EventKind) int {
+	wh.eventMu.Lock()
+	defer wh.eventMu.Unlock()
+	backlog, ok := wh.events[kind]
+	if !ok {
+		return 0
+	}
+	return backlog.Clear()
+}
+
+// SubscriberCount returns the number of event subscribers, like the SSE streams
+func (wh *WebSocketHub) SubscriberCount() int {
+	wh.eventMu.Lock()
+	defer wh.eventMu.Unlock()
+	return len(wh.subscribers)
+}
+
+// Subscribe returns the recorded events newer than lastID that match accepts
+// and a channel for the matching events that follow, nil match accepts all.
+// Only matching events count against the buffer of the channel, which is
+// closed when the subscriber falls too far behind or unsubscribe is called.
+// match runs while events are recorded and must not block.
+func (wh *WebSocketHub) Subscribe(lastID uint64, match func(Event) bool) (backlog []Event, events <-chan Event, unsubscribe func()) {
+	ch := make(chan Event, subscriberBuffer)
+	wh.eventMu.Lock()
+	for _, kind := range eventKinds {
+		for e := range wh.events[kind].All() {
+			if e.ID > lastID && (match == nil || match(e)) {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+	wh.subscribers[ch] = match
+	wh.eventMu.Unlock()
+	slices.SortFunc(backlog, func(a, b Event) int { return cmp.Compare(a.ID, b.ID) })
+
+	unsubscribe = func() {
+		wh.eventMu.Lock()
+		defer wh.eventMu.Unlock()
+		if _, ok := wh.subscribers[ch]; ok {
+			delete(wh.subscribers, ch)
+			close(ch)
+		}
+	}
+	return backlog, ch, unsubscribe
+}
@@ -0,0 +1,80 @@
+package ws
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// sseKeepAlive is the interval of the comment lines that keep idle streams open
+const sseKeepAlive = 15 * time.Second
+
+// ServeSSE streams the events of one kind as text/event-stream until the
+// request or the hub ends. Only events accepted by match are sent, match
+// must not block. Clients
+// resume with the Last-Event-ID header or the lastEventId query parameter,
+// as far as the backlog reaches.
+func (wh *WebSocketHub) ServeSSE(w http.ResponseWriter, r *http.Request, kind EventKind, match func(Event) bool) {
+	var lastID uint64
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		lastID, _ = strconv.ParseUint(id, 10, 64)
+	} else if id := r.URL.Query().Get("lastEventId"); id != "" {
+		lastID, _ = strconv.ParseUint(id, 10, 64)
+	}
+
+	backlog, events, unsubscribe := wh.Subscribe(lastID, func(e Event) bool {
+		return e.Kind == kind && (match == nil || match(e))
+	})
+	defer unsubscribe()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(e Event) error {
+		// Log entries end with a newline, which would end the event early
+		data := bytes.ReplaceAll(bytes.TrimRight(e.Data, "\n"), []byte("\n"), []byte("\ndata: "))
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Kind, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	for _, e := range backlog {
+		if err := send(e); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := send(e); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-wh.ctx.Done():
+			return
+		}
+	}
+}
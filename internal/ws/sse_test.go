@@ -0,0 +1,153 @@
+package ws
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocketHub_Subscribe_Backlog(t *testing.T) {
+	hub := NewHub(context.Background())
+	hub.Broadcast([]byte("uss"))
+	hub.Broadcast([]byte("pkt,1,0,1"))
+	require.NoError(t, hub.BroadcastMessage(WebSocketMessage{Type: TypePacket, Content: "pkt,1,0,1"}))
+
+	backlog, events, unsubscribe := hub.Subscribe(0, nil)
+	defer unsubscribe()
+
+	// The "pkt," string is recorded through its PKT message only
+	require.Len(t, backlog, 2)
+	assert.Equal(t, EventState, backlog[0].Kind)
+	assert.Equal(t, EventPacket, backlog[1].Kind)
+	assert.Equal(t, "pkt,1,0,1", backlog[1].Content)
+
+	hub.Broadcast([]byte("usu1"))
+	e := <-events
+	assert.Equal(t, backlog[1].ID+1, e.ID)
+	assert.Equal(t, "usu1", e.Content)
+
+	resumed, _, unsubscribeResumed := hub.Subscribe(backlog[0].ID, nil)
+	defer unsubscribeResumed()
+	assert.Len(t, resumed, 2)
+}
+
+func TestWebSocketHub_Subscribe_DropsSlowSubscriber(t *testing.T) {
+	hub := NewHub(context.Background())
+	_, events, unsubscribe := hub.Subscribe(0, nil)
+	defer unsubscribe()
+
+	for range subscriberBuffer + 1 {
+		hub.Broadcast([]byte("map"))
+	}
+
+	n := 0
+	for range events {
+		n++
+	}
+	assert.Equal(t, subscriberBuffer, n)
+}
+
+func TestWebSocketHub_Backlog_IsBounded(t *testing.T) {
+	hub := NewHub(context.Background())
+	for range eventBacklog + 10 {
+		hub.Broadcast([]byte("map"))
+	}
+
+	backlog, _, unsubscribe := hub.Subscribe(0, nil)
+	defer unsubscribe()
+
+	require.Len(t, backlog, eventBacklog)
+	assert.Equal(t, uint64(11), backlog[0].ID)
+}
+
+func TestWebSocketHub_Subscribe_OnlyMatchingEventsAreBuffered(t *testing.T) {
+	hub := NewHub(context.Background())
+	_, events, unsubscribe := hub.Subscribe(0, func(e Event) bool { return e.Kind == EventPacket })
+	defer unsubscribe()
+
+	for range subscriberBuffer + 1 {
+		hub.BroadcastLog([]byte("{}"))
+	}
+	hub.Broadcast([]byte("dgm,1,1"))
+
+	// Unrelated events don't fill the buffer, the subscriber is still there
+	e := <-events
+	assert.Equal(t, "dgm,1,1", e.Content)
+	assert.Equal(t, 1, hub.SubscriberCount())
+}
+
+func TestWebSocketHub_Backlog_IsPerKind(t *testing.T) {
+	hub := NewHub(context.Background())
+	hub.Broadcast([]byte("dgm,1,1"))
+	for range eventBacklog + 10 {
+		hub.BroadcastLog([]byte("{}"))
+	}
+	hub.Broadcast([]byte("uss"))
+
+	backlog, _, unsubscribe := hub.Subscribe(0, nil)
+	defer unsubscribe()
+
+	// Heavy logging doesn't evict the packet, the backlog is in ID order
+	require.Len(t, backlog, eventBacklog+2)
+	assert.Equal(t, "dgm,1,1", backlog[0].Content)
+	assert.Equal(t, uint64(12), backlog[1].ID)
+	assert.Equal(t, "uss", backlog[len(backlog)-1].Content)
+}
+
+func TestWebSocketHub_ServeSSE(t *testing.T) {
+	hub := NewHub(context.Background())
+	hub.Broadcast([]byte("uss"))
+	hub.Broadcast([]byte("usu1"))
+	hub.BroadcastLog([]byte("{\"msg\":\"hello\"}\n"))
+	hub.Broadcast([]byte("usu2"))
+
+	// The request is already done, so only the backlog is written
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("GET", "/api/stream/state", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", "1")
+	rr := httptest.NewRecorder()
+
+	hub.ServeSSE(rr, req, EventState, func(e Event) bool { return e.Content != "usu2" })
+
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "id: 2\nevent: state\ndata: usu1\n\n", rr.Body.String())
+}
+
+func TestWebSocketHub_ServeSSE_LogEvent(t *testing.T) {
+	hub := NewHub(context.Background())
+	hub.BroadcastLog([]byte("{\"msg\":\"hello\"}\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("GET", "/api/stream/logs?lastEventId=0", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	hub.ServeSSE(rr, req, EventLog, nil)
+
+	assert.Equal(t, "id: 1\nevent: log\ndata: {\"msg\":\"hello\"}\n\n", rr.Body.String())
+}
+
+func TestWebSocketHub_ClearEvents(t *testing.T) {
+	hub := NewHub(context.Background())
+	hub.BroadcastLog([]byte("{}"))
+	hub.Broadcast([]byte("uss"))
+	hub.BroadcastLog([]byte("{}"))
+
+	assert.Equal(t, 2, hub.ClearEvents(EventLog))
+
+	backlog, _, unsubscribe := hub.Subscribe(0, nil)
+	defer unsubscribe()
+	require.Len(t, backlog, 1)
+	assert.Equal(t, uint64(2), backlog[0].ID)
+
+	// IDs keep counting after a clear
+	hub.Broadcast([]byte("map"))
+	backlog, _, unsubscribeAgain := hub.Subscribe(2, nil)
+	defer unsubscribeAgain()
+	require.Len(t, backlog, 1)
+	assert.Equal(t, uint64(4), backlog[0].ID)
+}
@@ -4,6 +4,13 @@ import "golang.org/x/net/websocket"
 
 func (wh *WebSocketHub) HandleWS(ws *websocket.Conn) {
 	wh.mu.Lock()
+	// Closed hubs take no new connections
+	if wh.ctx.Err() != nil {
+		wh.mu.Unlock()
+		return
+	}
+	wh.wg.Add(1)
+	defer wh.wg.Done()
 	wh.conns[ws] = true
 	wh.mu.Unlock()
 
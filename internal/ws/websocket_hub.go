@@ -2,11 +2,14 @@ package ws
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net"
 	"sync"
 	"time"
 
+	"github.com/auraspeak/debug-ui/internal/middleware"
+	"github.com/auraspeak/debug-ui/internal/util"
 	log "github.com/sirupsen/logrus"
 
 	"golang.org/x/net/websocket"
@@ -15,12 +18,20 @@ import (
 type WebsocketMessageType string
 
 const (
-	TypeLog WebsocketMessageType = "LOG"
+	TypeLog     WebsocketMessageType = "LOG"
+	TypePacket  WebsocketMessageType = "PKT"
+	TypeSendJob WebsocketMessageType = "JOB"
+	TypeReset   WebsocketMessageType = "RST"
+	// TypeShutdown is the last message before the server closes the connections
+	TypeShutdown WebsocketMessageType = "SHD"
 )
 
+// WebSocketMessage is a structured frame for events that don't fit into the
+// short string commands (uss, usu, pkt, ...). Data carries the event payload.
 type WebSocketMessage struct {
 	Type    WebsocketMessageType `json:"type"`
 	Content string               `json:"content"`
+	Data    any                  `json:"data,omitempty"`
 }
 
 type WebSocketHub struct {
@@ -29,17 +40,34 @@ type WebSocketHub struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
+	// sends tracks the writes of send, closed stops new ones
+	sends  sync.WaitGroup
+	closed bool
+
+	// Handles commands sent by WebSocket clients
+	handleCommand func(msg string) bool
+	// logger of the hub's errors, the standard logger by default
+	logger *log.Logger
+
+	// Recorded events for the SSE streams, by kind
+	events      map[EventKind]*util.Ring[Event]
+	lastEventID uint64
+	// subscribers by channel with their match func, nil for all events
+	subscribers map[chan Event]func(Event) bool
+	eventMu     sync.Mutex
 }
 
 func NewHub(ctx context.Context) *WebSocketHub {
 	hubCtx, cancel := context.WithCancel(ctx)
 	wsHub := &WebSocketHub{
-		conns:  make(map[*websocket.Conn]bool),
-		mu:     sync.Mutex{},
-		ctx:    hubCtx,
-		cancel: cancel,
+		conns:       make(map[*websocket.Conn]bool),
+		mu:          sync.Mutex{},
+		ctx:         hubCtx,
+		cancel:      cancel,
+		logger:      log.StandardLogger(),
+		events:      newEventBacklogs(),
+		subscribers: make(map[chan Event]func(Event) bool),
 	}
-	log.AddHook(NewWebSocketHook(wsHub))
 	return wsHub
 }
 
@@ -54,7 +82,7 @@ func (wh *WebSocketHub) readLoop(ws *websocket.Conn) {
 		// Set the ReadDeadline, so we can peridoicly test the context
 		err := ws.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
 		if err != nil {
-			log.WithField("caller", "web").WithError(err).Error("readLoop error")
+			wh.log().WithError(err).Error("readLoop error")
 		}
 		n, err := ws.Read(buf)
 		if err != nil {
@@ -66,11 +94,23 @@ func (wh *WebSocketHub) readLoop(ws *websocket.Conn) {
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 				continue
 			}
-			log.WithField("caller", "web").WithError(err).Error("readLoop error")
+			wh.log().WithError(err).Error("readLoop error")
 			break
 		}
+		// Commands are handled, other messages of WebSocket clients are
+		// passed on, but not recorded as events
 		msg := buf[:n]
-		wh.Broadcast(msg)
+		// Viewers only receive
+		if !middleware.CanWrite(ws.Request()) {
+			continue
+		}
+		wh.mu.Lock()
+		handleCommand := wh.handleCommand
+		wh.mu.Unlock()
+		if handleCommand != nil && handleCommand(string(msg)) {
+			continue
+		}
+		wh.send(msg)
 
 	}
 }
@@ -78,28 +118,143 @@ func (wh *WebSocketHub) readLoop(ws *websocket.Conn) {
 func (wh *WebSocketHub) Broadcast(b []byte) {
 	// NOTE: Do NOT call log.Infof here! It would cause infinite recursion
 	// because the WebSocketHook calls Broadcast, which would call log.Infof again
+	wh.record(kindOf(string(b)), string(b), b)
+	wh.send(b)
+}
+
+// SetCommandHandler sets the handler for messages of WebSocket clients. If
+// it returns true, the message was a command and is not passed on.
+func (wh *WebSocketHub) SetCommandHandler(handler func(msg string) bool) {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	wh.handleCommand = handler
+}
+
+// SetLogger sets the logger of the hub's errors
+func (wh *WebSocketHub) SetLogger(logger *log.Logger) {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	wh.logger = logger
+}
+
+// log returns an entry of the hub's logger
+func (wh *WebSocketHub) log() *log.Entry {
 	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	return wh.logger.WithField("caller", "web")
+}
+
+// BroadcastLog sends a formatted log entry to all connections
+func (wh *WebSocketHub) BroadcastLog(b []byte) {
+	wh.record(EventLog, "", b)
+	wh.send(b)
+}
+
+func (wh *WebSocketHub) send(b []byte) {
+	wh.mu.Lock()
+	if wh.closed {
+		wh.mu.Unlock()
+		return
+	}
 	conns := make([]*websocket.Conn, 0, len(wh.conns))
 	for ws := range wh.conns {
 		conns = append(conns, ws)
 	}
+	wh.sends.Add(len(conns))
 	wh.mu.Unlock()
 
 	for _, ws := range conns {
 		go func(ws *websocket.Conn) {
+			defer wh.sends.Done()
 			if _, err := ws.Write(b); err != nil {
-				log.WithField("caller", "web").WithError(err).Error("broadcast error")
+				wh.log().WithError(err).Error("broadcast error")
 			}
 		}(ws)
 	}
 }
 
+// BroadcastMessage sends msg as JSON frame to all connections
+func (wh *WebSocketHub) BroadcastMessage(msg WebSocketMessage) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	wh.record(messageKind(msg.Type), msg.Content, b)
+	wh.send(b)
+	return nil
+}
+
+// ConnectionCount returns the number of open WebSocket connections
+func (wh *WebSocketHub) ConnectionCount() int {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	return len(wh.conns)
+}
+
 // Cancel cancels the WebSocketHub context, signaling all goroutines to stop
 func (wh *WebSocketHub) Cancel() {
+	// Under mu, so HandleWS doesn't add a connection while Wait waits
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
 	wh.cancel()
 }
 
-// Wait waits for all goroutines in the hub to finish
+// Wait waits for all connections of the hub to end
 func (wh *WebSocketHub) Wait() {
 	wh.wg.Wait()
 }
+
+// Close sends msg to every connection as the last message, waiting for the
+// writes, then ends the connections and event streams. Later broadcasts are
+// dropped. It returns ctx.Err() if the connections didn't end before ctx.
+func (wh *WebSocketHub) Close(ctx context.Context, msg WebSocketMessage) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	wh.record(messageKind(msg.Type), msg.Content, b)
+
+	wh.mu.Lock()
+	wh.closed = true
+	conns := make([]*websocket.Conn, 0, len(wh.conns))
+	for ws := range wh.conns {
+		conns = append(conns, ws)
+	}
+	wh.mu.Unlock()
+	// Earlier broadcasts go out first
+	if !waitCtx(ctx, wh.sends.Wait) {
+		return ctx.Err()
+	}
+	deadline, _ := ctx.Deadline()
+	var writes sync.WaitGroup
+	for _, ws := range conns {
+		writes.Go(func() {
+			ws.SetWriteDeadline(deadline)
+			if _, err := ws.Write(b); err != nil {
+				wh.log().WithError(err).Error("broadcast error")
+			}
+		})
+	}
+	writes.Wait()
+
+	wh.Cancel()
+	if !waitCtx(ctx, wh.wg.Wait) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// waitCtx runs wait and reports whether it returned before ctx ended
+func waitCtx(ctx context.Context, wait func()) bool {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
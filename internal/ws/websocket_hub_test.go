@@ -2,11 +2,14 @@ package ws
 
 import (
 	"context"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
 )
 
 func TestNewHub(t *testing.T) {
@@ -58,3 +61,50 @@ func TestWebSocketHub_Broadcast_WithConnections(t *testing.T) {
 		hub.Broadcast([]byte("test message"))
 	})
 }
+
+func TestWebSocketHub_BroadcastMessage_NoConnections(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub(ctx)
+
+	err := hub.BroadcastMessage(WebSocketMessage{
+		Type:    TypePacket,
+		Content: "pkt,1,0,1",
+		Data:    map[string]int{"fromClientId": 1},
+	})
+	assert.NoError(t, err)
+}
+
+func TestWebSocketHub_BroadcastMessage_Unmarshalable(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub(ctx)
+
+	err := hub.BroadcastMessage(WebSocketMessage{Type: TypePacket, Data: make(chan int)})
+	assert.Error(t, err)
+}
+
+func TestWebSocketHub_Close(t *testing.T) {
+	hub := NewHub(context.Background())
+	srv := httptest.NewServer(websocket.Handler(hub.HandleWS))
+	defer srv.Close()
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), "", srv.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Eventually(t, func() bool { return hub.ConnectionCount() == 1 }, time.Second, 10*time.Millisecond)
+	_, events, unsubscribe := hub.Subscribe(0, nil)
+	defer unsubscribe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, hub.Close(ctx, WebSocketMessage{Type: TypeShutdown, Content: "server shutting down"}))
+
+	// The final message arrives before the connection ends
+	var msg WebSocketMessage
+	require.NoError(t, websocket.JSON.Receive(conn, &msg))
+	assert.Equal(t, TypeShutdown, msg.Type)
+	var b []byte
+	assert.Error(t, websocket.Message.Receive(conn, &b))
+	assert.Zero(t, hub.ConnectionCount())
+	e := <-events
+	assert.Equal(t, EventState, e.Kind)
+	assert.Equal(t, "server shutting down", e.Content)
+}
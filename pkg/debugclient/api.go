@@ -0,0 +1,230 @@
+package debugclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// StartServer starts the UDP server
+func (c *Client) StartServer(ctx context.Context) (Success, error) {
+	return post[Success](ctx, c, "/api/v1/server/start", nil, nil)
+}
+
+// StopServer stops the UDP server
+func (c *Client) StopServer(ctx context.Context) (Success, error) {
+	return post[Success](ctx, c, "/api/v1/server/stop", nil, nil)
+}
+
+// ServerState returns the state of the UDP server
+func (c *Client) ServerState(ctx context.Context) (ServerState, error) {
+	return get[ServerState](ctx, c, "/api/v1/server/get", nil)
+}
+
+// StartClient starts a UDP client; a zero request picks a random name
+func (c *Client) StartClient(ctx context.Context, req StartClientRequest) (StartedClient, error) {
+	return post[StartedClient](ctx, c, "/api/v1/client/start", nil, req)
+}
+
+// StopClient stops a UDP client by name
+func (c *Client) StopClient(ctx context.Context, name string) (Success, error) {
+	return post[Success](ctx, c, "/api/v1/client/stop", url.Values{"name": {name}}, nil)
+}
+
+// Send sends a datagram from a client
+func (c *Client) Send(ctx context.Context, req SendRequest) (SendResponse, error) {
+	return post[SendResponse](ctx, c, "/api/v1/client/send", nil, req)
+}
+
+// ClientByName returns the state of a client with its datagrams
+func (c *Client) ClientByName(ctx context.Context, name string) (ClientState, error) {
+	return get[ClientState](ctx, c, "/api/v1/client/get/name", url.Values{"name": {name}})
+}
+
+// ClientByID returns the state of a client, with the datagrams if withDatagrams
+func (c *Client) ClientByID(ctx context.Context, id int, withDatagrams bool) (ClientState, error) {
+	return get[ClientState](ctx, c, "/api/v1/client/get/id", url.Values{
+		"id":        {strconv.Itoa(id)},
+		"datagrams": {strconv.FormatBool(withDatagrams)},
+	})
+}
+
+// AllClients returns the ID and name of every client
+func (c *Client) AllClients(ctx context.Context) (AllClients, error) {
+	return get[AllClients](ctx, c, "/api/v1/client/get/all", nil)
+}
+
+// ListClients returns a sorted, filtered page of the clients
+func (c *Client) ListClients(ctx context.Context, p ListParams) (ClientPage, error) {
+	q := values{}.
+		int("page", p.Page).
+		int("pageSize", p.PageSize).
+		set("cursor", p.Cursor).
+		set("q", p.Query).
+		set("sort", p.Sort).
+		set("order", p.Order).
+		int("activeWithin", p.ActiveWithin).
+		int("minDatagrams", p.MinDatagrams).
+		int("maxDatagrams", p.MaxDatagrams)
+	if p.Running != nil {
+		q.set("running", strconv.FormatBool(*p.Running))
+	}
+	for _, tag := range p.Tags {
+		url.Values(q).Add("tag", tag)
+	}
+	return get[ClientPage](ctx, c, "/api/v1/client/get/all/paginated", url.Values(q))
+}
+
+// Datagrams returns datagrams of a client after a sequence number
+func (c *Client) Datagrams(ctx context.Context, id int, p DatagramParams) (DatagramPage, error) {
+	q := values{}.set("id", strconv.Itoa(id)).int("since", p.Since).int("limit", p.Limit)
+	if p.Reverse {
+		q.set("reverse", "true")
+	}
+	return get[DatagramPage](ctx, c, "/api/v1/client/datagrams", url.Values(q))
+}
+
+// ClientMap returns the clients and their connections, optionally only those with all tags
+func (c *Client) ClientMap(ctx context.Context, tags ...string) (ClientMap, error) {
+	return get[ClientMap](ctx, c, "/api/v1/client/map", url.Values{"tag": tags})
+}
+
+// SetClientLabels sets the group and tags of a client
+func (c *Client) SetClientLabels(ctx context.Context, req SetClientLabelsRequest) (ClientListItem, error) {
+	return post[ClientListItem](ctx, c, "/api/v1/client/labels", nil, req)
+}
+
+// SetNameGenerator reseeds the generator of client names
+func (c *Client) SetNameGenerator(ctx context.Context, req NameGeneratorRequest) (Success, error) {
+	return post[Success](ctx, c, "/api/v1/client/names", nil, req)
+}
+
+// StartSendJob starts sending a datagram periodically
+func (c *Client) StartSendJob(ctx context.Context, req StartSendJobRequest) (SendJob, error) {
+	return post[SendJob](ctx, c, "/api/v1/client/jobs/start", nil, req)
+}
+
+// SendJobs returns the send jobs, of one client if clientID isn't 0
+func (c *Client) SendJobs(ctx context.Context, clientID int) (AllSendJobs, error) {
+	return get[AllSendJobs](ctx, c, "/api/v1/client/jobs/all", url.Values(values{}.int("clientId", clientID)))
+}
+
+func (c *Client) PauseSendJob(ctx context.Context, id int) (SendJob, error) {
+	return post[SendJob](ctx, c, "/api/v1/client/jobs/pause", url.Values{"id": {strconv.Itoa(id)}}, nil)
+}
+
+func (c *Client) ResumeSendJob(ctx context.Context, id int) (SendJob, error) {
+	return post[SendJob](ctx, c, "/api/v1/client/jobs/resume", url.Values{"id": {strconv.Itoa(id)}}, nil)
+}
+
+func (c *Client) CancelSendJob(ctx context.Context, id int) (SendJob, error) {
+	return post[SendJob](ctx, c, "/api/v1/client/jobs/cancel", url.Values{"id": {strconv.Itoa(id)}}, nil)
+}
+
+// Groups returns all client groups
+func (c *Client) Groups(ctx context.Context) (Groups, error) {
+	return get[Groups](ctx, c, "/api/v1/groups/all", nil)
+}
+
+func (c *Client) StartGroup(ctx context.Context, req StartGroupRequest) (GroupResult, error) {
+	return post[GroupResult](ctx, c, "/api/v1/groups/start", nil, req)
+}
+
+func (c *Client) StopGroup(ctx context.Context, group string) (GroupResult, error) {
+	return post[GroupResult](ctx, c, "/api/v1/groups/stop", url.Values{"group": {group}}, nil)
+}
+
+func (c *Client) SendGroup(ctx context.Context, req SendGroupRequest) (GroupResult, error) {
+	return post[GroupResult](ctx, c, "/api/v1/groups/send", nil, req)
+}
+
+func (c *Client) ClearGroup(ctx context.Context, group string) (GroupResult, error) {
+	return post[GroupResult](ctx, c, "/api/v1/groups/clear", url.Values{"group": {group}}, nil)
+}
+
+// Traces returns the traces as Mermaid diagram, of one client if name isn't empty
+func (c *Client) Traces(ctx context.Context, name string) (Traces, error) {
+	return get[Traces](ctx, c, "/api/v1/traces/all", url.Values(values{}.set("name", name)))
+}
+
+func (c *Client) StartFuzz(ctx context.Context, req StartFuzzRequest) (Success, error) {
+	return post[Success](ctx, c, "/api/v1/fuzz/start", nil, req)
+}
+
+func (c *Client) StopFuzz(ctx context.Context) (Success, error) {
+	return post[Success](ctx, c, "/api/v1/fuzz/stop", nil, nil)
+}
+
+func (c *Client) FuzzSummary(ctx context.Context) (FuzzSummary, error) {
+	return get[FuzzSummary](ctx, c, "/api/v1/fuzz/get", nil)
+}
+
+func (c *Client) Templates(ctx context.Context) ([]Template, error) {
+	resp, err := get[struct {
+		Templates []Template `json:"templates"`
+	}](ctx, c, "/api/v1/templates/all", nil)
+	return resp.Templates, err
+}
+
+func (c *Client) Template(ctx context.Context, name string) (Template, error) {
+	return get[Template](ctx, c, "/api/v1/templates/get", url.Values{"name": {name}})
+}
+
+func (c *Client) SaveTemplate(ctx context.Context, tmpl Template) (Success, error) {
+	return post[Success](ctx, c, "/api/v1/templates/save", nil, tmpl)
+}
+
+func (c *Client) DeleteTemplate(ctx context.Context, name string) (Success, error) {
+	return post[Success](ctx, c, "/api/v1/templates/delete", url.Values{"name": {name}}, nil)
+}
+
+// ResetWorkspace wipes the state between test runs
+func (c *Client) ResetWorkspace(ctx context.Context, req ResetRequest) (ResetResponse, error) {
+	return post[ResetResponse](ctx, c, "/api/v1/workspace/reset", nil, req)
+}
+
+func logQuery(p LogParams) values {
+	q := values{}.set("level", p.Level).set("caller", p.Caller).set("q", p.Query).int("limit", p.Limit)
+	if p.ClientID != nil {
+		q.set("clientId", strconv.Itoa(*p.ClientID))
+	}
+	if !p.Since.IsZero() {
+		q.set("since", p.Since.Format(time.RFC3339Nano))
+	}
+	if !p.Until.IsZero() {
+		q.set("until", p.Until.Format(time.RFC3339Nano))
+	}
+	return q
+}
+
+// Logs returns the stored log entries, oldest first
+func (c *Client) Logs(ctx context.Context, p LogParams) (Logs, error) {
+	return get[Logs](ctx, c, "/api/v1/logs", url.Values(logQuery(p)))
+}
+
+// LogsNDJSON downloads the stored log entries, one JSON object per line
+func (c *Client) LogsNDJSON(ctx context.Context, p LogParams) (io.ReadCloser, error) {
+	return c.request(ctx, "GET", "/api/v1/logs", url.Values(logQuery(p).set("format", "ndjson")), nil)
+}
+
+func (c *Client) LogLevels(ctx context.Context) (LogLevels, error) {
+	return get[LogLevels](ctx, c, "/api/v1/logs/levels", nil)
+}
+
+func (c *Client) SetLogLevel(ctx context.Context, req SetLogLevelRequest) (LogLevels, error) {
+	return post[LogLevels](ctx, c, "/api/v1/logs/levels", nil, req)
+}
+
+// Diagnostics returns goroutine counts, channel backlogs, lock times,
+// connections and memory of the server
+func (c *Client) Diagnostics(ctx context.Context) (Diagnostics, error) {
+	return get[Diagnostics](ctx, c, "/api/v1/diagnostics", nil)
+}
+
+// OpenAPI returns the OpenAPI document of the server
+func (c *Client) OpenAPI(ctx context.Context) (json.RawMessage, error) {
+	return get[json.RawMessage](ctx, c, "/api/v1/openapi.json", nil)
+}
@@ -0,0 +1,197 @@
+// Package debugclient is a typed Go client for the debug-ui REST and
+// WebSocket APIs, meant for integration tests of the server and clients.
+//
+//	c, err := debugclient.New("http://localhost:8080", debugclient.WithToken(token))
+//	started, err := c.StartClient(ctx, debugclient.StartClientRequest{Name: "alice"})
+//	...
+//	d, err := c.WaitForReceived(ctx, started.Id, debugclient.PayloadContains([]byte("pong")))
+package debugclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Error is a non-2xx response of the API
+type Error struct {
+	StatusCode int
+	// Code tells what went wrong, e.g. ErrClientNotFound
+	Code    ErrorCode `json:"errorCode"`
+	Message string    `json:"message"`
+	Details string    `json:"details"`
+	// RequestID finds the server's log entries of the request
+	RequestID string `json:"requestId"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("debug-ui: %d %s", e.StatusCode, e.Message)
+	if e.Details != "" {
+		msg += ": " + e.Details
+	}
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// IsCode reports whether err is an Error with the given code
+func IsCode(err error, code ErrorCode) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// Client talks to one debug-ui instance. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	tlsConfig  *tls.Config
+}
+
+type Option func(*Client)
+
+// WithToken authenticates every request with a bearer token
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithHTTPClient replaces http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithTLSConfig is used for HTTPS and WSS, e.g. to trust a self-signed
+// certificate. It replaces the transport of the HTTP client.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) { c.tlsConfig = config }
+}
+
+// New returns a client for the debug-ui at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("debug-ui: invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("debug-ui: base URL needs http or https, got %q", baseURL)
+	}
+	c := &Client{baseURL: u, httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = c.tlsConfig
+		httpClient := *c.httpClient
+		httpClient.Transport = transport
+		c.httpClient = &httpClient
+	}
+	return c, nil
+}
+
+// BaseURL returns the URL the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL.String()
+}
+
+func (c *Client) url(path string, query url.Values) string {
+	u := *c.baseURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// request sends a request and returns the response body, which the caller closes
+func (c *Client) request(ctx context.Context, method, path string, query url.Values, body any) (io.ReadCloser, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("debug-ui: encoding request: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path, query), reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		apiErr := &Error{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Request-ID")}
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if json.Unmarshal(b, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(b))
+			if apiErr.Message == "" {
+				apiErr.Message = http.StatusText(resp.StatusCode)
+			}
+		}
+		return nil, apiErr
+	}
+	return resp.Body, nil
+}
+
+// do sends a request and decodes the JSON response into out, if not nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	respBody, err := c.request(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer respBody.Close()
+	if out == nil {
+		_, err = io.Copy(io.Discard, respBody)
+		return err
+	}
+	if err := json.NewDecoder(respBody).Decode(out); err != nil {
+		return fmt.Errorf("debug-ui: decoding %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// get and post decode into a new T
+func get[T any](ctx context.Context, c *Client, path string, query url.Values) (T, error) {
+	var out T
+	err := c.do(ctx, http.MethodGet, path, query, nil, &out)
+	return out, err
+}
+
+func post[T any](ctx context.Context, c *Client, path string, query url.Values, body any) (T, error) {
+	var out T
+	err := c.do(ctx, http.MethodPost, path, query, body, &out)
+	return out, err
+}
+
+// values builds query parameters, leaving out empty values
+type values url.Values
+
+func (v values) set(key, value string) values {
+	if value != "" {
+		url.Values(v).Set(key, value)
+	}
+	return v
+}
+
+func (v values) int(key string, value int) values {
+	if value != 0 {
+		url.Values(v).Set(key, strconv.Itoa(value))
+	}
+	return v
+}
@@ -0,0 +1,124 @@
+package debugclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auraspeak/debug-ui/app"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient serves a fresh debug-ui server and returns a client for it
+func newTestClient(t *testing.T, opts ...Option) *Client {
+	t.Helper()
+	server := app.NewServer(8080, 9090, debugui.Config{})
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+
+	c, err := New(ts.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNew_InvalidURL(t *testing.T) {
+	_, err := New("localhost:8080")
+	assert.Error(t, err)
+}
+
+func TestClient_ServerState(t *testing.T) {
+	c := newTestClient(t)
+
+	state, err := c.ServerState(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, state.IsAlive)
+}
+
+func TestClient_Error(t *testing.T) {
+	c := newTestClient(t)
+
+	_, err := c.ClientByID(context.Background(), 42, false)
+
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr), "got %v", err)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, ErrClientNotFound, apiErr.Code)
+	assert.NotEmpty(t, apiErr.Message)
+	assert.NotEmpty(t, apiErr.RequestID)
+	assert.True(t, IsCode(err, ErrClientNotFound))
+}
+
+func TestClient_LogLevels(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	levels, err := c.SetLogLevel(ctx, SetLogLevelRequest{Caller: "web", Level: "debug"})
+	require.NoError(t, err)
+	assert.Equal(t, "debug", levels.Callers["web"])
+
+	levels, err = c.LogLevels(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "debug", levels.Callers["web"])
+}
+
+func TestClient_Diagnostics(t *testing.T) {
+	c := newTestClient(t)
+
+	diagnostics, err := c.Diagnostics(context.Background())
+	require.NoError(t, err)
+	assert.Positive(t, diagnostics.Goroutines.Total)
+	assert.Equal(t, "server", diagnostics.Locks[0].Name)
+}
+
+func TestClient_OpenAPI(t *testing.T) {
+	c := newTestClient(t)
+
+	doc, err := c.OpenAPI(context.Background())
+
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), `"openapi"`)
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	c := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sub, err := c.Subscribe(ctx)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	_, err = c.SetLogLevel(ctx, SetLogLevelRequest{Level: "info"})
+	require.NoError(t, err)
+
+	e, err := sub.WaitFor(ctx, func(e Event) bool { return e.Type == EventLogLevels })
+	require.NoError(t, err)
+	assert.Equal(t, "lvu", e.Raw)
+
+	sub.Close()
+	_, err = sub.Next(ctx)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestClient_Token(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		w.Write([]byte(`{"shouldStop":false,"isAlive":true}`))
+	}))
+	defer ts.Close()
+	c, err := New(ts.URL, WithToken("secret"))
+	require.NoError(t, err)
+
+	state, err := c.ServerState(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, state.IsAlive)
+	assert.Equal(t, "Bearer secret", got)
+}
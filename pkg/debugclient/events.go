@@ -0,0 +1,175 @@
+package debugclient
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventType tells what an Event is about
+type EventType string
+
+const (
+	// EventServerState: the UDP server state changed ("uss")
+	EventServerState EventType = "uss"
+	// EventClientState: the state of ClientID changed ("usu<id>")
+	EventClientState EventType = "usu"
+	// EventClientAdded: a client was started ("cnu")
+	EventClientAdded EventType = "cnu"
+	// EventMap: the client map changed ("map")
+	EventMap EventType = "map"
+	// EventReload: the server restarted, the UI reloads ("rp")
+	EventReload EventType = "rp"
+	// EventFuzz: the fuzz run made progress ("fzu")
+	EventFuzz EventType = "fzu"
+	// EventDatagram: ClientID recorded the datagram Seq ("dgm,<id>,<seq>")
+	EventDatagram EventType = "dgm"
+	// EventPacketNotice is the short form of a packet, with From, To and
+	// Direction in Packet but without payload ("pkt,<from>,<to>,<dir>").
+	// Every notice is followed by an EventPacket.
+	EventPacketNotice EventType = "pkt"
+	// EventPacket: a datagram was sent or received, Packet has all fields (PKT message)
+	EventPacket EventType = "PKT"
+	// EventSendJob: a send job changed, see SendJob (JOB message)
+	EventSendJob EventType = "JOB"
+	// EventResetNotice is the short form of EventReset ("rst")
+	EventResetNotice EventType = "rst"
+	// EventReset: the workspace was reset, see Reset (RST message)
+	EventReset EventType = "RST"
+	// EventShutdown: the server shuts down and closes the connection (SHD message)
+	EventShutdown EventType = "SHD"
+	// EventLogLevels: a log level changed ("lvu")
+	EventLogLevels EventType = "lvu"
+	// EventLog: a log entry, see Log
+	EventLog EventType = "log"
+	// EventUnknown is any other message
+	EventUnknown EventType = ""
+)
+
+// LogEvent is a log entry as the server's JSON formatter writes it
+type LogEvent struct {
+	Time    time.Time
+	Level   string
+	Message string
+	// Fields are the remaining fields, e.g. caller
+	Fields map[string]any
+}
+
+// Event is a message of the WebSocket or an SSE stream. Only the fields
+// of its type are set.
+type Event struct {
+	Type EventType
+	// ID of an SSE event, 0 for WebSocket messages
+	ID uint64
+	// Raw is the message as received
+	Raw string
+
+	ClientID int
+	Seq      int
+	Packet   *PacketEvent
+	SendJob  *SendJob
+	Reset    *ResetResponse
+	Log      *LogEvent
+}
+
+// wsMessage is the structured frame of the WebSocket
+type wsMessage struct {
+	Type    string          `json:"type"`
+	Content string          `json:"content"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// ParseEvent parses a WebSocket message or the data of an SSE event
+func ParseEvent(raw string) Event {
+	e := Event{Type: EventUnknown, Raw: raw}
+	if strings.HasPrefix(raw, "{") {
+		parseJSONEvent(&e)
+		return e
+	}
+	parts := strings.Split(raw, ",")
+	switch {
+	case raw == "uss", raw == "cnu", raw == "map", raw == "rp", raw == "lvu", raw == "rst":
+		e.Type = EventType(raw)
+	case strings.HasPrefix(raw, "fzu"):
+		e.Type = EventFuzz
+	case strings.HasPrefix(raw, "usu"):
+		if id, err := strconv.Atoi(raw[3:]); err == nil {
+			e.Type, e.ClientID = EventClientState, id
+		}
+	case parts[0] == "dgm" && len(parts) == 3:
+		id, err1 := strconv.Atoi(parts[1])
+		seq, err2 := strconv.Atoi(parts[2])
+		if err1 == nil && err2 == nil {
+			e.Type, e.ClientID, e.Seq = EventDatagram, id, seq
+		}
+	case parts[0] == "pkt" && len(parts) == 4:
+		from, err1 := strconv.Atoi(parts[1])
+		to, err2 := strconv.Atoi(parts[2])
+		dir, err3 := strconv.Atoi(parts[3])
+		if err1 == nil && err2 == nil && err3 == nil {
+			e.Type = EventPacketNotice
+			e.Packet = &PacketEvent{FromClientID: from, ToClientID: to, Direction: Direction(dir)}
+		}
+	}
+	return e
+}
+
+func parseJSONEvent(e *Event) {
+	var msg wsMessage
+	if err := json.Unmarshal([]byte(e.Raw), &msg); err != nil {
+		return
+	}
+	switch msg.Type {
+	case "PKT":
+		var p PacketEvent
+		if json.Unmarshal(msg.Data, &p) == nil {
+			e.Type, e.Packet, e.Seq = EventPacket, &p, p.Seq
+			e.ClientID = p.FromClientID
+			if e.ClientID == 0 {
+				e.ClientID = p.ToClientID
+			}
+		}
+		return
+	case "JOB":
+		var job SendJob
+		if json.Unmarshal(msg.Data, &job) == nil {
+			e.Type, e.SendJob, e.ClientID = EventSendJob, &job, job.ClientID
+		}
+		return
+	case "RST":
+		var reset ResetResponse
+		if json.Unmarshal(msg.Data, &reset) == nil {
+			e.Type, e.Reset = EventReset, &reset
+		}
+		return
+	case "SHD":
+		e.Type = EventShutdown
+		return
+	case "":
+	default:
+		return
+	}
+
+	// Log entries are plain JSON objects with msg and level
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(e.Raw), &fields); err != nil {
+		return
+	}
+	message, ok := fields["msg"].(string)
+	if !ok {
+		return
+	}
+	entry := &LogEvent{Message: message, Fields: fields}
+	entry.Level, _ = fields["level"].(string)
+	if t, ok := fields["time"].(string); ok {
+		entry.Time, _ = time.Parse(time.RFC3339, t)
+	}
+	delete(fields, "msg")
+	delete(fields, "level")
+	delete(fields, "time")
+	if id, ok := fields["cid"].(float64); ok {
+		e.ClientID = int(id)
+	}
+	e.Type, e.Log = EventLog, entry
+}
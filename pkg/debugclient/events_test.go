@@ -0,0 +1,89 @@
+package debugclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		raw      string
+		typ      EventType
+		clientID int
+		seq      int
+	}{
+		{"uss", EventServerState, 0, 0},
+		{"usu12", EventClientState, 12, 0},
+		{"dgm,3,41", EventDatagram, 3, 41},
+		{"lvu", EventLogLevels, 0, 0},
+		{"fzu", EventFuzz, 0, 0},
+		{`{"type":"PKT","data":{"seq":7,"fromClientId":0,"toClientId":5,"direction":1}}`, EventPacket, 5, 7},
+		{`{"type":"JOB","data":{"id":1,"clientId":2}}`, EventSendJob, 2, 0},
+		{`{"type":"SHD","content":"server shutting down"}`, EventShutdown, 0, 0},
+		{`{"level":"info","msg":"hello","caller":"web","time":"2026-01-02T03:04:05Z"}`, EventLog, 0, 0},
+		{"usuX", EventUnknown, 0, 0},
+		{"dgm,1", EventUnknown, 0, 0},
+		{"something else", EventUnknown, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			e := ParseEvent(tt.raw)
+			assert.Equal(t, tt.typ, e.Type)
+			assert.Equal(t, tt.clientID, e.ClientID)
+			assert.Equal(t, tt.seq, e.Seq)
+			assert.Equal(t, tt.raw, e.Raw)
+		})
+	}
+}
+
+func TestParseEvent_Log(t *testing.T) {
+	e := ParseEvent(`{"level":"warning","msg":"slow","caller":"client","cid":4,"time":"2026-01-02T03:04:05Z"}`)
+
+	require.NotNil(t, e.Log)
+	assert.Equal(t, "warning", e.Log.Level)
+	assert.Equal(t, "slow", e.Log.Message)
+	assert.Equal(t, "client", e.Log.Fields["caller"])
+	assert.Equal(t, 2026, e.Log.Time.Year())
+}
+
+func TestReadSSE(t *testing.T) {
+	stream := ": keep-alive\n\nid: 1\ndata: uss\n\nid: 2\ndata: dgm,1,2\n\nid: 3\ndata: map\n\n"
+
+	var events []Event
+	readSSE(strings.NewReader(stream), func(e Event) bool {
+		events = append(events, e)
+		return len(events) < 2
+	})
+
+	require.Len(t, events, 2)
+	assert.Equal(t, uint64(1), events[0].ID)
+	assert.Equal(t, EventServerState, events[0].Type)
+	assert.Equal(t, uint64(2), events[1].ID)
+	assert.Equal(t, EventDatagram, events[1].Type)
+}
+
+func TestClient_Stream_FirstClient(t *testing.T) {
+	queries := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+	}))
+	defer ts.Close()
+	c, err := New(ts.URL)
+	require.NoError(t, err)
+
+	id := 0
+	events, err := c.Stream(context.Background(), StreamPackets, StreamParams{Client: &id})
+	require.NoError(t, err)
+	for range events {
+	}
+
+	assert.Equal(t, "client=0", <-queries)
+}
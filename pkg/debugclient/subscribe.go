@@ -0,0 +1,228 @@
+package debugclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/websocket"
+)
+
+// subscriptionBuffer is the number of events a subscriber may lag behind;
+// further events are dropped and counted
+const subscriptionBuffer = 1024
+
+// ErrClosed is returned when waiting on a closed subscription
+var ErrClosed = errors.New("debug-ui: subscription closed")
+
+// Subscription receives the WebSocket messages of the server as events
+type Subscription struct {
+	conn    *websocket.Conn
+	events  chan Event
+	dropped atomic.Int64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	mu        sync.Mutex
+	err       error
+}
+
+// Subscribe connects to /ws. The subscription ends when ctx is done or Close is called.
+func (c *Client) Subscribe(ctx context.Context) (*Subscription, error) {
+	wsURL := *c.baseURL
+	wsURL.Scheme = map[string]string{"http": "ws", "https": "wss"}[c.baseURL.Scheme]
+	wsURL.Path = strings.TrimSuffix(wsURL.Path, "/") + "/ws"
+	config, err := websocket.NewConfig(wsURL.String(), c.baseURL.String())
+	if err != nil {
+		return nil, err
+	}
+	config.TlsConfig = c.tlsConfig
+	if c.token != "" {
+		config.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	conn, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Subscription{conn: conn, events: make(chan Event, subscriptionBuffer), closed: make(chan struct{})}
+	go s.readLoop()
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.closed:
+		}
+	}()
+	return s, nil
+}
+
+func (s *Subscription) readLoop() {
+	defer close(s.events)
+	for {
+		var msg string
+		if err := websocket.Message.Receive(s.conn, &msg); err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.setErr(err)
+			}
+			s.Close()
+			return
+		}
+		select {
+		case s.events <- ParseEvent(msg):
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Events returns the events in the order they arrived. The channel is
+// closed when the subscription ends.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Send sends a command, e.g. "lvl,server,debug"
+func (s *Subscription) Send(msg string) error {
+	return websocket.Message.Send(s.conn, msg)
+}
+
+// Dropped returns the number of events dropped because nobody read them
+func (s *Subscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Err returns the error that ended the subscription, if any
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Subscription) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Close ends the subscription
+func (s *Subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+// Next returns the next event
+func (s *Subscription) Next(ctx context.Context) (Event, error) {
+	select {
+	case e, ok := <-s.events:
+		if !ok {
+			return Event{}, ErrClosed
+		}
+		return e, nil
+	case <-ctx.Done():
+		return Event{}, ctx.Err()
+	}
+}
+
+// WaitFor returns the next event match accepts, skipping all others
+func (s *Subscription) WaitFor(ctx context.Context, match func(Event) bool) (Event, error) {
+	for {
+		e, err := s.Next(ctx)
+		if err != nil {
+			return Event{}, err
+		}
+		if match(e) {
+			return e, nil
+		}
+	}
+}
+
+// StreamKind names a Server-Sent Events stream
+type StreamKind string
+
+const (
+	StreamLogs    StreamKind = "logs"
+	StreamPackets StreamKind = "packets"
+	StreamState   StreamKind = "state"
+)
+
+// StreamParams filter a stream. Zero values are left out.
+type StreamParams struct {
+	// Filter keeps events containing this text, ignoring case
+	Filter string
+	// Client keeps packets of this client, only for StreamPackets
+	Client *int
+	// LastEventID resumes after this event
+	LastEventID uint64
+}
+
+// Stream reads a Server-Sent Events stream. The channel is closed when ctx
+// is done or the server ends the stream.
+func (c *Client) Stream(ctx context.Context, kind StreamKind, p StreamParams) (<-chan Event, error) {
+	q := values{}.set("filter", p.Filter)
+	if p.Client != nil {
+		q.set("client", strconv.Itoa(*p.Client))
+	}
+	if p.LastEventID != 0 {
+		q.set("lastEventId", strconv.FormatUint(p.LastEventID, 10))
+	}
+	body, err := c.request(ctx, http.MethodGet, "/api/v1/stream/"+string(kind), url.Values(q), nil)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer body.Close()
+		readSSE(body, func(e Event) bool {
+			select {
+			case events <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return events, nil
+}
+
+// readSSE parses text/event-stream and hands every event to emit until it returns false
+func readSSE(r io.Reader, emit func(Event) bool) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var id uint64
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				e := ParseEvent(strings.Join(data, "\n"))
+				e.ID = id
+				if !emit(e) {
+					return
+				}
+			}
+			data = data[:0]
+		case strings.HasPrefix(line, ":"):
+			// Comment, e.g. keep-alive
+		case strings.HasPrefix(line, "id: "):
+			id, _ = strconv.ParseUint(line[len("id: "):], 10, 64)
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, line[len("data: "):])
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package debugclient
+
+import (
+	"time"
+
+	"github.com/auraspeak/debug-ui/internal/api"
+	"github.com/auraspeak/debug-ui/internal/fuzz"
+	"github.com/auraspeak/debug-ui/internal/logstore"
+	"github.com/auraspeak/debug-ui/internal/payload"
+)
+
+// The request and response types are the ones the server uses, so they
+// can't drift apart.
+type (
+	ErrorCode   = api.ErrorCode
+	Success     = api.ApiSuccess
+	ServerState = api.ServerStateResponse
+
+	StartClientRequest     = api.StartUDPClientRequest
+	StartedClient          = api.UDPClientResponse
+	ClientState            = api.UDPClientStateResponse
+	AllClients             = api.AllUDPClientResponse
+	ClientListItem         = api.UDPClientListItem
+	ClientPage             = api.UDPClientPaginatedRespone
+	ClientMap              = api.ClientMapResponse
+	SetClientLabelsRequest = api.SetClientLabelsRequest
+	NameGeneratorRequest   = api.NameGeneratorRequest
+
+	SendRequest    = api.SendDatagramRequest
+	HeaderOverride = api.HeaderOverride
+	SendResponse   = api.SendDatagramResponse
+	Datagram       = api.Datagram
+	DatagramPage   = api.DatagramPageResponse
+	Direction      = api.DatagramDirection
+	PacketEvent    = api.PacketEvent
+
+	StartSendJobRequest = api.StartSendJobRequest
+	SendJob             = api.SendJob
+	SendJobState        = api.SendJobState
+	AllSendJobs         = api.AllSendJobsResponse
+
+	StartGroupRequest = api.StartGroupRequest
+	SendGroupRequest  = api.SendGroupRequest
+	GroupResult       = api.GroupActionResponse
+	Groups            = api.AllGroupsResponse
+
+	Traces = api.MermaidResponse
+
+	StartFuzzRequest = api.StartFuzzRequest
+	FuzzSummary      = fuzz.Summary
+
+	Template = payload.Template
+
+	ResetRequest  = api.WorkspaceResetRequest
+	ResetResponse = api.WorkspaceResetResponse
+
+	LogEntry           = logstore.Entry
+	Logs               = api.LogsResponse
+	LogLevels          = api.LogLevelsResponse
+	SetLogLevelRequest = api.SetLogLevelRequest
+
+	Diagnostics = api.DiagnosticsResponse
+)
+
+const (
+	ClientToServer = api.ClientToServer
+	ServerToClient = api.ServerToClient
+)
+
+// Error codes of the API
+const (
+	ErrInvalidBody       = api.ErrInvalidBody
+	ErrInvalidParameter  = api.ErrInvalidParameter
+	ErrMissingParameter  = api.ErrMissingParameter
+	ErrInvalidPayload    = api.ErrInvalidPayload
+	ErrServerNotRunning  = api.ErrServerNotRunning
+	ErrServerStartFailed = api.ErrServerStartFailed
+	ErrClientNotFound    = api.ErrClientNotFound
+	ErrClientNotRunning  = api.ErrClientNotRunning
+	ErrInvalidClientName = api.ErrInvalidClientName
+	ErrClientNameTaken   = api.ErrClientNameTaken
+	ErrSendFailed        = api.ErrSendFailed
+	ErrGroupNotFound     = api.ErrGroupNotFound
+	ErrInvalidSendJob    = api.ErrInvalidSendJob
+	ErrSendJobNotFound   = api.ErrSendJobNotFound
+	ErrSendJobFinished   = api.ErrSendJobFinished
+	ErrInvalidTemplate   = api.ErrInvalidTemplate
+	ErrTemplateNotFound  = api.ErrTemplateNotFound
+	ErrFuzzRunning       = api.ErrFuzzRunning
+	ErrFuzzNotRunning    = api.ErrFuzzNotRunning
+	ErrInvalidCorpus     = api.ErrInvalidCorpus
+	ErrNoFuzzSeeds       = api.ErrNoFuzzSeeds
+	ErrUnauthorized      = api.ErrUnauthorized
+	ErrForbidden         = api.ErrForbidden
+	ErrOriginNotAllowed  = api.ErrOriginNotAllowed
+	ErrNotFound          = api.ErrNotFound
+	ErrConflict          = api.ErrConflict
+	ErrUnavailable       = api.ErrUnavailable
+	ErrInternal          = api.ErrInternal
+)
+
+// ListParams filter, sort and page the client list. Zero values are left out.
+type ListParams struct {
+	Page     int
+	PageSize int
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+	Query  string
+	Tags   []string
+	// Sort is id, name, created or activity
+	Sort string
+	// Order is asc or desc
+	Order        string
+	Running      *bool
+	ActiveWithin int
+	MinDatagrams int
+	MaxDatagrams int
+}
+
+// DatagramParams page the datagrams of a client by sequence number
+type DatagramParams struct {
+	Since   int
+	Limit   int
+	Reverse bool
+}
+
+// LogParams filter the stored log entries. Zero values are left out.
+type LogParams struct {
+	Level    string
+	Caller   string
+	ClientID *int
+	Since    time.Time
+	Until    time.Time
+	Query    string
+	Limit    int
+}
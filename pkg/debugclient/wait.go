@@ -0,0 +1,137 @@
+package debugclient
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"time"
+)
+
+// pollInterval is how often the wait helpers poll when no event arrives
+const pollInterval = 250 * time.Millisecond
+
+// PayloadEquals matches payloads equal to b
+func PayloadEquals(b []byte) func([]byte) bool {
+	return func(payload []byte) bool { return bytes.Equal(payload, b) }
+}
+
+// PayloadContains matches payloads containing b
+func PayloadContains(b []byte) func([]byte) bool {
+	return func(payload []byte) bool { return bytes.Contains(payload, b) }
+}
+
+// PayloadMatches matches payloads matching re
+func PayloadMatches(re *regexp.Regexp) func([]byte) bool {
+	return re.Match
+}
+
+// WaitForDatagram waits until the client recorded a datagram that match
+// accepts, including datagrams recorded before the call. It is woken by
+// the WebSocket and polls as fallback, so it works without it too. When
+// the sequence numbers start over, e.g. after a workspace reset or a
+// cleared history, it reads the datagrams from the start again.
+func (c *Client) WaitForDatagram(ctx context.Context, clientID int, match func(Datagram) bool) (Datagram, error) {
+	var wake <-chan Event
+	if sub, err := c.Subscribe(ctx); err == nil {
+		defer sub.Close()
+		wake = sub.Events()
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	since := 0
+	for {
+		for {
+			page, err := c.Datagrams(ctx, clientID, DatagramParams{Since: since, Limit: 1000})
+			if err != nil {
+				return Datagram{}, err
+			}
+			if page.LastSeq < since {
+				since = 0
+				continue
+			}
+			for _, d := range page.Datagrams {
+				if match(d) {
+					return d, nil
+				}
+			}
+			if page.Next > since {
+				since = page.Next
+			}
+			if !page.HasMore {
+				break
+			}
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return Datagram{}, ctx.Err()
+			case <-ticker.C:
+				break wait
+			case e, ok := <-wake:
+				if !ok {
+					// Fall back to polling
+					wake = nil
+					continue
+				}
+				if e.Type == EventReset {
+					since = 0
+					break wait
+				}
+				if e.Type == EventDatagram && e.ClientID == clientID {
+					break wait
+				}
+			}
+		}
+	}
+}
+
+// WaitForReceived waits until the client received a datagram from the
+// server whose message payload matches
+func (c *Client) WaitForReceived(ctx context.Context, clientID int, payload func([]byte) bool) (Datagram, error) {
+	return c.WaitForDatagram(ctx, clientID, func(d Datagram) bool {
+		return d.Direction == ServerToClient && payload(d.Message)
+	})
+}
+
+// WaitForSent waits until the client sent a datagram whose message payload matches
+func (c *Client) WaitForSent(ctx context.Context, clientID int, payload func([]byte) bool) (Datagram, error) {
+	return c.WaitForDatagram(ctx, clientID, func(d Datagram) bool {
+		return d.Direction == ClientToServer && payload(d.Message)
+	})
+}
+
+// WaitForServer waits until the UDP server is alive, or not if alive is false
+func (c *Client) WaitForServer(ctx context.Context, alive bool) error {
+	return poll(ctx, func() (bool, error) {
+		state, err := c.ServerState(ctx)
+		return err == nil && state.IsAlive == alive, err
+	})
+}
+
+// WaitForClient waits until the client is running, or not if running is false
+func (c *Client) WaitForClient(ctx context.Context, clientID int, running bool) error {
+	return poll(ctx, func() (bool, error) {
+		state, err := c.ClientByID(ctx, clientID, false)
+		return err == nil && state.Running == running, err
+	})
+}
+
+// poll calls check until it is done, fails or ctx ends
+func poll(ctx context.Context, check func() (bool, error)) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
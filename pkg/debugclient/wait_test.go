@@ -0,0 +1,112 @@
+package debugclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// datagramServer serves the datagrams of client 1, add appends to them and
+// reset removes them, so the sequence numbers start over
+func datagramServer(t *testing.T) (c *Client, add func(Datagram), reset func()) {
+	t.Helper()
+	var mu sync.Mutex
+	var datagrams []Datagram
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/client/datagrams" {
+			http.NotFound(w, r)
+			return
+		}
+		since, _ := strconv.Atoi(r.URL.Query().Get("since"))
+		mu.Lock()
+		defer mu.Unlock()
+		page := DatagramPage{ClientID: 1, Datagrams: []Datagram{}, LastSeq: len(datagrams), Next: since}
+		for _, d := range datagrams {
+			if d.Seq > since {
+				page.Datagrams = append(page.Datagrams, d)
+				page.Next = d.Seq
+			}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(ts.Close)
+
+	c, err := New(ts.URL)
+	require.NoError(t, err)
+	add = func(d Datagram) {
+		mu.Lock()
+		defer mu.Unlock()
+		d.Seq = len(datagrams) + 1
+		datagrams = append(datagrams, d)
+	}
+	reset = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		datagrams = nil
+	}
+	return c, add, reset
+}
+
+func TestClient_WaitForReceived(t *testing.T) {
+	c, add, _ := datagramServer(t)
+	add(Datagram{Direction: ClientToServer, Message: []byte("ping")})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		add(Datagram{Direction: ServerToClient, Message: []byte("other")})
+		add(Datagram{Direction: ServerToClient, Message: []byte("pong 1")})
+	}()
+	d, err := c.WaitForReceived(ctx, 1, PayloadContains([]byte("pong")))
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, d.Seq)
+	assert.Equal(t, []byte("pong 1"), d.Message)
+}
+
+func TestClient_WaitForReceived_SeqStartsOver(t *testing.T) {
+	c, add, reset := datagramServer(t)
+	add(Datagram{Direction: ServerToClient, Message: []byte("old 1")})
+	add(Datagram{Direction: ServerToClient, Message: []byte("old 2")})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		reset()
+		add(Datagram{Direction: ServerToClient, Message: []byte("pong")})
+	}()
+	d, err := c.WaitForReceived(ctx, 1, PayloadEquals([]byte("pong")))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, d.Seq)
+}
+
+func TestClient_WaitForSent_History(t *testing.T) {
+	c, add, _ := datagramServer(t)
+	add(Datagram{Direction: ClientToServer, Message: []byte("ping")})
+
+	d, err := c.WaitForSent(context.Background(), 1, PayloadEquals([]byte("ping")))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, d.Seq)
+}
+
+func TestClient_WaitForDatagram_Timeout(t *testing.T) {
+	c, _, _ := datagramServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := c.WaitForReceived(ctx, 1, PayloadEquals([]byte("never")))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
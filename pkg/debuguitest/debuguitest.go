@@ -0,0 +1,258 @@
+// Package debuguitest runs a complete debug-ui in-process for the tests of
+// other repositories, like net/http/httptest does for handlers.
+//
+//	ui := debuguitest.Start(t)
+//	alice := ui.StartClient(t, "alice")
+//	...
+//	d, err := ui.Client.WaitForReceived(ctx, alice.Id, debugclient.PayloadContains([]byte("pong")))
+//
+// Web server and UDP server listen on free ports of the loopback interface.
+// Everything is stopped by t.Cleanup.
+//
+// Each instance has its own logger, log store and log levels. The entries of
+// the AuraSpeak libraries go to the standard logger of the process and are
+// stored by every running instance.
+package debuguitest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auraspeak/debug-ui/app"
+	"github.com/auraspeak/debug-ui/internal/certs"
+	"github.com/auraspeak/debug-ui/pkg/debugclient"
+	"github.com/auraspeak/server/pkg/debugui"
+)
+
+// startTimeout bounds starting and stopping the instance
+const startTimeout = 10 * time.Second
+
+// Options configure Start
+type Options struct {
+	// Config of the debug-ui. UDPPort 0 picks a free port, an empty
+	// TemplateDir a temporary directory.
+	Config app.Config
+	// DTLS builds the configuration of the UDP server and clients from the
+	// generated certificate and key files. Nil uses DefaultDTLS.
+	DTLS func(certFile, keyFile string) debugui.Config
+	// NoUDPServer leaves the UDP server stopped, e.g. to test starting it
+	NoUDPServer bool
+}
+
+type Option func(*Options)
+
+// WithConfig sets the debug-ui config, see Options.Config
+func WithConfig(config app.Config) Option {
+	return func(o *Options) { o.Config = config }
+}
+
+// WithDTLS sets how the DTLS config is built, see Options.DTLS
+//
+//	debuguitest.WithDTLS(func(certFile, keyFile string) debugui.Config {
+//		cfg := debuguitest.DefaultDTLS(certFile, keyFile)
+//		cfg.Server.DTLS.Tuning.MTU = 500
+//		return cfg
+//	})
+func WithDTLS(dtls func(certFile, keyFile string) debugui.Config) Option {
+	return func(o *Options) { o.DTLS = dtls }
+}
+
+// DefaultDTLS is the DTLS config of an instance: the generated certificate
+// and key with the settings of cmd/server_config.yml. It doesn't read a
+// config file, so it is the same in every working directory.
+func DefaultDTLS(certFile, keyFile string) debugui.Config {
+	var cfg debugui.Config
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Env = "test"
+	cfg.Server.DTLS.Certs.Mode = "file"
+	cfg.Server.DTLS.Certs.CertFile = certFile
+	cfg.Server.DTLS.Certs.KeyFile = keyFile
+	cfg.Server.DTLS.Security.ClientAuth = "no_client_cert"
+	cfg.Server.DTLS.Security.ExtendedMasterSecret = "request"
+	cfg.Server.DTLS.Tuning.MTU = 1200
+	cfg.Server.DTLS.Tuning.ReplayProtectionWindow = 64
+	cfg.Server.DTLS.Tuning.InsecureSkipVerifyHello = true
+	return cfg
+}
+
+// WithoutUDPServer does not start the UDP server
+func WithoutUDPServer() Option {
+	return func(o *Options) { o.NoUDPServer = true }
+}
+
+// Instance is a running debug-ui
+type Instance struct {
+	// URL of the web server, e.g. "http://127.0.0.1:41234"
+	URL string
+	// WSURL of the WebSocket
+	WSURL string
+	// TLSConfig trusts the certificate of the web server, nil for HTTP
+	TLSConfig *tls.Config
+	// UDPPort the UDP server listens on
+	UDPPort int
+	// DTLSCertFile and DTLSKeyFile are the generated certificate of the UDP server
+	DTLSCertFile string
+	DTLSKeyFile  string
+	// Server is the debug-ui itself
+	Server *app.Server
+	// Client is a client for URL, authenticated with the first admin token
+	Client *debugclient.Client
+}
+
+// Start runs a debug-ui until the test ends and fails the test if it can't
+func Start(t testing.TB, opts ...Option) *Instance {
+	t.Helper()
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dir := t.TempDir()
+	config := o.Config
+	if config.TemplateDir == "" {
+		config.TemplateDir = filepath.Join(dir, "templates")
+	}
+	if config.TLSSelfSigned && config.TLSDir == "" {
+		config.TLSDir = filepath.Join(dir, "tls")
+	}
+	if config.UDPPort == 0 {
+		port, err := freeUDPPort()
+		if err != nil {
+			t.Fatalf("debuguitest: %v", err)
+		}
+		config.UDPPort = port
+	}
+
+	// A fresh certificate for DTLS, in its own directory
+	dtlsDir := filepath.Join(dir, "dtls")
+	if _, err := certs.LoadOrCreate(dtlsDir); err != nil {
+		t.Fatalf("debuguitest: generating DTLS certificate: %v", err)
+	}
+	ui := &Instance{
+		UDPPort:      config.UDPPort,
+		DTLSCertFile: filepath.Join(dtlsDir, certs.CertFile),
+		DTLSKeyFile:  filepath.Join(dtlsDir, certs.KeyFile),
+	}
+	dtls := DefaultDTLS
+	if o.DTLS != nil {
+		dtls = o.DTLS
+	}
+
+	// Load the web certificate up front, so the client can trust it
+	var webCert *x509.Certificate
+	switch {
+	case config.TLSCertFile != "" || config.TLSKeyFile != "":
+		cert, err := certs.Load(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			t.Fatalf("debuguitest: %v", err)
+		}
+		webCert = cert.Leaf
+	case config.TLSSelfSigned:
+		cert, err := certs.LoadOrCreate(config.TLSDir, config.TLSHosts...)
+		if err != nil {
+			t.Fatalf("debuguitest: %v", err)
+		}
+		webCert = cert.Leaf
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("debuguitest: %v", err)
+	}
+	ui.Server = app.NewServerWithConfig(l.Addr().(*net.TCPAddr).Port, config, dtls(ui.DTLSCertFile, ui.DTLSKeyFile))
+	served := make(chan error, 1)
+	go func() {
+		served <- ui.Server.Serve(l)
+	}()
+	t.Cleanup(func() {
+		if report, err := ui.Server.Shutdown(startTimeout); err != nil {
+			t.Errorf("debuguitest: shutdown: %v\n%s", err, report)
+		}
+		if err := <-served; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("debuguitest: serve: %v", err)
+		}
+	})
+
+	scheme := "http"
+	clientOpts := []debugclient.Option{}
+	if webCert != nil {
+		scheme = "https"
+		pool := x509.NewCertPool()
+		pool.AddCert(webCert)
+		ui.TLSConfig = &tls.Config{RootCAs: pool}
+		clientOpts = append(clientOpts, debugclient.WithTLSConfig(ui.TLSConfig))
+	}
+	if len(config.AdminTokens) > 0 {
+		clientOpts = append(clientOpts, debugclient.WithToken(config.AdminTokens[0]))
+	}
+	ui.URL = scheme + "://" + l.Addr().String()
+	ui.WSURL = strings.Replace(ui.URL, "http", "ws", 1) + "/ws"
+	ui.Client, err = debugclient.New(ui.URL, clientOpts...)
+	if err != nil {
+		t.Fatalf("debuguitest: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	if _, err := ui.Client.ServerState(ctx); err != nil {
+		t.Fatalf("debuguitest: web server not ready: %v", err)
+	}
+	if !o.NoUDPServer {
+		ui.StartUDPServer(t)
+	}
+	return ui
+}
+
+// StartUDPServer starts the UDP server and waits until it is alive
+func (ui *Instance) StartUDPServer(t testing.TB) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	if _, err := ui.Client.StartServer(ctx); err != nil {
+		t.Fatalf("debuguitest: starting UDP server: %v", err)
+	}
+	if err := ui.Client.WaitForServer(ctx, true); err != nil {
+		t.Fatalf("debuguitest: UDP server not alive: %v", err)
+	}
+}
+
+// StartClient starts a UDP client, an empty name generates one
+func (ui *Instance) StartClient(t testing.TB, name string) debugclient.StartedClient {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	started, err := ui.Client.StartClient(ctx, debugclient.StartClientRequest{Name: name})
+	if err != nil {
+		t.Fatalf("debuguitest: starting client %q: %v", name, err)
+	}
+	return started
+}
+
+// Subscribe connects to the WebSocket until the test ends
+func (ui *Instance) Subscribe(t testing.TB) *debugclient.Subscription {
+	t.Helper()
+	sub, err := ui.Client.Subscribe(context.Background())
+	if err != nil {
+		t.Fatalf("debuguitest: subscribing: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+	return sub
+}
+
+// freeUDPPort asks the kernel for a free UDP port on the loopback interface
+func freeUDPPort() (int, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).Port, nil
+}
@@ -0,0 +1,152 @@
+package debuguitest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auraspeak/debug-ui/app"
+	"github.com/auraspeak/debug-ui/pkg/debugclient"
+	"github.com/auraspeak/server/pkg/debugui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart(t *testing.T) {
+	ui := Start(t, WithoutUDPServer())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NotZero(t, ui.UDPPort)
+	assert.FileExists(t, ui.DTLSCertFile)
+	assert.FileExists(t, ui.DTLSKeyFile)
+	assert.Equal(t, "ws"+ui.URL[len("http"):]+"/ws", ui.WSURL)
+
+	state, err := ui.Client.ServerState(ctx)
+	require.NoError(t, err)
+	assert.False(t, state.IsAlive)
+
+	sub := ui.Subscribe(t)
+	_, err = ui.Client.SetLogLevel(ctx, debugclient.SetLogLevelRequest{Level: "info"})
+	require.NoError(t, err)
+	_, err = sub.WaitFor(ctx, func(e debugclient.Event) bool { return e.Type == debugclient.EventLogLevels })
+	assert.NoError(t, err)
+}
+
+func TestStart_Independent(t *testing.T) {
+	a := Start(t, WithoutUDPServer())
+	b := Start(t, WithoutUDPServer())
+
+	assert.NotEqual(t, a.URL, b.URL)
+	assert.NotEqual(t, a.UDPPort, b.UDPPort)
+
+	// Each instance has its own log store and levels: the warning of b is
+	// stored by b only, although a only logs errors
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := a.Client.SetLogLevel(ctx, debugclient.SetLogLevelRequest{Level: "error"})
+	require.NoError(t, err)
+	_, err = b.Client.StopServer(ctx)
+	require.Error(t, err)
+	logs, err := b.Client.Logs(ctx, debugclient.LogParams{Query: "UDP server is not running"})
+	require.NoError(t, err)
+	assert.Len(t, logs.Entries, 1)
+	logs, err = a.Client.Logs(ctx, debugclient.LogParams{Query: "UDP server is not running"})
+	require.NoError(t, err)
+	assert.Empty(t, logs.Entries)
+}
+
+func TestStart_RoundTrip(t *testing.T) {
+	ui := Start(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	state, err := ui.Client.ServerState(ctx)
+	require.NoError(t, err)
+	assert.True(t, state.IsAlive)
+
+	alice := ui.StartClient(t, "alice")
+	require.NoError(t, ui.Client.WaitForClient(ctx, alice.Id, true))
+	_, err = ui.Client.Send(ctx, debugclient.SendRequest{Id: alice.Id, Message: "ping", Format: "text"})
+	require.NoError(t, err)
+
+	// The datagram went out over the UDP server and came back to the client
+	_, err = ui.Client.WaitForSent(ctx, alice.Id, debugclient.PayloadContains([]byte("ping")))
+	require.NoError(t, err)
+	_, err = ui.Client.WaitForReceived(ctx, alice.Id, debugclient.PayloadContains([]byte("ping")))
+	require.NoError(t, err)
+}
+
+func TestStart_RequestIDInLogs(t *testing.T) {
+	ui := Start(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	alice := ui.StartClient(t, "alice")
+	require.NoError(t, ui.Client.WaitForClient(ctx, alice.Id, true))
+	before, err := ui.Client.Logs(ctx, debugclient.LogParams{})
+	require.NoError(t, err)
+	var lastID uint64
+	if n := len(before.Entries); n > 0 {
+		lastID = before.Entries[n-1].ID
+	}
+
+	body := fmt.Sprintf(`{"id":%d,"message":"ping","format":"text"}`, alice.Id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ui.URL+"/api/v1/client/send", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-Request-ID", "send-1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Every entry debug-ui logged for the send carries the ID. The entries of
+	// the libraries and of the UDP server receiving the datagram on its own
+	// goroutine don't belong to the request.
+	after, err := ui.Client.Logs(ctx, debugclient.LogParams{})
+	require.NoError(t, err)
+	var messages []string
+	for _, e := range after.Entries {
+		if e.ID <= lastID || e.Caller == "server" || e.Caller == "client" || strings.HasPrefix(e.Message, "Received packet") {
+			continue
+		}
+		assert.Equal(t, "send-1", e.Fields["requestId"], e.Message)
+		messages = append(messages, e.Message)
+	}
+	assert.Contains(t, messages, "Datagram sent successfully: ping")
+}
+
+func TestStart_WithDTLS(t *testing.T) {
+	var gotCert, gotKey string
+	ui := Start(t, WithoutUDPServer(), WithDTLS(func(certFile, keyFile string) debugui.Config {
+		gotCert, gotKey = certFile, keyFile
+		return DefaultDTLS(certFile, keyFile)
+	}))
+
+	assert.Equal(t, ui.DTLSCertFile, gotCert)
+	assert.Equal(t, ui.DTLSKeyFile, gotKey)
+}
+
+func TestStart_TLSAndAuth(t *testing.T) {
+	ui := Start(t, WithoutUDPServer(), WithConfig(app.Config{
+		TLSSelfSigned: true,
+		AdminTokens:   []string{"admin-token"},
+	}))
+
+	assert.Contains(t, ui.URL, "https://")
+	assert.Contains(t, ui.WSURL, "wss://")
+
+	// The instance client trusts the certificate and sends the token
+	_, err := ui.Client.ServerState(context.Background())
+	require.NoError(t, err)
+
+	// Others need the token
+	anonymous, err := debugclient.New(ui.URL, debugclient.WithTLSConfig(ui.TLSConfig))
+	require.NoError(t, err)
+	_, err = anonymous.ServerState(context.Background())
+	var apiErr *debugclient.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
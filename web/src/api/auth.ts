@@ -0,0 +1,27 @@
+// src/api/auth.ts
+const STORAGE_KEY = "debug-ui-token";
+
+/**
+ * Returns the API token. A `?token=` in the page URL is remembered, so the
+ * link only has to be opened once.
+ */
+export function getAuthToken(): string | null {
+  const fromUrl = new URLSearchParams(location.search).get("token");
+  if (fromUrl) {
+    localStorage.setItem(STORAGE_KEY, fromUrl);
+    return fromUrl;
+  }
+  return localStorage.getItem(STORAGE_KEY);
+}
+
+export function setAuthToken(token: string | null) {
+  if (token) localStorage.setItem(STORAGE_KEY, token);
+  else localStorage.removeItem(STORAGE_KEY);
+}
+
+/** Adds the token as query parameter, for WebSockets and downloads that can't send headers */
+export function withAuthToken(url: string): string {
+  const token = getAuthToken();
+  if (!token) return url;
+  return url + (url.includes("?") ? "&" : "?") + "token=" + encodeURIComponent(token);
+}
@@ -1,5 +1,6 @@
 import type { ApiClient } from "./client";
-import type { ID, Paginated, ServerState, UDPClient, UDPClientState, SendDatagramRequest, MermaidTraces, ClientMapData } from "./types";
+import type { ID, Paginated, ServerState, UDPClient, UDPClientListParams, UDPClientState, DatagramPage, SendDatagramRequest, MermaidTraces, ClientMapData, StartFuzzRequest, FuzzSummary, PayloadTemplate, StartSendJobRequest, SendJob, SetClientLabelsRequest, StartUDPClientRequest, NameGeneratorRequest, StartGroupRequest, SendGroupRequest, GroupActionResponse, ClientGroup, WorkspaceResetRequest, WorkspaceResetResponse, LogQuery, LogsResponse, LogLevels, SetLogLevelRequest } from "./types";
+import { withAuthToken } from "./auth";
 
 export interface ServerApi {
     start: () => Promise<void>;
@@ -8,14 +9,22 @@ export interface ServerApi {
 }
 
 export interface UDPClientApi {
-    start: () => Promise<void>;
+    start: (request?: StartUDPClientRequest) => Promise<UDPClient>;
     stop: () => Promise<void>;
     getStateByName: (name: string) => Promise<UDPClientState>;
-    getStateById: (id: ID) => Promise<UDPClientState>;
+    getStateById: (id: ID, datagrams?: boolean) => Promise<UDPClientState>;
+    getDatagrams: (id: ID, params?: { since?: number, limit?: number, reverse?: boolean }) => Promise<DatagramPage>;
     getAll: () => Promise<{ udpClients: UDPClient[] }>;
-    list: (params: { page?: number, pageSize?: number, q?: string }) => Promise<Paginated<UDPClient>>;
+    list: (params: UDPClientListParams) => Promise<Paginated<UDPClient>>;
     sendDatagram: (request: SendDatagramRequest) => Promise<void>;
-    getClientMap: () => Promise<ClientMapData>;
+    getClientMap: (tag?: string) => Promise<ClientMapData>;
+    setLabels: (request: SetClientLabelsRequest) => Promise<UDPClient>;
+    setNameGenerator: (request: NameGeneratorRequest) => Promise<void>;
+    startJob: (request: StartSendJobRequest) => Promise<SendJob>;
+    listJobs: (clientId?: ID) => Promise<{ jobs: SendJob[] }>;
+    pauseJob: (id: number) => Promise<SendJob>;
+    resumeJob: (id: number) => Promise<SendJob>;
+    cancelJob: (id: number) => Promise<SendJob>;
 }
 
 export interface TraceApi {
@@ -24,27 +33,116 @@ export interface TraceApi {
 
 export function createServerApi(client: ApiClient): ServerApi {
     return {
-        start: () => client.post("/api/server/start"),
-        stop: () => client.post("/api/server/stop"),
-        getState: () => client.get("/api/server/get"),
+        start: () => client.post("/api/v1/server/start"),
+        stop: () => client.post("/api/v1/server/stop"),
+        getState: () => client.get("/api/v1/server/get"),
     }
 }
 
 export function createUDPClientApi(client: ApiClient): UDPClientApi {
     return {
-        start: () => client.post("/api/client/start"),
-        stop: () => client.post("/api/client/stop"),
-        getStateByName: (name: string) => client.get("/api/client/get/name", { query: { name } }),
-        getStateById: (id: ID) => client.get("/api/client/get/id", { query: { id } }),
-        getAll: () => client.get("/api/client/get/all"),
-        list: (params: { page?: number, pageSize?: number, q?: string }) => client.get("/api/client/get/all/paginated", { query: params }),
-        sendDatagram: (request: SendDatagramRequest) => client.post("/api/client/send", { body: request }),
-        getClientMap: () => client.get<ClientMapData>("/api/client/map"),
+        start: (request?: StartUDPClientRequest) => client.post("/api/v1/client/start", { body: request }),
+        stop: () => client.post("/api/v1/client/stop"),
+        getStateByName: (name: string) => client.get("/api/v1/client/get/name", { query: { name } }),
+        getStateById: (id: ID, datagrams?: boolean) => client.get("/api/v1/client/get/id", { query: { id, datagrams } }),
+        getDatagrams: (id: ID, params?: { since?: number, limit?: number, reverse?: boolean }) => client.get("/api/v1/client/datagrams", { query: { id, ...params } }),
+        getAll: () => client.get("/api/v1/client/get/all"),
+        list: (params: UDPClientListParams) => client.get("/api/v1/client/get/all/paginated", { query: params }),
+        sendDatagram: (request: SendDatagramRequest) => client.post("/api/v1/client/send", { body: request }),
+        getClientMap: (tag?: string) => client.get<ClientMapData>("/api/v1/client/map", { query: { tag } }),
+        setLabels: (request: SetClientLabelsRequest) => client.post("/api/v1/client/labels", { body: request }),
+        setNameGenerator: (request: NameGeneratorRequest) => client.post("/api/v1/client/names", { body: request }),
+        startJob: (request: StartSendJobRequest) => client.post("/api/v1/client/jobs/start", { body: request }),
+        listJobs: (clientId?: ID) => client.get("/api/v1/client/jobs/all", { query: { clientId } }),
+        pauseJob: (id: number) => client.post("/api/v1/client/jobs/pause", { query: { id } }),
+        resumeJob: (id: number) => client.post("/api/v1/client/jobs/resume", { query: { id } }),
+        cancelJob: (id: number) => client.post("/api/v1/client/jobs/cancel", { query: { id } }),
     };
 }
 
 export function createTraceApi(client: ApiClient): TraceApi {
     return {
-        getAll: (name: string) => client.get("/api/traces/all", { query: {name}}),
+        getAll: (name: string) => client.get("/api/v1/traces/all", { query: {name}}),
+    };
+}
+
+export interface FuzzApi {
+    start: (request: StartFuzzRequest) => Promise<void>;
+    stop: () => Promise<void>;
+    getSummary: () => Promise<FuzzSummary>;
+}
+
+export function createFuzzApi(client: ApiClient): FuzzApi {
+    return {
+        start: (request: StartFuzzRequest) => client.post("/api/v1/fuzz/start", { body: request }),
+        stop: () => client.post("/api/v1/fuzz/stop"),
+        getSummary: () => client.get<FuzzSummary>("/api/v1/fuzz/get"),
+    };
+}
+
+export interface TemplateApi {
+    getAll: () => Promise<{ templates: PayloadTemplate[] }>;
+    get: (name: string) => Promise<PayloadTemplate>;
+    save: (template: PayloadTemplate) => Promise<void>;
+    delete: (name: string) => Promise<void>;
+}
+
+export function createTemplateApi(client: ApiClient): TemplateApi {
+    return {
+        getAll: () => client.get("/api/v1/templates/all"),
+        get: (name: string) => client.get("/api/v1/templates/get", { query: { name } }),
+        save: (template: PayloadTemplate) => client.post("/api/v1/templates/save", { body: template }),
+        delete: (name: string) => client.post("/api/v1/templates/delete", { query: { name } }),
+    };
+}
+
+export interface GroupApi {
+    getAll: () => Promise<{ groups: ClientGroup[] }>;
+    start: (request: StartGroupRequest) => Promise<GroupActionResponse>;
+    stop: (group: string) => Promise<GroupActionResponse>;
+    send: (request: SendGroupRequest) => Promise<GroupActionResponse>;
+    clear: (group: string) => Promise<GroupActionResponse>;
+}
+
+export function createGroupApi(client: ApiClient): GroupApi {
+    return {
+        getAll: () => client.get("/api/v1/groups/all"),
+        start: (request: StartGroupRequest) => client.post("/api/v1/groups/start", { body: request }),
+        stop: (group: string) => client.post("/api/v1/groups/stop", { query: { group } }),
+        send: (request: SendGroupRequest) => client.post("/api/v1/groups/send", { body: request }),
+        clear: (group: string) => client.post("/api/v1/groups/clear", { query: { group } }),
+    };
+}
+
+export interface WorkspaceApi {
+    reset: (request: WorkspaceResetRequest) => Promise<WorkspaceResetResponse>;
+}
+
+export function createWorkspaceApi(client: ApiClient): WorkspaceApi {
+    return {
+        reset: (request: WorkspaceResetRequest) => client.post("/api/v1/workspace/reset", { body: request }),
+    };
+}
+
+export interface LogApi {
+    query: (params?: LogQuery) => Promise<LogsResponse>;
+    /** URL that downloads the matching entries as NDJSON */
+    downloadUrl: (params?: LogQuery) => string;
+    getLevels: () => Promise<LogLevels>;
+    setLevel: (request: SetLogLevelRequest) => Promise<LogLevels>;
+}
+
+export function createLogApi(client: ApiClient, baseUrl: string): LogApi {
+    return {
+        query: (params?: LogQuery) => client.get("/api/v1/logs", { query: { ...params } }),
+        downloadUrl: (params?: LogQuery) => {
+            const query = new URLSearchParams({ format: "ndjson" });
+            for (const [key, value] of Object.entries(params ?? {})) {
+                if (value !== undefined && value !== "") query.set(key, String(value));
+            }
+            return withAuthToken(`${baseUrl}/api/v1/logs?${query}`);
+        },
+        getLevels: () => client.get("/api/v1/logs/levels"),
+        setLevel: (request: SetLogLevelRequest) => client.post("/api/v1/logs/levels", { body: request }),
     };
 }
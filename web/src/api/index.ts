@@ -1,12 +1,18 @@
 import type { App, InjectionKey} from "vue";
 import { createFetchClient, type ApiClient } from "./client";
-import { createServerApi, createTraceApi, createUDPClientApi, type ServerApi, type TraceApi, type UDPClientApi } from "./endpoints";
+import { getAuthToken } from "./auth";
+import { createFuzzApi, createGroupApi, createLogApi, createServerApi, createTemplateApi, createTraceApi, createUDPClientApi, createWorkspaceApi, type FuzzApi, type GroupApi, type LogApi, type ServerApi, type TemplateApi, type TraceApi, type UDPClientApi, type WorkspaceApi } from "./endpoints";
 
 export interface Api {
     client: ApiClient;
     server: ServerApi;
     udpClients: UDPClientApi;
     trace: TraceApi;
+    fuzz: FuzzApi;
+    templates: TemplateApi;
+    groups: GroupApi;
+    workspace: WorkspaceApi;
+    logs: LogApi;
 }
 
 export const ApiKey: InjectionKey<Api> = Symbol("Api");
@@ -14,6 +20,7 @@ export const ApiKey: InjectionKey<Api> = Symbol("Api");
 export function createApi(baseUrl: string): Api {
     const client = createFetchClient({ 
         baseUrl,
+        getAuthToken,
         defaultHeaders: {
             "Content-Type": "application/json",
         },
@@ -23,6 +30,11 @@ export function createApi(baseUrl: string): Api {
         server: createServerApi(client),
         udpClients: createUDPClientApi(client),
         trace: createTraceApi(client),
+        fuzz: createFuzzApi(client),
+        templates: createTemplateApi(client),
+        groups: createGroupApi(client),
+        workspace: createWorkspaceApi(client),
+        logs: createLogApi(client, baseUrl),
     }
 }
 
@@ -2,26 +2,33 @@ export type ID = number;
 
 export type ApiError = {
 	code: number;
+	errorCode: string;
 	message: string;
 	details: string;
+	requestId?: string;
 };
 
 export type ApiSuccess = {
 	message: string;
 	details: string;
+	requestId?: string;
 };
 
 export interface ApiErrorBody {
     message: string;
     code?: number;
+    // Stable error code, e.g. "client_not_found"
+    errorCode?: string;
     details?: string;
+    requestId?: string;
 }
 
 export interface Paginated<T> {
     items: T[];
-    page: number;
+    page: number; // 0 when paging by cursor
     pageSize: number;
     total: number;
+    nextCursor?: string;
 }
 
 export interface ServerState {
@@ -36,26 +43,100 @@ export const DatagramDirection = {
 
 export type DatagramDirection = typeof DatagramDirection[keyof typeof DatagramDirection];
 
+export type FieldType = "uint" | "bytes" | "string" | "struct";
+
+export interface DissectedField {
+    name: string;
+    type: FieldType;
+    offset: number; // relative to message
+    length: number;
+    value?: unknown;
+    children?: DissectedField[];
+}
+
 export interface Datagram {
+    seq: number;
     direction: typeof DatagramDirection[keyof typeof DatagramDirection];
     message: Uint8Array;
+    packetType: number;
+    raw?: boolean;
+    fields?: DissectedField[];
+}
+
+export interface PacketEvent {
+    fromClientId: number;
+    toClientId: number; // 0 = server
+    seq: number;
+    direction: DatagramDirection;
+    packetType: number;
+    raw?: boolean;
+    message: string; // base64
+    fields?: DissectedField[];
 }
 
 export interface UDPClient{
     id: ID;
     name: string;
+    group?: string;
+    tags?: string[];
+    running?: boolean;
+    datagramCount?: number;
+    createdAt?: string;
+    lastActivity?: string;
+}
+
+export interface UDPClientListParams {
+    page?: number;
+    pageSize?: number;
+    q?: string;
+    tag?: string;
+    sort?: "id" | "name" | "created" | "activity";
+    order?: "asc" | "desc";
+    running?: boolean;
+    activeWithin?: number; // seconds
+    minDatagrams?: number;
+    maxDatagrams?: number;
+    cursor?: string;
 }
 
 export interface UDPClientState {
     id: ID;
     running: boolean;
     datagrams: Datagram[];
+    datagramCount: number;
+    lastSeq: number;
+}
+
+export interface DatagramPage {
+    clientId: ID;
+    datagrams: Datagram[];
+    lastSeq: number;
+    next: number;
+    hasMore: boolean;
+}
+
+export interface HeaderOverride {
+    packetType?: number;
+    magic?: number;
+    version?: number;
+    length?: number;
 }
 
 export interface SendDatagramRequest {
     id: ID;
     message: string;
     format: "hex" | "text";
+    header?: HeaderOverride;
+    raw?: boolean;
+    template?: string;
+    variables?: Record<string, string>;
+}
+
+export interface PayloadTemplate {
+    name: string;
+    description?: string;
+    format: "hex" | "text";
+    body: string;
 }
 
 export interface MermaidTraces {
@@ -76,6 +157,182 @@ export interface ClientMapConnection {
 }
 
 export interface ClientMapData {
-    clients: Array<{ id: number; name: string }>;
+    clients: UDPClient[];
     connections: ClientMapConnection[];
 }
+
+export interface StartFuzzRequest {
+    clientId: ID;
+    corpusDir?: string;
+    crashDir?: string;
+    iterations?: number;
+    seed?: number;
+    maxMutations?: number;
+    probeEvery?: number;
+    probeTimeoutMs?: number;
+    minimizeBudget?: number;
+}
+
+export interface FuzzFinding {
+    kind: "crash" | "hang" | "errorlog";
+    iteration: number;
+    mutations: string[];
+    input: string; // base64
+    minimized: string; // base64
+    file?: string;
+    error?: string;
+}
+
+export interface FuzzSummary {
+    running: boolean;
+    seed: number;
+    seeds: number;
+    iterations: number;
+    executed: number;
+    sendErrors: number;
+    findings: FuzzFinding[];
+    startedAt: string;
+    finishedAt?: string;
+    error?: string;
+}
+
+export type SendJobState = "running" | "paused" | "done" | "cancelled" | "failed";
+
+export interface StartSendJobRequest {
+    clientId: ID;
+    intervalMs: number;
+    jitterMs?: number;
+    count?: number;
+    durationMs?: number;
+    request: Omit<SendDatagramRequest, "id">;
+}
+
+export interface SendJob {
+    id: number;
+    clientId: ID;
+    intervalMs: number;
+    jitterMs: number;
+    count: number;
+    durationMs: number;
+    request: SendDatagramRequest;
+    state: SendJobState;
+    sent: number;
+    lastError?: string;
+    startedAt: string;
+    finishedAt?: string;
+}
+
+export interface StartUDPClientRequest {
+    name?: string;
+    group?: string;
+    tags?: string[];
+}
+
+export interface NameGeneratorRequest {
+    seed: number; // 0 = random
+    fullNames: boolean;
+}
+
+export interface SetClientLabelsRequest {
+    id: ID;
+    group: string;
+    tags: string[];
+}
+
+export interface StartGroupRequest {
+    group: string;
+    count: number;
+    tags?: string[];
+}
+
+export interface SendGroupRequest {
+    group: string;
+    request: Omit<SendDatagramRequest, "id">;
+}
+
+export interface GroupMemberResult {
+    id: ID;
+    name: string;
+    error?: string;
+}
+
+export interface GroupActionResponse {
+    group: string;
+    results: GroupMemberResult[];
+}
+
+export interface ClientGroup {
+    name: string;
+    clientIds: ID[];
+    running: number;
+}
+
+export interface WorkspaceResetRequest {
+    all?: boolean;
+    stopClients?: boolean;
+    removeClients?: boolean;
+    stopServer?: boolean;
+    clearTraces?: boolean;
+    clearLogs?: boolean;
+    resetIds?: boolean;
+}
+
+export interface WorkspaceResetResponse {
+    stoppedClients: number;
+    removedClients: number;
+    fuzzStopped: boolean;
+    serverStopped: boolean;
+    clearedTraces: number;
+    clearedLogs: number;
+    clearedLogEvents: number;
+    nextId?: ID;
+}
+
+export type LogLevel = "panic" | "fatal" | "error" | "warning" | "info" | "debug" | "trace";
+
+export interface LogEntry {
+    id: number;
+    time: string;
+    level: LogLevel;
+    msg: string;
+    caller?: string;
+    clientId?: ID;
+    fields?: Record<string, unknown>;
+}
+
+export interface LogQuery {
+    level?: LogLevel;
+    caller?: string;
+    clientId?: ID;
+    since?: string;
+    until?: string;
+    q?: string;
+    limit?: number;
+}
+
+export interface LogsResponse {
+    entries: LogEntry[];
+    stored: number;
+}
+
+export interface SetLogLevelRequest {
+    caller?: string;
+    clientId?: ID;
+    /** Empty or "reset" removes the level of a caller or client */
+    level: LogLevel | "reset" | "";
+}
+
+export interface EffectiveLogLevel {
+    caller: string;
+    clientId?: ID;
+    name?: string;
+    level: LogLevel;
+    override: boolean;
+}
+
+export interface LogLevels {
+    global: LogLevel;
+    callers: Record<string, LogLevel>;
+    clients: Record<string, LogLevel>;
+    effective: EffectiveLogLevel[];
+}
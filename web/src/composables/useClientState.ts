@@ -1,4 +1,4 @@
-import { ref, watch, onMounted } from "vue";
+import { ref, watch, onMounted, inject, type Ref } from "vue";
 import { useApi } from "@/api/useApi";
 import type { UDPClient, UDPClientState } from "@/api/types";
 
@@ -16,6 +16,7 @@ export function useClientState(
     const clientName = ref<string | null>(null);
     const clientState = ref<UDPClientState>();
     const usuSeq = ref<number | null>(null);
+    const datagramEvent = inject<Ref<{ id: number, seq: number } | null>>("datagramEvent");
 
     /**
      * Lädt alle Clients vom Server
@@ -44,14 +45,56 @@ export function useClientState(
         console.log("handleUsUEvent", e);
         if (usuSeq.value === e.seq) return;
         usuSeq.value = e.seq;
-        if (e.id === clientState.value?.id) {
+        const current = clientState.value;
+        if (e.id === current?.id) {
             console.log("handleUsUEvent", "updating client state");
-            const StateData = await api.udpClients.getStateById(e.id);
-            clientState.value = StateData;
+            // Datagrams are fetched incrementally, see fetchNewDatagrams
+            const StateData = await api.udpClients.getStateById(e.id, false);
+            if (StateData.datagramCount < current.datagrams.length) {
+                // History was cleared, start over
+                clientState.value = await api.udpClients.getStateById(e.id);
+            } else {
+                clientState.value = { ...StateData, datagrams: current.datagrams, lastSeq: current.lastSeq };
+            }
             onClientStateUpdate?.();
         }
     }
 
+    /**
+     * Lädt nur die Datagramme, die seit dem letzten bekannten lastSeq dazugekommen sind
+     */
+    let fetchingDatagrams = false;
+    async function fetchNewDatagrams(e: { id: number, seq: number }): Promise<void> {
+        const current = clientState.value;
+        // A running fetch continues until hasMore is false, so it picks up this seq too
+        if (fetchingDatagrams || !current || e.id !== current.id || e.seq <= current.lastSeq) return;
+        fetchingDatagrams = true;
+        try {
+            let hasMore = true;
+            while (hasMore && clientState.value?.id === e.id) {
+                const page = await api.udpClients.getDatagrams(e.id, { since: clientState.value.lastSeq });
+                // Selection may have changed while waiting
+                if (clientState.value?.id !== e.id) return;
+                clientState.value = {
+                    ...clientState.value,
+                    datagrams: [...clientState.value.datagrams, ...page.datagrams],
+                    lastSeq: page.next,
+                };
+                hasMore = page.hasMore || (page.datagrams.length > 0 && page.lastSeq > page.next);
+            }
+            onClientStateUpdate?.();
+        } finally {
+            fetchingDatagrams = false;
+        }
+    }
+
+    if (datagramEvent) {
+        watch(datagramEvent, (e) => {
+            if (!e) return;
+            fetchNewDatagrams(e);
+        });
+    }
+
     // Watcher für Update-Events
     watch(
         usuEvent,
@@ -1,6 +1,6 @@
 import { ref } from "vue";
 import { useApi } from "@/api/useApi";
-import type { UDPClientState } from "@/api/types";
+import type { HeaderOverride, UDPClientState } from "@/api/types";
 
 /**
  * Composable für das Senden von Datagrammen
@@ -10,10 +10,25 @@ export function useSendDatagram() {
     
     const sendFormat = ref<"hex" | "text">("text");
     const sendMessage = ref<string>("");
+    const sendRaw = ref<boolean>(false);
+    const headerOverride = ref<HeaderOverride>({});
     const sending = ref<boolean>(false);
     const sendError = ref<string | null>(null);
     const sendSuccess = ref<boolean>(false);
 
+    /**
+     * Übernimmt nur die tatsächlich ausgefüllten Header-Felder
+     */
+    function buildHeaderOverride(): HeaderOverride | undefined {
+        const header: HeaderOverride = {};
+        for (const [key, value] of Object.entries(headerOverride.value)) {
+            if (typeof value === "number" && !Number.isNaN(value)) {
+                header[key as keyof HeaderOverride] = value;
+            }
+        }
+        return Object.keys(header).length > 0 ? header : undefined;
+    }
+
     /**
      * Sendet ein Datagramm an einen Client
      */
@@ -34,6 +49,8 @@ export function useSendDatagram() {
                 id: clientId,
                 message: sendMessage.value.trim(),
                 format: sendFormat.value,
+                header: buildHeaderOverride(),
+                raw: sendRaw.value,
             });
 
             sendSuccess.value = true;
@@ -58,6 +75,8 @@ export function useSendDatagram() {
     return {
         sendFormat,
         sendMessage,
+        sendRaw,
+        headerOverride,
         sending,
         sendError,
         sendSuccess,